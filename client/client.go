@@ -23,6 +23,8 @@ import (
 	"net/http"
 	"strings"
 	"time"
+
+	"github.com/SENERGY-Platform/analytics-pipeline/lib"
 )
 
 type Client struct {
@@ -48,11 +50,7 @@ func do[T any](req *http.Request, token string, userId string) (result T, err er
 
 	if code >= 300 {
 		body, _ := io.ReadAll(resp.Body)
-		return result, fmt.Errorf(
-			"unexpected status code %d: %s",
-			code,
-			strings.TrimSpace(string(body)),
-		), code
+		return result, statusError(code, body), code
 	}
 
 	if resp.ContentLength == 0 {
@@ -65,6 +63,43 @@ func do[T any](req *http.Request, token string, userId string) (result T, err er
 	return
 }
 
+// doRaw is like do, but returns the body of successful responses as is.
+func doRaw(req *http.Request, token string, userId string) (body []byte, err error, code int) {
+	req.Header.Set("Authorization", withBearer(token))
+	req.Header.Set("X-UserId", userId)
+	client := &http.Client{
+		Timeout: 10 * time.Second,
+	}
+
+	resp, err := client.Do(req)
+	if err != nil {
+		return body, err, http.StatusInternalServerError
+	}
+	defer resp.Body.Close()
+	code = resp.StatusCode
+
+	body, err = io.ReadAll(resp.Body)
+	if err != nil {
+		return nil, err, code
+	}
+	if code >= 300 {
+		return nil, statusError(code, body), code
+	}
+	return
+}
+
+// statusError wraps a lib.ValidationError if the body lists violations, so that callers
+// can inspect them with errors.As.
+func statusError(code int, body []byte) error {
+	if code == http.StatusBadRequest {
+		var ve lib.ValidationError
+		if json.Unmarshal(body, &ve) == nil && len(ve.Violations) > 0 {
+			return fmt.Errorf("unexpected status code %d: %w", code, lib.NewValidationError(ve.Violations))
+		}
+	}
+	return fmt.Errorf("unexpected status code %d: %s", code, strings.TrimSpace(string(body)))
+}
+
 func withBearer(token string) string {
 	if strings.HasPrefix(strings.ToLower(token), "bearer ") {
 		return token
@@ -19,7 +19,9 @@ package client
 import (
 	"bytes"
 	"encoding/json"
+	"iter"
 	"net/http"
+	"net/url"
 	"strconv"
 
 	"github.com/SENERGY-Platform/analytics-pipeline/lib"
@@ -40,8 +42,26 @@ func (c *Client) UpdatePipeline(token string, userId string, pipeline lib.Pipeli
 		return id, err, http.StatusBadRequest
 	}
 	req, err := http.NewRequest(http.MethodPut, c.baseUrl+"/pipeline", bytes.NewBuffer(b))
-	return do[string](req, token, userId)
+	result, err, code := do[map[string]string](req, token, userId)
+	return result["id"], err, code
 }
+
+// UpdatePipelineIfMatch updates the pipeline only if its stored version equals version,
+// a mismatch results in http.StatusPreconditionFailed.
+func (c *Client) UpdatePipelineIfMatch(token string, userId string, pipeline lib.Pipeline, version int) (id string, err error, code int) {
+	b, err := json.Marshal(pipeline)
+	if err != nil {
+		return id, err, http.StatusBadRequest
+	}
+	req, err := http.NewRequest(http.MethodPut, c.baseUrl+"/pipeline", bytes.NewBuffer(b))
+	if err != nil {
+		return id, err, http.StatusBadRequest
+	}
+	req.Header.Set("If-Match", "\""+strconv.Itoa(version)+"\"")
+	result, err, code := do[map[string]string](req, token, userId)
+	return result["id"], err, code
+}
+
 func (c *Client) GetPipelines(token string, userId string, limit int, offset int, order string, asc bool) (pipelines lib.PipelinesResponse, err error, code int) {
 	url := c.baseUrl + "/pipeline?limit=" + strconv.Itoa(limit) + "&offset=" + strconv.Itoa(offset)
 	if order != "" {
@@ -56,6 +76,48 @@ func (c *Client) GetPipelines(token string, userId string, limit int, offset int
 	return do[lib.PipelinesResponse](req, token, userId)
 }
 
+// GetPipelinesPage retrieves the page following cursor, an empty cursor starts at the first page.
+// order is a sort field like name, createdat or updatedat and may be empty.
+func (c *Client) GetPipelinesPage(token string, userId string, limit int, cursor string, order string, asc bool) (pipelines lib.PipelinesResponse, err error, code int) {
+	query := url.Values{}
+	query.Set("limit", strconv.Itoa(limit))
+	if cursor != "" {
+		query.Set("cursor", cursor)
+	}
+	if order != "" {
+		if asc {
+			query.Set("order", order+":asc")
+		} else {
+			query.Set("order", order+":desc")
+		}
+	}
+	req, err := http.NewRequest(http.MethodGet, c.baseUrl+"/pipeline?"+query.Encode(), nil)
+	return do[lib.PipelinesResponse](req, token, userId)
+}
+
+// IteratePipelines walks all pages of pipelines using cursors. Iteration stops after the first error.
+func (c *Client) IteratePipelines(token string, userId string, pageSize int, order string, asc bool) iter.Seq2[lib.Pipeline, error] {
+	return func(yield func(lib.Pipeline, error) bool) {
+		cursor := ""
+		for {
+			page, err, _ := c.GetPipelinesPage(token, userId, pageSize, cursor, order, asc)
+			if err != nil {
+				yield(lib.Pipeline{}, err)
+				return
+			}
+			for _, pipeline := range page.Data {
+				if !yield(pipeline, nil) {
+					return
+				}
+			}
+			if page.NextCursor == "" {
+				return
+			}
+			cursor = page.NextCursor
+		}
+	}
+}
+
 func (c *Client) GetPipelinesAdmin(token string, userId string, limit int, offset int, order string, asc bool) (pipelines lib.PipelinesResponse, err error, code int) {
 	url := c.baseUrl + "/admin/pipeline?limit=" + strconv.Itoa(limit) + "&offset=" + strconv.Itoa(offset)
 	if order != "" {
@@ -76,6 +138,27 @@ func (c *Client) DeletePipelineAdmin(token string, userId string, id string) (er
 	return err, code
 }
 
+// ReconcilePermissionsAdmin makes the permissions-v2 resources match the pipelines, with dryRun it only reports the changes.
+func (c *Client) ReconcilePermissionsAdmin(token string, userId string, dryRun bool) (report lib.ReconcileReport, err error, code int) {
+	req, err := http.NewRequest(http.MethodPost, c.baseUrl+"/admin/pipeline/reconcile?dryRun="+strconv.FormatBool(dryRun), nil)
+	return do[lib.ReconcileReport](req, token, userId)
+}
+
+func (c *Client) GetReconcileMetricsAdmin(token string, userId string) (metrics lib.ReconcileMetrics, err error, code int) {
+	req, err := http.NewRequest(http.MethodGet, c.baseUrl+"/admin/pipeline/reconcile", nil)
+	return do[lib.ReconcileMetrics](req, token, userId)
+}
+
+// TransferPipelinesAdmin hands all pipelines of a user over to another user.
+func (c *Client) TransferPipelinesAdmin(token string, userId string, transfer lib.PipelinesTransfer) (result lib.PipelinesTransferResult, err error, code int) {
+	b, err := json.Marshal(transfer)
+	if err != nil {
+		return result, err, http.StatusBadRequest
+	}
+	req, err := http.NewRequest(http.MethodPost, c.baseUrl+"/admin/pipeline/transfer", bytes.NewBuffer(b))
+	return do[lib.PipelinesTransferResult](req, token, userId)
+}
+
 func (c *Client) GetPipeline(token string, userId string, id string) (pipeline lib.Pipeline, err error, code int) {
 	req, err := http.NewRequest(http.MethodGet, c.baseUrl+"/pipeline/"+id, nil)
 	return do[lib.Pipeline](req, token, userId)
@@ -87,7 +170,156 @@ func (c *Client) DeletePipeline(token string, userId string, id string) (err err
 	return err, code
 }
 
+// DeletePipelineCheckDownstream deletes a pipeline unless other pipelines consume its output,
+// which results in http.StatusConflict.
+func (c *Client) DeletePipelineCheckDownstream(token string, userId string, id string) (err error, code int) {
+	req, err := http.NewRequest(http.MethodDelete, c.baseUrl+"/pipeline/"+id+"?checkDownstream=true", nil)
+	_, err, code = do[any](req, token, userId)
+	return err, code
+}
+
+// GetPipelineLineage walks the pipelines linked by topics in direction lib.LineageUpstream or
+// lib.LineageDownstream, a depth of 0 is unlimited.
+func (c *Client) GetPipelineLineage(token string, userId string, id string, direction string, depth int) (lineage lib.PipelineLineage, err error, code int) {
+	req, err := http.NewRequest(http.MethodGet, c.baseUrl+"/pipeline/"+id+"/lineage?direction="+direction+"&depth="+strconv.Itoa(depth), nil)
+	return do[lib.PipelineLineage](req, token, userId)
+}
+
+// ExportPipeline exports a pipeline in format, lib.ExportFormatJSON or lib.ExportFormatYAML for
+// bundles, lib.ExportFormatDOT or lib.ExportFormatMermaid for graphs.
+func (c *Client) ExportPipeline(token string, userId string, id string, format string) (content []byte, err error, code int) {
+	req, err := http.NewRequest(http.MethodGet, c.baseUrl+"/pipeline/"+id+"/export?format="+url.QueryEscape(format), nil)
+	if err != nil {
+		return content, err, http.StatusBadRequest
+	}
+	return doRaw(req, token, userId)
+}
+
+// ImportPipeline creates a pipeline from a bundle in format lib.ExportFormatJSON or lib.ExportFormatYAML.
+// With dryRun, the result shows the pipeline that would be created.
+func (c *Client) ImportPipeline(token string, userId string, bundle []byte, format string, dryRun bool) (result lib.PipelineImportResult, err error, code int) {
+	req, err := http.NewRequest(http.MethodPost, c.baseUrl+"/pipeline/import?format="+url.QueryEscape(format)+"&dryRun="+strconv.FormatBool(dryRun), bytes.NewBuffer(bundle))
+	if err != nil {
+		return result, err, http.StatusBadRequest
+	}
+	return do[lib.PipelineImportResult](req, token, userId)
+}
+
+// ClonePipeline copies a pipeline and returns the id of the clone.
+func (c *Client) ClonePipeline(token string, userId string, id string, options lib.PipelineCloneOptions) (cloneId string, err error, code int) {
+	b, err := json.Marshal(options)
+	if err != nil {
+		return cloneId, err, http.StatusBadRequest
+	}
+	req, err := http.NewRequest(http.MethodPost, c.baseUrl+"/pipeline/"+id+"/clone", bytes.NewBuffer(b))
+	result, err, code := do[map[string]string](req, token, userId)
+	return result["id"], err, code
+}
+
+// BatchPipelines creates, updates and deletes several pipelines at once.
+func (c *Client) BatchPipelines(token string, userId string, request lib.PipelineBatchRequest) (response lib.PipelineBatchResponse, err error, code int) {
+	b, err := json.Marshal(request)
+	if err != nil {
+		return response, err, http.StatusBadRequest
+	}
+	req, err := http.NewRequest(http.MethodPost, c.baseUrl+"/pipeline/batch", bytes.NewBuffer(b))
+	return do[lib.PipelineBatchResponse](req, token, userId)
+}
+
+func (c *Client) GetPipelinePermissions(token string, userId string, id string) (permissions lib.PipelinePermissions, err error, code int) {
+	req, err := http.NewRequest(http.MethodGet, c.baseUrl+"/pipeline/"+id+"/permissions", nil)
+	return do[lib.PipelinePermissions](req, token, userId)
+}
+
+// SetPipelinePermissions replaces the permissions granted on a pipeline.
+func (c *Client) SetPipelinePermissions(token string, userId string, id string, permissions lib.PipelinePermissions) (updated lib.PipelinePermissions, err error, code int) {
+	b, err := json.Marshal(permissions)
+	if err != nil {
+		return updated, err, http.StatusBadRequest
+	}
+	req, err := http.NewRequest(http.MethodPut, c.baseUrl+"/pipeline/"+id+"/permissions", bytes.NewBuffer(b))
+	return do[lib.PipelinePermissions](req, token, userId)
+}
+
+// TransferPipeline hands a pipeline over to another user.
+func (c *Client) TransferPipeline(token string, userId string, id string, transfer lib.PipelineTransfer) (err error, code int) {
+	b, err := json.Marshal(transfer)
+	if err != nil {
+		return err, http.StatusBadRequest
+	}
+	req, err := http.NewRequest(http.MethodPost, c.baseUrl+"/pipeline/"+id+"/transfer", bytes.NewBuffer(b))
+	_, err, code = do[any](req, token, userId)
+	return err, code
+}
+
 func (c *Client) GetFlowUsageById(token string, userId string, id string) (usage *lib.FlowUsage, err error, code int) {
 	req, err := http.NewRequest(http.MethodGet, c.baseUrl+"/pipeline/statistics/flowusage/"+id, nil)
 	return do[*lib.FlowUsage](req, token, userId)
 }
+
+func (c *Client) GetTopicUsage(token string, userId string, topic string) (usage *lib.TopicUsage, err error, code int) {
+	req, err := http.NewRequest(http.MethodGet, c.baseUrl+"/pipeline/statistics/topicusage/"+url.PathEscape(topic), nil)
+	return do[*lib.TopicUsage](req, token, userId)
+}
+
+func (c *Client) GetSelectableUsage(token string, userId string, id string) (usage *lib.SelectableUsage, err error, code int) {
+	req, err := http.NewRequest(http.MethodGet, c.baseUrl+"/pipeline/statistics/selectableusage/"+url.PathEscape(id), nil)
+	return do[*lib.SelectableUsage](req, token, userId)
+}
+
+func (c *Client) GetPipelineRevisions(token string, userId string, id string) (revisions []lib.PipelineRevision, err error, code int) {
+	req, err := http.NewRequest(http.MethodGet, c.baseUrl+"/pipeline/"+id+"/revisions", nil)
+	return do[[]lib.PipelineRevision](req, token, userId)
+}
+
+func (c *Client) GetPipelineRevision(token string, userId string, id string, revision int) (result lib.PipelineRevision, err error, code int) {
+	req, err := http.NewRequest(http.MethodGet, c.baseUrl+"/pipeline/"+id+"/revisions/"+strconv.Itoa(revision), nil)
+	return do[lib.PipelineRevision](req, token, userId)
+}
+
+func (c *Client) RollbackPipeline(token string, userId string, id string, revision int) (err error, code int) {
+	req, err := http.NewRequest(http.MethodPost, c.baseUrl+"/pipeline/"+id+"/revisions/"+strconv.Itoa(revision)+"/rollback", nil)
+	_, err, code = do[any](req, token, userId)
+	return err, code
+}
+
+// DiffPipeline compares two revisions of a pipeline, a to revision of 0 compares with the current pipeline.
+func (c *Client) DiffPipeline(token string, userId string, id string, from int, to int) (diff lib.PipelineDiff, err error, code int) {
+	url := c.baseUrl + "/pipeline/" + id + "/diff?from=" + strconv.Itoa(from)
+	if to > 0 {
+		url += "&to=" + strconv.Itoa(to)
+	}
+	req, err := http.NewRequest(http.MethodGet, url, nil)
+	return do[lib.PipelineDiff](req, token, userId)
+}
+
+func (c *Client) GetTrashedPipelines(token string, userId string, limit int, offset int) (pipelines lib.PipelinesResponse, err error, code int) {
+	req, err := http.NewRequest(http.MethodGet, c.baseUrl+"/pipeline/trash?limit="+strconv.Itoa(limit)+"&offset="+strconv.Itoa(offset), nil)
+	return do[lib.PipelinesResponse](req, token, userId)
+}
+
+func (c *Client) RestorePipeline(token string, userId string, id string) (err error, code int) {
+	req, err := http.NewRequest(http.MethodPost, c.baseUrl+"/pipeline/"+id+"/restore", nil)
+	_, err, code = do[any](req, token, userId)
+	return err, code
+}
+
+// PatchPipeline applies a patch to a pipeline, contentType selects between
+// application/merge-patch+json and application/json-patch+json.
+func (c *Client) PatchPipeline(token string, userId string, id string, patch []byte, contentType string) (pipeline lib.Pipeline, err error, code int) {
+	req, err := http.NewRequest(http.MethodPatch, c.baseUrl+"/pipeline/"+id, bytes.NewBuffer(patch))
+	if err != nil {
+		return pipeline, err, http.StatusBadRequest
+	}
+	req.Header.Set("Content-Type", contentType)
+	return do[lib.Pipeline](req, token, userId)
+}
+
+func (c *Client) UpdatePipelineStatus(token string, userId string, id string, update lib.StatusUpdate) (pipeline lib.Pipeline, err error, code int) {
+	b, err := json.Marshal(update)
+	if err != nil {
+		return pipeline, err, http.StatusBadRequest
+	}
+	req, err := http.NewRequest(http.MethodPost, c.baseUrl+"/pipeline/"+id+"/status", bytes.NewBuffer(b))
+	return do[lib.Pipeline](req, token, userId)
+}
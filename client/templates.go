@@ -0,0 +1,80 @@
+/*
+ * Copyright 2025 InfAI (CC SES)
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *      http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package client
+
+import (
+	"bytes"
+	"encoding/json"
+	"net/http"
+
+	"github.com/SENERGY-Platform/analytics-pipeline/lib"
+)
+
+func (c *Client) CreateTemplate(token string, userId string, template lib.PipelineTemplate) (created lib.PipelineTemplate, err error, code int) {
+	b, err := json.Marshal(template)
+	if err != nil {
+		return created, err, http.StatusBadRequest
+	}
+	req, err := http.NewRequest(http.MethodPost, c.baseUrl+"/template", bytes.NewBuffer(b))
+	return do[lib.PipelineTemplate](req, token, userId)
+}
+
+func (c *Client) GetTemplates(token string, userId string) (templates []lib.PipelineTemplate, err error, code int) {
+	req, err := http.NewRequest(http.MethodGet, c.baseUrl+"/template", nil)
+	return do[[]lib.PipelineTemplate](req, token, userId)
+}
+
+func (c *Client) GetTemplate(token string, userId string, id string) (template lib.PipelineTemplate, err error, code int) {
+	req, err := http.NewRequest(http.MethodGet, c.baseUrl+"/template/"+id, nil)
+	return do[lib.PipelineTemplate](req, token, userId)
+}
+
+func (c *Client) UpdateTemplate(token string, userId string, template lib.PipelineTemplate) (updated lib.PipelineTemplate, err error, code int) {
+	b, err := json.Marshal(template)
+	if err != nil {
+		return updated, err, http.StatusBadRequest
+	}
+	req, err := http.NewRequest(http.MethodPut, c.baseUrl+"/template/"+template.Id, bytes.NewBuffer(b))
+	return do[lib.PipelineTemplate](req, token, userId)
+}
+
+func (c *Client) DeleteTemplate(token string, userId string, id string) (err error, code int) {
+	req, err := http.NewRequest(http.MethodDelete, c.baseUrl+"/template/"+id, nil)
+	_, err, code = do[any](req, token, userId)
+	return err, code
+}
+
+// InstantiateTemplate creates a pipeline from a template and returns its id.
+func (c *Client) InstantiateTemplate(token string, userId string, id string, request lib.TemplateInstantiation) (pipelineId string, err error, code int) {
+	b, err := json.Marshal(request)
+	if err != nil {
+		return pipelineId, err, http.StatusBadRequest
+	}
+	req, err := http.NewRequest(http.MethodPost, c.baseUrl+"/template/"+id+"/instantiate", bytes.NewBuffer(b))
+	result, err, code := do[map[string]string](req, token, userId)
+	return result["id"], err, code
+}
+
+// RenderPipeline renders a pipeline created from a template again, values override the stored ones.
+func (c *Client) RenderPipeline(token string, userId string, id string, values map[string]any) (pipeline lib.Pipeline, err error, code int) {
+	b, err := json.Marshal(lib.TemplateInstantiation{Values: values})
+	if err != nil {
+		return pipeline, err, http.StatusBadRequest
+	}
+	req, err := http.NewRequest(http.MethodPost, c.baseUrl+"/pipeline/"+id+"/render", bytes.NewBuffer(b))
+	return do[lib.Pipeline](req, token, userId)
+}
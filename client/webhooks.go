@@ -0,0 +1,55 @@
+/*
+ * Copyright 2025 InfAI (CC SES)
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *      http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package client
+
+import (
+	"bytes"
+	"encoding/json"
+	"net/http"
+
+	"github.com/SENERGY-Platform/analytics-pipeline/lib"
+)
+
+func (c *Client) CreateWebhook(token string, userId string, subscription lib.WebhookSubscription) (created lib.WebhookSubscription, err error, code int) {
+	b, err := json.Marshal(subscription)
+	if err != nil {
+		return created, err, http.StatusBadRequest
+	}
+	req, err := http.NewRequest(http.MethodPost, c.baseUrl+"/webhook", bytes.NewBuffer(b))
+	return do[lib.WebhookSubscription](req, token, userId)
+}
+
+func (c *Client) GetWebhooks(token string, userId string) (subscriptions []lib.WebhookSubscription, err error, code int) {
+	req, err := http.NewRequest(http.MethodGet, c.baseUrl+"/webhook", nil)
+	return do[[]lib.WebhookSubscription](req, token, userId)
+}
+
+func (c *Client) DeleteWebhook(token string, userId string, id string) (err error, code int) {
+	req, err := http.NewRequest(http.MethodDelete, c.baseUrl+"/webhook/"+id, nil)
+	_, err, code = do[any](req, token, userId)
+	return err, code
+}
+
+func (c *Client) GetWebhookDeliveries(token string, userId string, id string) (deliveries []lib.WebhookDelivery, err error, code int) {
+	req, err := http.NewRequest(http.MethodGet, c.baseUrl+"/webhook/"+id+"/deliveries", nil)
+	return do[[]lib.WebhookDelivery](req, token, userId)
+}
+
+func (c *Client) GetWebhookDeadLetters(token string, userId string, id string) (letters []lib.WebhookDeadLetter, err error, code int) {
+	req, err := http.NewRequest(http.MethodGet, c.baseUrl+"/webhook/"+id+"/deadletters", nil)
+	return do[[]lib.WebhookDeadLetter](req, token, userId)
+}
@@ -0,0 +1,61 @@
+/*
+ * Copyright 2025 InfAI (CC SES)
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *      http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package lib
+
+const (
+	BatchOpCreate = "create"
+	BatchOpUpdate = "update"
+	BatchOpDelete = "delete"
+)
+
+const (
+	BatchModeAtomic     = "atomic"
+	BatchModeBestEffort = "bestEffort"
+)
+
+// PipelineBatchRequest applies several operations at once. In BatchModeAtomic, the default,
+// either all operations are applied or none. In BatchModeBestEffort, every valid operation is
+// applied independently of the others.
+type PipelineBatchRequest struct {
+	Mode       string                   `json:"mode,omitempty"`
+	Operations []PipelineBatchOperation `json:"operations"`
+}
+
+// PipelineBatchOperation creates or updates Pipeline, or moves the pipeline with Id to the trash.
+// Updates take the id from the pipeline, a version sent with it has to match the stored one.
+type PipelineBatchOperation struct {
+	Op       string    `json:"op"`
+	Id       string    `json:"id,omitempty"`
+	Pipeline *Pipeline `json:"pipeline,omitempty"`
+}
+
+type PipelineBatchResponse struct {
+	Mode    string                `json:"mode"`
+	Results []PipelineBatchResult `json:"results"`
+}
+
+// PipelineBatchResult reports the outcome of the operation at Index with a HTTP status code.
+// Operations that were valid but not applied, because another operation of an atomic batch
+// failed, report http.StatusFailedDependency.
+type PipelineBatchResult struct {
+	Index      int         `json:"index"`
+	Op         string      `json:"op"`
+	Id         string      `json:"id,omitempty"`
+	Status     int         `json:"status"`
+	Error      string      `json:"error,omitempty"`
+	Violations []Violation `json:"violations,omitempty"`
+}
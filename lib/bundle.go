@@ -0,0 +1,251 @@
+/*
+ * Copyright 2025 InfAI (CC SES)
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *      http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package lib
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"strings"
+
+	"github.com/google/uuid"
+	"go.yaml.in/yaml/v3"
+)
+
+// PipelineBundleVersion is the version of the bundle format written by NewPipelineBundle.
+const PipelineBundleVersion = 1
+
+const (
+	ExportFormatJSON = "json"
+	ExportFormatYAML = "yaml"
+)
+
+// BundlePipelineRef is the placeholder for the id of the pipeline in topic names and filter values of a bundle.
+const BundlePipelineRef = "{pipeline}"
+
+// PipelineBundle is the portable form of a pipeline. It contains no ids, user ids or timestamps.
+// Operator ids are replaced by references like operator-1, wherever the ids of operators or of
+// the pipeline occur in filter values or topic names, they are replaced by {operator-1} or
+// {pipeline}. Ids inside topic names are only detected if they are UUIDs.
+type PipelineBundle struct {
+	Version  int            `json:"version"`
+	Pipeline BundlePipeline `json:"pipeline"`
+}
+
+type BundlePipeline struct {
+	Name               string     `json:"name,omitempty"`
+	Description        string     `json:"description,omitempty"`
+	FlowId             string     `json:"flowId,omitempty"`
+	Image              string     `json:"image,omitempty"`
+	WindowTime         int        `json:"windowTime,omitempty"`
+	MergeStrategy      string     `json:"mergeStrategy,omitempty"`
+	ConsumeAllMessages bool       `json:"consumeAllMessages,omitempty"`
+	Metrics            bool       `json:"metrics,omitempty"`
+	Operators          []Operator `json:"operators,omitempty"`
+}
+
+// PipelineImportResult describes the pipeline created from a bundle, or the one that would be
+// created on a dry run. OperatorIds maps the operator references of the bundle to the new ids.
+type PipelineImportResult struct {
+	DryRun      bool              `json:"dryRun"`
+	Pipeline    Pipeline          `json:"pipeline"`
+	OperatorIds map[string]string `json:"operatorIds"`
+}
+
+// NewPipelineBundle converts a pipeline to its bundle.
+func NewPipelineBundle(pipeline Pipeline) PipelineBundle {
+	refs := map[string]string{}
+	if pipeline.Id != "" {
+		refs[pipeline.Id] = BundlePipelineRef
+	}
+	operators := make([]Operator, len(pipeline.Operators))
+	for i, operator := range pipeline.Operators {
+		ref := fmt.Sprintf("operator-%d", i+1)
+		if operator.Id != "" {
+			refs[operator.Id] = "{" + ref + "}"
+		}
+		operator.Id = ref
+		operators[i] = operator
+	}
+	for i := range operators {
+		operators[i] = copyOperator(operators[i])
+		operators[i].OutputTopic = replaceIds(operators[i].OutputTopic, refs)
+		for j, topic := range operators[i].InputTopics {
+			topic.Name = replaceIds(topic.Name, refs)
+			segments := strings.Split(topic.FilterValue, ":")
+			for k, segment := range segments {
+				if ref, ok := refs[segment]; ok {
+					segments[k] = ref
+				}
+			}
+			topic.FilterValue = strings.Join(segments, ":")
+			operators[i].InputTopics[j] = topic
+		}
+	}
+	return PipelineBundle{
+		Version: PipelineBundleVersion,
+		Pipeline: BundlePipeline{
+			Name:               pipeline.Name,
+			Description:        pipeline.Description,
+			FlowId:             pipeline.FlowId,
+			Image:              pipeline.Image,
+			WindowTime:         pipeline.WindowTime,
+			MergeStrategy:      pipeline.MergeStrategy,
+			ConsumeAllMessages: pipeline.ConsumeAllMessages,
+			Metrics:            pipeline.Metrics,
+			Operators:          operators,
+		},
+	}
+}
+
+// replaceIds replaces UUIDs in value by their references.
+func replaceIds(value string, refs map[string]string) string {
+	for id, ref := range refs {
+		if _, err := uuid.Parse(id); err == nil {
+			value = strings.ReplaceAll(value, id, ref)
+		}
+	}
+	return value
+}
+
+// copyOperator copies the slices of an operator, so that it can be modified without changing the original.
+func copyOperator(operator Operator) Operator {
+	operator.InputTopics = append([]InputTopic(nil), operator.InputTopics...)
+	operator.InputSelections = append([]InputSelection(nil), operator.InputSelections...)
+	if operator.Config != nil {
+		config := make(map[string]string, len(operator.Config))
+		for key, value := range operator.Config {
+			config[key] = value
+		}
+		operator.Config = config
+	}
+	return operator
+}
+
+// PipelineFromBundle converts a bundle to a pipeline with id pipelineId. Each operator gets a new
+// id from newId, the references in topic names and filter values are replaced consistently.
+func PipelineFromBundle(bundle PipelineBundle, pipelineId string, newId func() string) (pipeline Pipeline, operatorIds map[string]string, err error) {
+	if bundle.Version != PipelineBundleVersion {
+		return pipeline, nil, NewInputError(fmt.Errorf("unsupported bundle version %d, expected %d", bundle.Version, PipelineBundleVersion))
+	}
+	operatorIds = map[string]string{}
+	replacements := []string{BundlePipelineRef, pipelineId}
+	operators := make([]Operator, len(bundle.Pipeline.Operators))
+	for i, operator := range bundle.Pipeline.Operators {
+		id, ok := operatorIds[operator.Id]
+		if !ok {
+			id = newId()
+			if operator.Id != "" {
+				operatorIds[operator.Id] = id
+				replacements = append(replacements, "{"+operator.Id+"}", id)
+			}
+		}
+		operator = copyOperator(operator)
+		operator.Id = id
+		operators[i] = operator
+	}
+	replacer := strings.NewReplacer(replacements...)
+	for i := range operators {
+		operators[i].OutputTopic = replacer.Replace(operators[i].OutputTopic)
+		for j := range operators[i].InputTopics {
+			operators[i].InputTopics[j].Name = replacer.Replace(operators[i].InputTopics[j].Name)
+			operators[i].InputTopics[j].FilterValue = replacer.Replace(operators[i].InputTopics[j].FilterValue)
+		}
+	}
+	pipeline = Pipeline{
+		Id:                 pipelineId,
+		Name:               bundle.Pipeline.Name,
+		Description:        bundle.Pipeline.Description,
+		FlowId:             bundle.Pipeline.FlowId,
+		Image:              bundle.Pipeline.Image,
+		WindowTime:         bundle.Pipeline.WindowTime,
+		MergeStrategy:      bundle.Pipeline.MergeStrategy,
+		ConsumeAllMessages: bundle.Pipeline.ConsumeAllMessages,
+		Metrics:            bundle.Pipeline.Metrics,
+		Operators:          operators,
+	}
+	return
+}
+
+// MarshalBundle encodes a bundle in ExportFormatJSON or ExportFormatYAML. Both formats use
+// the field names of the JSON API.
+func MarshalBundle(bundle PipelineBundle, format string) ([]byte, error) {
+	b, err := json.MarshalIndent(bundle, "", "  ")
+	if err != nil {
+		return nil, err
+	}
+	switch format {
+	case ExportFormatJSON:
+		return b, nil
+	case ExportFormatYAML:
+		// JSON is valid YAML, decoding it into a node keeps the order of the fields
+		var node yaml.Node
+		err = yaml.Unmarshal(b, &node)
+		if err != nil {
+			return nil, err
+		}
+		setDefaultStyle(&node)
+		var buf bytes.Buffer
+		encoder := yaml.NewEncoder(&buf)
+		encoder.SetIndent(2)
+		err = encoder.Encode(&node)
+		if err != nil {
+			return nil, err
+		}
+		err = encoder.Close()
+		return buf.Bytes(), err
+	default:
+		return nil, fmt.Errorf("unknown bundle format %q", format)
+	}
+}
+
+// setDefaultStyle drops the flow style and quotes the JSON input implies. Strings are still
+// quoted where they would otherwise be read as another type.
+func setDefaultStyle(node *yaml.Node) {
+	node.Style = 0
+	for _, child := range node.Content {
+		setDefaultStyle(child)
+	}
+}
+
+// UnmarshalBundle decodes a bundle in ExportFormatJSON or ExportFormatYAML. Unknown fields are
+// rejected, errors are reported as InputError.
+func UnmarshalBundle(data []byte, format string) (bundle PipelineBundle, err error) {
+	switch format {
+	case ExportFormatJSON:
+	case ExportFormatYAML:
+		var value any
+		err = yaml.Unmarshal(data, &value)
+		if err != nil {
+			return bundle, NewInputError(err)
+		}
+		data, err = json.Marshal(value)
+		if err != nil {
+			return bundle, NewInputError(err)
+		}
+	default:
+		return bundle, NewInputError(fmt.Errorf("unknown bundle format %q", format))
+	}
+	decoder := json.NewDecoder(bytes.NewReader(data))
+	decoder.DisallowUnknownFields()
+	err = decoder.Decode(&bundle)
+	if err != nil {
+		return bundle, NewInputError(errors.Join(errors.New("invalid bundle"), err))
+	}
+	return
+}
@@ -0,0 +1,103 @@
+/*
+ * Copyright 2025 InfAI (CC SES)
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *      http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package lib
+
+import (
+	"errors"
+	"strconv"
+	"strings"
+	"testing"
+	"time"
+)
+
+const (
+	bundleTestPipelineId = "8c4e4bd5-4e0b-4a0b-9f3e-2f0f1c6b1a11"
+	bundleTestOperatorA  = "0b0e3d86-5f7e-4a35-8f8e-6c1b2f0e6a01"
+	bundleTestOperatorB  = "0b0e3d86-5f7e-4a35-8f8e-6c1b2f0e6a02"
+)
+
+func bundleTestPipeline() Pipeline {
+	return Pipeline{
+		Id:        bundleTestPipelineId,
+		Name:      "demo",
+		UserId:    "user",
+		CreatedAt: time.Now(),
+		UpdatedAt: time.Now(),
+		Version:   3,
+		Status:    PipelineStatusRunning,
+		Operators: []Operator{
+			{Id: bundleTestOperatorA, OperatorId: "adder", OutputTopic: "out-" + bundleTestOperatorA, Config: map[string]string{"factor": "5"}, InputTopics: []InputTopic{
+				{Name: "device", FilterType: FilterTypeDeviceId, FilterValue: "d1"},
+			}},
+			{Id: bundleTestOperatorB, OperatorId: "sum", InputTopics: []InputTopic{
+				{Name: "out-" + bundleTestOperatorA, FilterType: FilterTypeOperatorId, FilterValue: bundleTestOperatorA + ":" + bundleTestPipelineId},
+			}},
+		},
+	}
+}
+
+func TestPipelineBundle_RoundTrip(t *testing.T) {
+	for _, format := range []string{ExportFormatJSON, ExportFormatYAML} {
+		data, err := MarshalBundle(NewPipelineBundle(bundleTestPipeline()), format)
+		if err != nil {
+			t.Fatal(err)
+		}
+		for _, stripped := range []string{bundleTestPipelineId, bundleTestOperatorA, "user", "createdAt", "running"} {
+			if strings.Contains(string(data), stripped) {
+				t.Errorf("%s bundle contains %s:\n%s", format, stripped, data)
+			}
+		}
+		bundle, err := UnmarshalBundle(data, format)
+		if err != nil {
+			t.Fatal(err)
+		}
+		n := 0
+		pipeline, operatorIds, err := PipelineFromBundle(bundle, "new-pipeline", func() string {
+			n++
+			return "new-" + strconv.Itoa(n)
+		})
+		if err != nil {
+			t.Fatal(err)
+		}
+		a, b := pipeline.Operators[0], pipeline.Operators[1]
+		if a.Id != "new-1" || b.Id != "new-2" || operatorIds["operator-1"] != "new-1" {
+			t.Errorf("unexpected operator ids %s %s %v", a.Id, b.Id, operatorIds)
+		}
+		if a.OutputTopic != "out-new-1" || b.InputTopics[0].Name != "out-new-1" || b.InputTopics[0].FilterValue != "new-1:new-pipeline" {
+			t.Errorf("references not remapped: %+v", pipeline.Operators)
+		}
+		if a.Config["factor"] != "5" || pipeline.Id != "new-pipeline" || pipeline.Name != "demo" {
+			t.Errorf("unexpected pipeline %+v", pipeline)
+		}
+	}
+}
+
+func TestPipelineBundle_Invalid(t *testing.T) {
+	var inputErr *InputError
+	_, err := UnmarshalBundle([]byte(`{"version": 1, "pipeline": {"id": "x"}}`), ExportFormatJSON)
+	if !errors.As(err, &inputErr) {
+		t.Errorf("expected input error for unknown field, got %v", err)
+	}
+	bundle, err := UnmarshalBundle([]byte("version: 2\npipeline:\n  name: x\n"), ExportFormatYAML)
+	if err != nil {
+		t.Fatal(err)
+	}
+	_, _, err = PipelineFromBundle(bundle, "p", func() string { return "o" })
+	if !errors.As(err, &inputErr) {
+		t.Errorf("expected input error for unsupported version, got %v", err)
+	}
+}
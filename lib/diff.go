@@ -0,0 +1,252 @@
+/*
+ * Copyright 2025 InfAI (CC SES)
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *      http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package lib
+
+import (
+	"encoding/json"
+	"fmt"
+	"maps"
+	"reflect"
+	"slices"
+	"strconv"
+	"strings"
+	"time"
+)
+
+const (
+	ChangeAdded   = "added"
+	ChangeRemoved = "removed"
+	ChangeChanged = "changed"
+)
+
+type PipelineDiff struct {
+	From      string         `json:"from"`
+	To        string         `json:"to"`
+	Fields    []FieldChange  `json:"fields,omitempty"`
+	Operators []OperatorDiff `json:"operators,omitempty"`
+}
+
+type FieldChange struct {
+	Field string `json:"field"`
+	Old   any    `json:"old,omitempty"`
+	New   any    `json:"new,omitempty"`
+}
+
+type OperatorDiff struct {
+	Id          string           `json:"id"`
+	Change      string           `json:"change"`
+	Fields      []FieldChange    `json:"fields,omitempty"`
+	InputTopics []InputTopicDiff `json:"inputTopics,omitempty"`
+}
+
+// InputTopicDiff identifies an input topic by its name. Repeated names are
+// distinguished by their occurrence, e.g. device-topic#2.
+type InputTopicDiff struct {
+	Name   string        `json:"name"`
+	Change string        `json:"change"`
+	Fields []FieldChange `json:"fields,omitempty"`
+}
+
+// DiffPipelines compares two versions of a pipeline. Operators are matched by Id, input topics by
+// name. Ids, owner, timestamps, version, status and trash state of the pipeline are ignored.
+func DiffPipelines(from Pipeline, to Pipeline, fromLabel string, toLabel string) (diff PipelineDiff) {
+	diff.From = fromLabel
+	diff.To = toLabel
+	strip := func(p Pipeline) Pipeline {
+		p.Id, p.UserId, p.Operators = "", "", nil
+		p.CreatedAt, p.UpdatedAt, p.DeletedAt = time.Time{}, time.Time{}, nil
+		p.Version, p.Status, p.StatusTransitions = 0, "", nil
+		return p
+	}
+	diff.Fields = diffFields(flatten(strip(from)), flatten(strip(to)))
+
+	fromOperators := map[string]Operator{}
+	for _, operator := range from.Operators {
+		fromOperators[operator.Id] = operator
+	}
+	toIds := map[string]bool{}
+	for _, operator := range to.Operators {
+		toIds[operator.Id] = true
+		old, ok := fromOperators[operator.Id]
+		if !ok {
+			diff.Operators = append(diff.Operators, diffOperator(Operator{}, operator, ChangeAdded))
+			continue
+		}
+		if d := diffOperator(old, operator, ChangeChanged); len(d.Fields) > 0 || len(d.InputTopics) > 0 {
+			diff.Operators = append(diff.Operators, d)
+		}
+	}
+	for _, operator := range from.Operators {
+		if !toIds[operator.Id] {
+			diff.Operators = append(diff.Operators, diffOperator(operator, Operator{}, ChangeRemoved))
+		}
+	}
+	return
+}
+
+func diffOperator(from Operator, to Operator, change string) OperatorDiff {
+	d := OperatorDiff{Id: to.Id, Change: change}
+	if change == ChangeRemoved {
+		d.Id = from.Id
+	}
+	fromTopics, toTopics := keyInputTopics(from.InputTopics), keyInputTopics(to.InputTopics)
+	from.Id, from.InputTopics = "", nil
+	to.Id, to.InputTopics = "", nil
+	switch change {
+	case ChangeAdded:
+		d.Fields = diffFields(nil, flatten(to))
+	case ChangeRemoved:
+		d.Fields = diffFields(flatten(from), nil)
+	default:
+		d.Fields = diffFields(flatten(from), flatten(to))
+	}
+	for _, key := range sortedKeys(fromTopics, toTopics) {
+		old, inFrom := fromTopics[key]
+		cur, inTo := toTopics[key]
+		switch {
+		case !inFrom:
+			d.InputTopics = append(d.InputTopics, InputTopicDiff{Name: key, Change: ChangeAdded, Fields: diffFields(nil, flattenInputTopic(cur))})
+		case !inTo:
+			d.InputTopics = append(d.InputTopics, InputTopicDiff{Name: key, Change: ChangeRemoved, Fields: diffFields(flattenInputTopic(old), nil)})
+		default:
+			if fields := diffFields(flattenInputTopic(old), flattenInputTopic(cur)); len(fields) > 0 {
+				d.InputTopics = append(d.InputTopics, InputTopicDiff{Name: key, Change: ChangeChanged, Fields: fields})
+			}
+		}
+	}
+	return d
+}
+
+func keyInputTopics(topics []InputTopic) map[string]InputTopic {
+	result := map[string]InputTopic{}
+	count := map[string]int{}
+	for _, topic := range topics {
+		count[topic.Name]++
+		key := topic.Name
+		if count[topic.Name] > 1 {
+			key += "#" + strconv.Itoa(count[topic.Name])
+		}
+		result[key] = topic
+	}
+	return result
+}
+
+// flattenInputTopic flattens an input topic, mappings are keyed by their destination.
+func flattenInputTopic(topic InputTopic) map[string]any {
+	mappings := topic.Mappings
+	topic.Name, topic.Mappings = "", nil
+	result := flatten(topic)
+	for _, mapping := range mappings {
+		result["mappings."+mapping.Dest] = mapping.Source
+	}
+	return result
+}
+
+// flatten maps the JSON representation of value to dotted field paths.
+func flatten(value any) map[string]any {
+	result := map[string]any{}
+	b, err := json.Marshal(value)
+	if err != nil {
+		return result
+	}
+	var raw any
+	if err = json.Unmarshal(b, &raw); err != nil {
+		return result
+	}
+	var walk func(prefix string, v any)
+	walk = func(prefix string, v any) {
+		switch t := v.(type) {
+		case map[string]any:
+			for key, child := range t {
+				if prefix != "" {
+					key = prefix + "." + key
+				}
+				walk(key, child)
+			}
+		case []any:
+			for i, child := range t {
+				walk(fmt.Sprintf("%s[%d]", prefix, i), child)
+			}
+		default:
+			result[prefix] = v
+		}
+	}
+	walk("", raw)
+	return result
+}
+
+func diffFields(from map[string]any, to map[string]any) (changes []FieldChange) {
+	for _, key := range sortedKeys(from, to) {
+		old, inFrom := from[key]
+		cur, inTo := to[key]
+		if inFrom && inTo && reflect.DeepEqual(old, cur) {
+			continue
+		}
+		changes = append(changes, FieldChange{Field: key, Old: old, New: cur})
+	}
+	return
+}
+
+func sortedKeys[V any](a map[string]V, b map[string]V) []string {
+	keys := slices.Collect(maps.Keys(a))
+	for key := range b {
+		if _, ok := a[key]; !ok {
+			keys = append(keys, key)
+		}
+	}
+	slices.Sort(keys)
+	return keys
+}
+
+// Text renders the diff as a plain-text unified view.
+func (d PipelineDiff) Text() string {
+	var sb strings.Builder
+	sb.WriteString("--- " + d.From + "\n")
+	sb.WriteString("+++ " + d.To + "\n")
+	if len(d.Fields) > 0 {
+		sb.WriteString("@@ pipeline @@\n")
+		writeFieldChanges(&sb, "", d.Fields)
+	}
+	for _, operator := range d.Operators {
+		sb.WriteString(fmt.Sprintf("@@ operator %s (%s) @@\n", operator.Id, operator.Change))
+		writeFieldChanges(&sb, "", operator.Fields)
+		for _, topic := range operator.InputTopics {
+			sb.WriteString(fmt.Sprintf(" inputTopic %s (%s)\n", topic.Name, topic.Change))
+			writeFieldChanges(&sb, "  ", topic.Fields)
+		}
+	}
+	return sb.String()
+}
+
+func writeFieldChanges(sb *strings.Builder, indent string, changes []FieldChange) {
+	for _, change := range changes {
+		if change.Old != nil {
+			sb.WriteString("-" + indent + change.Field + ": " + formatDiffValue(change.Old) + "\n")
+		}
+		if change.New != nil {
+			sb.WriteString("+" + indent + change.Field + ": " + formatDiffValue(change.New) + "\n")
+		}
+	}
+}
+
+func formatDiffValue(value any) string {
+	b, err := json.Marshal(value)
+	if err != nil {
+		return fmt.Sprint(value)
+	}
+	return string(b)
+}
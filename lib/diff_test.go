@@ -0,0 +1,118 @@
+/*
+ * Copyright 2025 InfAI (CC SES)
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *      http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package lib
+
+import (
+	"slices"
+	"testing"
+)
+
+func diffTestOperator(id string, config map[string]string, inputs ...InputTopic) Operator {
+	operator := graphTestOperator(id, "topic-"+id)
+	operator.Config = config
+	operator.InputTopics = inputs
+	return operator
+}
+
+func TestDiffPipelines_Reordered(t *testing.T) {
+	from := Pipeline{Id: "p", Name: "test", Version: 1, Operators: []Operator{
+		diffTestOperator("a", map[string]string{"limit": "1"}),
+		diffTestOperator("b", nil),
+	}}
+	to := Pipeline{Id: "p", Name: "test", Version: 2, Status: PipelineStatusRunning, Operators: []Operator{
+		diffTestOperator("b", nil),
+		diffTestOperator("a", map[string]string{"limit": "1"}),
+	}}
+	diff := DiffPipelines(from, to, "1", "2")
+	if len(diff.Fields) > 0 || len(diff.Operators) > 0 {
+		t.Errorf("unexpected diff: %+v", diff)
+	}
+}
+
+func TestDiffPipelines_Operators(t *testing.T) {
+	from := Pipeline{Operators: []Operator{
+		diffTestOperator("a", map[string]string{"limit": "1", "unit": "s"}),
+		diffTestOperator("b", nil),
+	}}
+	to := Pipeline{Operators: []Operator{
+		diffTestOperator("a", map[string]string{"limit": "2", "mode": "sum"}),
+		diffTestOperator("c", nil),
+	}}
+	diff := DiffPipelines(from, to, "1", "2")
+	if len(diff.Operators) != 3 {
+		t.Fatalf("unexpected operators: %+v", diff.Operators)
+	}
+	changed, added, removed := diff.Operators[0], diff.Operators[1], diff.Operators[2]
+	if changed.Id != "a" || changed.Change != ChangeChanged {
+		t.Errorf("unexpected changed operator: %+v", changed)
+	}
+	expected := []FieldChange{
+		{Field: "config.limit", Old: "1", New: "2"},
+		{Field: "config.mode", New: "sum"},
+		{Field: "config.unit", Old: "s"},
+	}
+	if !slices.Equal(changed.Fields, expected) {
+		t.Errorf("unexpected config changes: %+v", changed.Fields)
+	}
+	if added.Id != "c" || added.Change != ChangeAdded || len(added.Fields) == 0 {
+		t.Errorf("unexpected added operator: %+v", added)
+	}
+	if removed.Id != "b" || removed.Change != ChangeRemoved || len(removed.Fields) == 0 {
+		t.Errorf("unexpected removed operator: %+v", removed)
+	}
+}
+
+func TestDiffPipelines_RepeatedInputTopics(t *testing.T) {
+	from := Pipeline{Operators: []Operator{diffTestOperator("a", nil,
+		InputTopic{Name: "device", FilterValue: "d1"},
+		InputTopic{Name: "device", FilterValue: "d2"},
+	)}}
+	to := Pipeline{Operators: []Operator{diffTestOperator("a", nil,
+		InputTopic{Name: "device", FilterValue: "d1"},
+		InputTopic{Name: "device", FilterValue: "d3"},
+		InputTopic{Name: "device", FilterValue: "d4"},
+	)}}
+	diff := DiffPipelines(from, to, "1", "2")
+	if len(diff.Operators) != 1 || len(diff.Operators[0].InputTopics) != 2 {
+		t.Fatalf("unexpected diff: %+v", diff)
+	}
+	topics := diff.Operators[0].InputTopics
+	if topics[0].Name != "device#2" || topics[0].Change != ChangeChanged ||
+		!slices.Equal(topics[0].Fields, []FieldChange{{Field: "filterValue", Old: "d2", New: "d3"}}) {
+		t.Errorf("unexpected change of second topic: %+v", topics[0])
+	}
+	if topics[1].Name != "device#3" || topics[1].Change != ChangeAdded {
+		t.Errorf("unexpected change of third topic: %+v", topics[1])
+	}
+}
+
+func TestPipelineDiff_Text(t *testing.T) {
+	from := Pipeline{Name: "old", Operators: []Operator{diffTestOperator("a", map[string]string{"limit": "1"})}}
+	to := Pipeline{Name: "new", Operators: []Operator{diffTestOperator("a", map[string]string{"limit": "2"})}}
+	expected := `--- revision 1
++++ revision 2
+@@ pipeline @@
+-name: "old"
++name: "new"
+@@ operator a (changed) @@
+-config.limit: "1"
++config.limit: "2"
+`
+	if text := DiffPipelines(from, to, "revision 1", "revision 2").Text(); text != expected {
+		t.Errorf("unexpected text:\n%s", text)
+	}
+}
@@ -16,6 +16,8 @@
 
 package lib
 
+import "strings"
+
 type cError struct {
 	err error
 }
@@ -36,6 +38,14 @@ type ForbiddenError struct {
 	cError
 }
 
+type ConflictError struct {
+	cError
+}
+
+type PreconditionFailedError struct {
+	cError
+}
+
 func (e *cError) Error() string {
 	return e.err.Error()
 }
@@ -59,3 +69,34 @@ func NewNotFoundError(err error) error {
 func NewForbiddenError(err error) error {
 	return &ForbiddenError{cError{err: err}}
 }
+
+func NewConflictError(err error) error {
+	return &ConflictError{cError{err: err}}
+}
+
+func NewPreconditionFailedError(err error) error {
+	return &PreconditionFailedError{cError{err: err}}
+}
+
+// Violation describes a single invalid field of a request body.
+// Path uses the JSON field names, e.g. operators[2].inputTopics[0].mappings.
+type Violation struct {
+	Path    string `json:"path"`
+	Message string `json:"message"`
+}
+
+type ValidationError struct {
+	Violations []Violation `json:"violations"`
+}
+
+func (e *ValidationError) Error() string {
+	parts := make([]string, 0, len(e.Violations))
+	for _, v := range e.Violations {
+		parts = append(parts, v.Path+": "+v.Message)
+	}
+	return strings.Join(parts, "; ")
+}
+
+func NewValidationError(violations []Violation) error {
+	return NewInputError(&ValidationError{Violations: violations})
+}
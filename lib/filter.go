@@ -0,0 +1,362 @@
+/*
+ * Copyright 2025 InfAI (CC SES)
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *      http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package lib
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// The filter language of the pipeline listing:
+//
+//	filter     = or
+//	or         = and { "or" and }
+//	and        = unary { ( "and" | "|" ) unary }
+//	unary      = "not" unary | "(" or ")" | condition
+//	condition  = field op value { "," value }
+//	op         = ":" | ">" | ">=" | "<" | "<="
+//	value      = word | '"' { char | '\"' } '"'
+//
+// Keywords are case-insensitive. A condition with ":" matches if the field equals any of the
+// values. Comparisons are only allowed on time fields, whose values are RFC 3339 timestamps or
+// dates (2006-01-02). A date compared with ":" matches the whole day in UTC. Fields of operators
+// and input topics match if any operator or input topic matches. Example:
+//
+//	(operator:adder,filter or flow:f1) and not metrics:true and createdAt>=2025-01-01
+//
+// The "|" separator keeps filters written as operator:a,b|flow:c working.
+
+type FilterFieldType int
+
+const (
+	FilterString FilterFieldType = iota
+	FilterBool
+	FilterTime
+)
+
+// FilterFields lists the fields that can be filtered by and the type of their values.
+var FilterFields = map[string]FilterFieldType{
+	"name":               FilterString,
+	"owner":              FilterString,
+	"status":             FilterString,
+	"flow":               FilterString,
+	"image":              FilterString,
+	"mergeStrategy":      FilterString,
+	"metrics":            FilterBool,
+	"consumeAllMessages": FilterBool,
+	"createdAt":          FilterTime,
+	"updatedAt":          FilterTime,
+	"operator":           FilterString,
+	"deploymentType":     FilterString,
+	"persistData":        FilterBool,
+	"inputTopic":         FilterString,
+	"template":           FilterString,
+}
+
+const (
+	FilterOpEq  = ":"
+	FilterOpGt  = ">"
+	FilterOpGte = ">="
+	FilterOpLt  = "<"
+	FilterOpLte = "<="
+)
+
+// FilterExpr is a node of a parsed filter, one of FilterAnd, FilterOr, FilterNot or FilterCondition.
+type FilterExpr interface {
+	filterExpr()
+}
+
+type FilterAnd struct {
+	Terms []FilterExpr
+}
+
+type FilterOr struct {
+	Terms []FilterExpr
+}
+
+type FilterNot struct {
+	Term FilterExpr
+}
+
+// FilterCondition compares a field with its values. Values are of type string, bool or
+// time.Time according to the type of the field. With FilterOpEq, any value matches.
+type FilterCondition struct {
+	Field  string
+	Op     string
+	Values []any
+}
+
+func (FilterAnd) filterExpr()       {}
+func (FilterOr) filterExpr()        {}
+func (FilterNot) filterExpr()       {}
+func (FilterCondition) filterExpr() {}
+
+// ParseFilter parses a filter into its AST. An empty filter results in a nil expression.
+// Syntax errors and unknown fields are reported as InputError.
+func ParseFilter(filter string) (FilterExpr, error) {
+	p := &filterParser{input: filter}
+	p.skipSpace()
+	if p.eof() {
+		return nil, nil
+	}
+	expr, err := p.parseOr()
+	if err != nil {
+		return nil, NewInputError(err)
+	}
+	p.skipSpace()
+	if !p.eof() {
+		return nil, NewInputError(p.errorf("unexpected %q", p.input[p.pos:]))
+	}
+	return expr, nil
+}
+
+type filterParser struct {
+	input string
+	pos   int
+}
+
+func (p *filterParser) errorf(format string, args ...any) error {
+	return fmt.Errorf("filter position %d: %s", p.pos, fmt.Sprintf(format, args...))
+}
+
+func (p *filterParser) eof() bool {
+	return p.pos >= len(p.input)
+}
+
+func (p *filterParser) skipSpace() {
+	for !p.eof() && (p.input[p.pos] == ' ' || p.input[p.pos] == '\t' || p.input[p.pos] == '\n') {
+		p.pos++
+	}
+}
+
+func isFilterDelimiter(c byte) bool {
+	return strings.IndexByte(" \t\n()|,:<>=\"", c) >= 0
+}
+
+// peekWord returns the word at the current position without consuming it.
+func (p *filterParser) peekWord() string {
+	end := p.pos
+	for end < len(p.input) && !isFilterDelimiter(p.input[end]) {
+		end++
+	}
+	return p.input[p.pos:end]
+}
+
+// keyword consumes the keyword if it follows, "|" is accepted for "and".
+func (p *filterParser) keyword(keyword string) bool {
+	p.skipSpace()
+	if keyword == "and" && !p.eof() && p.input[p.pos] == '|' {
+		p.pos++
+		return true
+	}
+	if strings.EqualFold(p.peekWord(), keyword) {
+		p.pos += len(keyword)
+		return true
+	}
+	return false
+}
+
+func (p *filterParser) parseOr() (FilterExpr, error) {
+	terms := []FilterExpr{}
+	for {
+		term, err := p.parseAnd()
+		if err != nil {
+			return nil, err
+		}
+		terms = append(terms, term)
+		if !p.keyword("or") {
+			break
+		}
+	}
+	if len(terms) == 1 {
+		return terms[0], nil
+	}
+	return FilterOr{Terms: terms}, nil
+}
+
+func (p *filterParser) parseAnd() (FilterExpr, error) {
+	terms := []FilterExpr{}
+	for {
+		term, err := p.parseUnary()
+		if err != nil {
+			return nil, err
+		}
+		terms = append(terms, term)
+		if !p.keyword("and") {
+			break
+		}
+	}
+	if len(terms) == 1 {
+		return terms[0], nil
+	}
+	return FilterAnd{Terms: terms}, nil
+}
+
+func (p *filterParser) parseUnary() (FilterExpr, error) {
+	if p.keyword("not") {
+		term, err := p.parseUnary()
+		if err != nil {
+			return nil, err
+		}
+		return FilterNot{Term: term}, nil
+	}
+	p.skipSpace()
+	if !p.eof() && p.input[p.pos] == '(' {
+		p.pos++
+		expr, err := p.parseOr()
+		if err != nil {
+			return nil, err
+		}
+		p.skipSpace()
+		if p.eof() || p.input[p.pos] != ')' {
+			return nil, p.errorf("missing )")
+		}
+		p.pos++
+		return expr, nil
+	}
+	return p.parseCondition()
+}
+
+func (p *filterParser) parseCondition() (FilterExpr, error) {
+	start := p.pos
+	field := p.peekWord()
+	if field == "" {
+		return nil, p.errorf("expected field")
+	}
+	fieldType, ok := FilterFields[field]
+	if !ok {
+		return nil, p.errorf("unknown field %q", field)
+	}
+	p.pos += len(field)
+	op, err := p.parseOp()
+	if err != nil {
+		return nil, err
+	}
+	if op != FilterOpEq && fieldType != FilterTime {
+		return nil, p.errorf("operator %s is only allowed on time fields", op)
+	}
+	raw := []string{}
+	for {
+		value, err := p.parseValue()
+		if err != nil {
+			return nil, err
+		}
+		raw = append(raw, value)
+		if p.eof() || p.input[p.pos] != ',' {
+			break
+		}
+		p.pos++
+	}
+	if op != FilterOpEq && len(raw) > 1 {
+		p.pos = start
+		return nil, p.errorf("operator %s takes a single value", op)
+	}
+	return typedCondition(field, fieldType, op, raw, func(format string, args ...any) error {
+		p.pos = start
+		return p.errorf(format, args...)
+	})
+}
+
+func (p *filterParser) parseOp() (string, error) {
+	for _, op := range []string{FilterOpGte, FilterOpLte, FilterOpEq, FilterOpGt, FilterOpLt} {
+		if strings.HasPrefix(p.input[p.pos:], op) {
+			p.pos += len(op)
+			return op, nil
+		}
+	}
+	return "", p.errorf("expected one of : > >= < <=")
+}
+
+// parseValue reads a quoted value or a word. Unlike field names, unquoted values may contain
+// ':' and '=', so that timestamps can be written without quotes.
+func (p *filterParser) parseValue() (string, error) {
+	if !p.eof() && p.input[p.pos] == '"' {
+		var sb strings.Builder
+		p.pos++
+		for !p.eof() {
+			c := p.input[p.pos]
+			p.pos++
+			switch {
+			case c == '\\' && !p.eof():
+				sb.WriteByte(p.input[p.pos])
+				p.pos++
+			case c == '"':
+				return sb.String(), nil
+			default:
+				sb.WriteByte(c)
+			}
+		}
+		return "", p.errorf("unterminated string")
+	}
+	start := p.pos
+	for !p.eof() && strings.IndexByte(" \t\n()|,\"", p.input[p.pos]) < 0 {
+		p.pos++
+	}
+	if start == p.pos {
+		return "", p.errorf("expected value")
+	}
+	return p.input[start:p.pos], nil
+}
+
+const filterDateLayout = "2006-01-02"
+
+// typedCondition converts the values to the type of the field. Dates compared with ":" are
+// expanded to a range covering the day.
+func typedCondition(field string, fieldType FilterFieldType, op string, raw []string, errorf func(string, ...any) error) (FilterExpr, error) {
+	condition := FilterCondition{Field: field, Op: op}
+	var days []FilterExpr
+	for _, value := range raw {
+		switch fieldType {
+		case FilterString:
+			condition.Values = append(condition.Values, value)
+		case FilterBool:
+			b, err := strconv.ParseBool(value)
+			if err != nil {
+				return nil, errorf("%s expects true or false, got %q", field, value)
+			}
+			condition.Values = append(condition.Values, b)
+		case FilterTime:
+			if day, err := time.Parse(filterDateLayout, value); err == nil && op == FilterOpEq {
+				days = append(days, FilterAnd{Terms: []FilterExpr{
+					FilterCondition{Field: field, Op: FilterOpGte, Values: []any{day}},
+					FilterCondition{Field: field, Op: FilterOpLt, Values: []any{day.AddDate(0, 0, 1)}},
+				}})
+				continue
+			}
+			t, err := time.Parse(time.RFC3339Nano, value)
+			if err != nil {
+				t, err = time.Parse(filterDateLayout, value)
+			}
+			if err != nil {
+				return nil, errorf("%s expects a RFC 3339 timestamp or date, got %q", field, value)
+			}
+			condition.Values = append(condition.Values, t)
+		}
+	}
+	if len(days) == 0 {
+		return condition, nil
+	}
+	if len(condition.Values) > 0 {
+		days = append(days, condition)
+	}
+	if len(days) == 1 {
+		return days[0], nil
+	}
+	return FilterOr{Terms: days}, nil
+}
@@ -0,0 +1,94 @@
+/*
+ * Copyright 2025 InfAI (CC SES)
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *      http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package lib
+
+import (
+	"errors"
+	"reflect"
+	"testing"
+	"time"
+)
+
+func TestParseFilter(t *testing.T) {
+	day := time.Date(2025, 1, 2, 0, 0, 0, 0, time.UTC)
+	cases := []struct {
+		filter   string
+		expected FilterExpr
+	}{
+		{"", nil},
+		{
+			"operator:a,b|flow:f",
+			FilterAnd{Terms: []FilterExpr{
+				FilterCondition{Field: "operator", Op: FilterOpEq, Values: []any{"a", "b"}},
+				FilterCondition{Field: "flow", Op: FilterOpEq, Values: []any{"f"}},
+			}},
+		},
+		{
+			`(image:"my image" OR not metrics:true) and owner:u1`,
+			FilterAnd{Terms: []FilterExpr{
+				FilterOr{Terms: []FilterExpr{
+					FilterCondition{Field: "image", Op: FilterOpEq, Values: []any{"my image"}},
+					FilterNot{Term: FilterCondition{Field: "metrics", Op: FilterOpEq, Values: []any{true}}},
+				}},
+				FilterCondition{Field: "owner", Op: FilterOpEq, Values: []any{"u1"}},
+			}},
+		},
+		{
+			"createdAt>=2025-01-02T00:00:00Z",
+			FilterCondition{Field: "createdAt", Op: FilterOpGte, Values: []any{day}},
+		},
+		{
+			"updatedAt:2025-01-02",
+			FilterAnd{Terms: []FilterExpr{
+				FilterCondition{Field: "updatedAt", Op: FilterOpGte, Values: []any{day}},
+				FilterCondition{Field: "updatedAt", Op: FilterOpLt, Values: []any{day.AddDate(0, 0, 1)}},
+			}},
+		},
+	}
+	for _, c := range cases {
+		expr, err := ParseFilter(c.filter)
+		if err != nil {
+			t.Errorf("%q: unexpected error: %v", c.filter, err)
+			continue
+		}
+		if !reflect.DeepEqual(expr, c.expected) {
+			t.Errorf("%q: got %#v want %#v", c.filter, expr, c.expected)
+		}
+	}
+}
+
+func TestParseFilter_Errors(t *testing.T) {
+	for _, filter := range []string{
+		"unknown:x",
+		"operator",
+		"operator:",
+		"metrics:yes",
+		"image>a",
+		"createdAt>2025-01-01,2025-01-02",
+		"createdAt:yesterday",
+		"(operator:a",
+		"operator:a or",
+		`image:"open`,
+		"operator:a flow:b",
+	} {
+		_, err := ParseFilter(filter)
+		var ie *InputError
+		if !errors.As(err, &ie) {
+			t.Errorf("%q: expected input error, got %v", filter, err)
+		}
+	}
+}
@@ -0,0 +1,182 @@
+/*
+ * Copyright 2025 InfAI (CC SES)
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *      http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package lib
+
+import (
+	"slices"
+	"strings"
+)
+
+const (
+	FilterTypeDeviceId   = "DeviceId"
+	FilterTypeImportId   = "ImportId"
+	FilterTypeOperatorId = "OperatorId"
+)
+
+type PipelineGraph struct {
+	Nodes               []GraphNode  `json:"nodes"`
+	Edges               []GraphEdge  `json:"edges"`
+	Cycle               []string     `json:"cycle,omitempty"`
+	DanglingInputs      []GraphInput `json:"danglingInputs,omitempty"`
+	UnconsumedOperators []string     `json:"unconsumedOperators,omitempty"`
+}
+
+type GraphNode struct {
+	Id             string `json:"id"`
+	Name           string `json:"name,omitempty"`
+	OperatorId     string `json:"operatorId,omitempty"`
+	DeploymentType string `json:"deploymentType,omitempty"`
+	OutputTopic    string `json:"outputTopic,omitempty"`
+	PersistData    bool   `json:"persistData,omitempty"`
+	Upstream       bool   `json:"upstream,omitempty"`
+	Downstream     bool   `json:"downstream,omitempty"`
+}
+
+// GraphEdge connects the operator producing a topic with the operator consuming it.
+// InputIndex is the index of the consuming input topic in the InputTopics of To.
+type GraphEdge struct {
+	From        string    `json:"from"`
+	To          string    `json:"to"`
+	Topic       string    `json:"topic"`
+	InputIndex  int       `json:"inputIndex"`
+	FilterType  string    `json:"filterType,omitempty"`
+	FilterValue string    `json:"filterValue,omitempty"`
+	Mappings    []Mapping `json:"mappings,omitempty"`
+}
+
+// GraphInput references an input topic that is not produced inside the pipeline.
+type GraphInput struct {
+	OperatorIndex int    `json:"operatorIndex"`
+	InputIndex    int    `json:"inputIndex"`
+	OperatorId    string `json:"operatorId"`
+	Topic         string `json:"topic"`
+}
+
+// BuildGraph derives the operator graph of a pipeline. Operators are connected whenever the
+// OutputTopic of one operator is the name of an input topic of another. Input topics that are
+// not produced inside the pipeline and are not accepted by external are reported as dangling,
+// a nil external accepts every unresolved input topic.
+func BuildGraph(pipeline Pipeline, external func(topic InputTopic) bool) (graph PipelineGraph) {
+	graph.Nodes = make([]GraphNode, 0, len(pipeline.Operators))
+	graph.Edges = []GraphEdge{}
+	for _, operator := range pipeline.Operators {
+		graph.Nodes = append(graph.Nodes, GraphNode{
+			Id:             operator.Id,
+			Name:           operator.Name,
+			OperatorId:     operator.OperatorId,
+			DeploymentType: operator.DeploymentType,
+			OutputTopic:    operator.OutputTopic,
+			PersistData:    operator.PersistData,
+			Upstream:       operator.UpstreamConfig.Enabled,
+			Downstream:     operator.DownstreamConfig.Enabled,
+		})
+	}
+
+	consumed := map[string]bool{}
+	for i, operator := range pipeline.Operators {
+		for j, topic := range operator.InputTopics {
+			producers := producersOf(pipeline.Operators, topic)
+			if len(producers) == 0 {
+				if external != nil && !external(topic) {
+					graph.DanglingInputs = append(graph.DanglingInputs, GraphInput{
+						OperatorIndex: i,
+						InputIndex:    j,
+						OperatorId:    operator.Id,
+						Topic:         topic.Name,
+					})
+				}
+				continue
+			}
+			for _, producer := range producers {
+				consumed[producer.Id] = true
+				graph.Edges = append(graph.Edges, GraphEdge{
+					From:        producer.Id,
+					To:          operator.Id,
+					Topic:       topic.Name,
+					InputIndex:  j,
+					FilterType:  topic.FilterType,
+					FilterValue: topic.FilterValue,
+					Mappings:    topic.Mappings,
+				})
+			}
+		}
+	}
+
+	for _, operator := range pipeline.Operators {
+		if !consumed[operator.Id] && !operator.PersistData {
+			graph.UnconsumedOperators = append(graph.UnconsumedOperators, operator.Id)
+		}
+	}
+	graph.Cycle = findCycle(graph)
+	return
+}
+
+func producersOf(operators []Operator, topic InputTopic) (producers []Operator) {
+	for _, operator := range operators {
+		if operator.OutputTopic == "" || operator.OutputTopic != topic.Name {
+			continue
+		}
+		if topic.FilterType == FilterTypeOperatorId && topic.FilterValue != "" &&
+			!slices.Contains(strings.Split(topic.FilterValue, ":"), operator.Id) {
+			continue
+		}
+		producers = append(producers, operator)
+	}
+	return
+}
+
+// findCycle returns the operator ids of the first cycle found, with the first id repeated at the end.
+func findCycle(graph PipelineGraph) []string {
+	adjacency := map[string][]string{}
+	for _, edge := range graph.Edges {
+		adjacency[edge.From] = append(adjacency[edge.From], edge.To)
+	}
+	const (
+		unvisited = iota
+		visiting
+		done
+	)
+	state := map[string]int{}
+	var stack []string
+	var visit func(id string) []string
+	visit = func(id string) []string {
+		state[id] = visiting
+		stack = append(stack, id)
+		for _, next := range adjacency[id] {
+			switch state[next] {
+			case visiting:
+				start := slices.Index(stack, next)
+				return append(slices.Clone(stack[start:]), next)
+			case unvisited:
+				if cycle := visit(next); cycle != nil {
+					return cycle
+				}
+			}
+		}
+		stack = stack[:len(stack)-1]
+		state[id] = done
+		return nil
+	}
+	for _, node := range graph.Nodes {
+		if state[node.Id] == unvisited {
+			if cycle := visit(node.Id); cycle != nil {
+				return cycle
+			}
+		}
+	}
+	return nil
+}
@@ -0,0 +1,83 @@
+/*
+ * Copyright 2025 InfAI (CC SES)
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *      http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package lib
+
+import (
+	"slices"
+	"testing"
+)
+
+func graphTestOperator(id string, output string, inputs ...string) Operator {
+	operator := Operator{Id: id, OperatorId: id, OutputTopic: output}
+	for _, input := range inputs {
+		operator.InputTopics = append(operator.InputTopics, InputTopic{Name: input})
+	}
+	return operator
+}
+
+func TestBuildGraph(t *testing.T) {
+	pipeline := Pipeline{Operators: []Operator{
+		graphTestOperator("a", "topic-a", "device"),
+		graphTestOperator("b", "topic-b", "topic-a", "unknown"),
+		graphTestOperator("c", "topic-c", "topic-a"),
+	}}
+	pipeline.Operators[2].PersistData = true
+	graph := BuildGraph(pipeline, func(topic InputTopic) bool {
+		return topic.Name == "device"
+	})
+	if len(graph.Nodes) != 3 {
+		t.Errorf("unexpected node count: got %d want 3", len(graph.Nodes))
+	}
+	if len(graph.Edges) != 2 || graph.Edges[0].From != "a" || graph.Edges[0].To != "b" || graph.Edges[1].To != "c" {
+		t.Errorf("unexpected edges: %+v", graph.Edges)
+	}
+	if len(graph.DanglingInputs) != 1 || graph.DanglingInputs[0].OperatorIndex != 1 || graph.DanglingInputs[0].InputIndex != 1 {
+		t.Errorf("unexpected dangling inputs: %+v", graph.DanglingInputs)
+	}
+	if !slices.Equal(graph.UnconsumedOperators, []string{"b"}) {
+		t.Errorf("unexpected unconsumed operators: %v", graph.UnconsumedOperators)
+	}
+	if graph.Cycle != nil {
+		t.Errorf("unexpected cycle: %v", graph.Cycle)
+	}
+}
+
+func TestBuildGraph_Cycle(t *testing.T) {
+	pipeline := Pipeline{Operators: []Operator{
+		graphTestOperator("a", "topic-a", "topic-c"),
+		graphTestOperator("b", "topic-b", "topic-a"),
+		graphTestOperator("c", "topic-c", "topic-b"),
+	}}
+	graph := BuildGraph(pipeline, nil)
+	if !slices.Equal(graph.Cycle, []string{"a", "b", "c", "a"}) {
+		t.Errorf("unexpected cycle: %v", graph.Cycle)
+	}
+}
+
+func TestBuildGraph_OperatorIdFilter(t *testing.T) {
+	pipeline := Pipeline{Operators: []Operator{
+		graphTestOperator("a", "analytics-adder"),
+		graphTestOperator("b", "analytics-adder"),
+		graphTestOperator("c", "result", "analytics-adder"),
+	}}
+	pipeline.Operators[2].InputTopics[0].FilterType = FilterTypeOperatorId
+	pipeline.Operators[2].InputTopics[0].FilterValue = "b:pipeline"
+	graph := BuildGraph(pipeline, nil)
+	if len(graph.Edges) != 1 || graph.Edges[0].From != "b" {
+		t.Errorf("unexpected edges: %+v", graph.Edges)
+	}
+}
@@ -0,0 +1,127 @@
+/*
+ * Copyright 2025 InfAI (CC SES)
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *      http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package lib
+
+const (
+	LineageUpstream   = "up"
+	LineageDownstream = "down"
+)
+
+// PipelineLineage is the part of the cross-pipeline graph reachable from Root. Nodes are listed
+// in breadth-first order with their distance to the root, the root itself has depth 0.
+type PipelineLineage struct {
+	Root      string        `json:"root"`
+	Direction string        `json:"direction"`
+	Nodes     []LineageNode `json:"nodes"`
+	Edges     []LineageEdge `json:"edges"`
+	Truncated bool          `json:"truncated,omitempty"`
+}
+
+type LineageNode struct {
+	PipelineId string `json:"pipelineId"`
+	Name       string `json:"name,omitempty"`
+	Depth      int    `json:"depth"`
+}
+
+// LineageEdge connects the operator producing a topic with the operator of another pipeline
+// consuming it. Edges always point in data flow direction, independent of the walked direction.
+type LineageEdge struct {
+	FromPipeline string `json:"fromPipeline"`
+	FromOperator string `json:"fromOperator"`
+	ToPipeline   string `json:"toPipeline"`
+	ToOperator   string `json:"toOperator"`
+	Topic        string `json:"topic"`
+}
+
+// BuildLineage walks the topic links between pipelines starting at the pipeline with id root.
+// Pipelines are linked whenever the OutputTopic of an operator of one pipeline is the name of
+// an input topic of an operator of another pipeline. A depth of 0 walks the whole graph,
+// otherwise Truncated reports whether further pipelines were cut off.
+func BuildLineage(root string, pipelines []Pipeline, direction string, depth int) (lineage PipelineLineage) {
+	lineage.Root = root
+	lineage.Direction = direction
+	lineage.Nodes = []LineageNode{}
+	lineage.Edges = []LineageEdge{}
+	byId := map[string]Pipeline{}
+	for _, pipeline := range pipelines {
+		byId[pipeline.Id] = pipeline
+	}
+	start, ok := byId[root]
+	if !ok {
+		return
+	}
+	lineage.Nodes = append(lineage.Nodes, LineageNode{PipelineId: root, Name: start.Name})
+	visited := map[string]bool{root: true}
+	for i := 0; i < len(lineage.Nodes); i++ {
+		node := lineage.Nodes[i]
+		edges := linkedPipelines(byId[node.PipelineId], pipelines, direction)
+		if len(edges) > 0 && depth > 0 && node.Depth == depth {
+			lineage.Truncated = true
+			continue
+		}
+		for _, edge := range edges {
+			lineage.Edges = append(lineage.Edges, edge)
+			next := edge.ToPipeline
+			if direction == LineageUpstream {
+				next = edge.FromPipeline
+			}
+			if !visited[next] {
+				visited[next] = true
+				lineage.Nodes = append(lineage.Nodes, LineageNode{PipelineId: next, Name: byId[next].Name, Depth: node.Depth + 1})
+			}
+		}
+	}
+	return
+}
+
+// DownstreamPipelines returns the ids of the pipelines directly consuming an output topic of pipeline.
+func DownstreamPipelines(pipeline Pipeline, pipelines []Pipeline) (ids []string) {
+	seen := map[string]bool{}
+	for _, edge := range linkedPipelines(pipeline, pipelines, LineageDownstream) {
+		if !seen[edge.ToPipeline] {
+			seen[edge.ToPipeline] = true
+			ids = append(ids, edge.ToPipeline)
+		}
+	}
+	return
+}
+
+func linkedPipelines(pipeline Pipeline, pipelines []Pipeline, direction string) (edges []LineageEdge) {
+	for _, other := range pipelines {
+		if other.Id == pipeline.Id {
+			continue
+		}
+		producer, consumer := pipeline, other
+		if direction == LineageUpstream {
+			producer, consumer = other, pipeline
+		}
+		for _, operator := range consumer.Operators {
+			for _, topic := range operator.InputTopics {
+				for _, source := range producersOf(producer.Operators, topic) {
+					edges = append(edges, LineageEdge{
+						FromPipeline: producer.Id,
+						FromOperator: source.Id,
+						ToPipeline:   consumer.Id,
+						ToOperator:   operator.Id,
+						Topic:        topic.Name,
+					})
+				}
+			}
+		}
+	}
+	return
+}
@@ -0,0 +1,65 @@
+/*
+ * Copyright 2025 InfAI (CC SES)
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *      http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package lib
+
+import (
+	"slices"
+	"testing"
+)
+
+func lineageTestPipelines() []Pipeline {
+	// a -> b -> c, a -> c, d -> a
+	return []Pipeline{
+		{Id: "a", Operators: []Operator{graphTestOperator("a1", "topic-a", "topic-d")}},
+		{Id: "b", Operators: []Operator{graphTestOperator("b1", "topic-b", "topic-a")}},
+		{Id: "c", Operators: []Operator{graphTestOperator("c1", "", "topic-b", "topic-a")}},
+		{Id: "d", Operators: []Operator{graphTestOperator("d1", "topic-d", "device")}},
+	}
+}
+
+func lineageNodeIds(lineage PipelineLineage) (ids []string) {
+	for _, node := range lineage.Nodes {
+		ids = append(ids, node.PipelineId)
+	}
+	return
+}
+
+func TestBuildLineage(t *testing.T) {
+	pipelines := lineageTestPipelines()
+
+	down := BuildLineage("a", pipelines, LineageDownstream, 0)
+	if !slices.Equal(lineageNodeIds(down), []string{"a", "b", "c"}) || len(down.Edges) != 3 || down.Truncated {
+		t.Errorf("unexpected downstream lineage: %+v", down)
+	}
+	if down.Nodes[2].Depth != 1 {
+		t.Errorf("unexpected depth of c: %d", down.Nodes[2].Depth)
+	}
+
+	up := BuildLineage("c", pipelines, LineageUpstream, 1)
+	if !slices.Equal(lineageNodeIds(up), []string{"c", "a", "b"}) || !up.Truncated {
+		t.Errorf("unexpected upstream lineage: %+v", up)
+	}
+	for _, edge := range up.Edges {
+		if edge.ToPipeline != "c" {
+			t.Errorf("edges must point in data flow direction: %+v", edge)
+		}
+	}
+
+	if ids := DownstreamPipelines(pipelines[3], pipelines); !slices.Equal(ids, []string{"a"}) {
+		t.Errorf("unexpected downstream pipelines: %v", ids)
+	}
+}
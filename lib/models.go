@@ -22,25 +22,66 @@ import (
 	"github.com/google/uuid"
 )
 
+// PipelinesResponse is a page of pipelines. NextCursor is set if the page is full and can be
+// passed as cursor parameter to retrieve the following page. Highlights lists the fields
+// matching a search per pipeline id.
 type PipelinesResponse struct {
-	Data  []Pipeline `json:"data"`
-	Total int64      `json:"total"`
+	Data       []Pipeline                   `json:"data"`
+	Total      int64                        `json:"total"`
+	NextCursor string                       `json:"nextCursor,omitempty"`
+	Highlights map[string][]SearchHighlight `json:"highlights,omitempty"`
 }
 
 type Pipeline struct {
-	Id                 string     `bson:"id" json:"id"`
-	Name               string     `json:"name,omitempty"`
-	Description        string     `json:"description,omitempty"`
-	FlowId             string     `json:"flowId,omitempty"`
-	Image              string     `json:"image,omitempty"`
-	WindowTime         int        `json:"windowTime,omitempty"`
-	MergeStrategy      string     `json:"mergeStrategy,omitempty"`
-	ConsumeAllMessages bool       `json:"consumeAllMessages,omitempty"`
-	Metrics            bool       `json:"metrics,omitempty"`
-	CreatedAt          time.Time  `json:"createdAt,omitempty"`
-	UpdatedAt          time.Time  `json:"updatedAt,omitempty"`
-	UserId             string     `json:"userId,omitempty"`
-	Operators          []Operator `json:"operators,omitempty"`
+	Id                 string             `bson:"id" json:"id"`
+	Name               string             `json:"name,omitempty"`
+	Description        string             `json:"description,omitempty"`
+	FlowId             string             `json:"flowId,omitempty"`
+	Image              string             `json:"image,omitempty"`
+	WindowTime         int                `json:"windowTime,omitempty"`
+	MergeStrategy      string             `json:"mergeStrategy,omitempty"`
+	ConsumeAllMessages bool               `json:"consumeAllMessages,omitempty"`
+	Metrics            bool               `json:"metrics,omitempty"`
+	CreatedAt          time.Time          `json:"createdAt,omitempty"`
+	UpdatedAt          time.Time          `json:"updatedAt,omitempty"`
+	UserId             string             `json:"userId,omitempty"`
+	Version            int                `json:"version,omitempty"`
+	DeletedAt          *time.Time         `json:"deletedAt,omitempty"`
+	Status             string             `json:"status,omitempty"`
+	StatusTransitions  []StatusTransition `json:"statusTransitions,omitempty"`
+	Template           *TemplateReference `json:"template,omitempty"`
+	Operators          []Operator         `json:"operators,omitempty"`
+}
+
+const (
+	PipelineStatusDraft     = "draft"
+	PipelineStatusDeploying = "deploying"
+	PipelineStatusRunning   = "running"
+	PipelineStatusStopped   = "stopped"
+	PipelineStatusFailed    = "failed"
+	PipelineStatusDeleting  = "deleting"
+)
+
+type StatusTransition struct {
+	From      string    `json:"from,omitempty"`
+	To        string    `json:"to"`
+	Reason    string    `json:"reason,omitempty"`
+	UserId    string    `json:"userId,omitempty"`
+	Timestamp time.Time `json:"timestamp"`
+}
+
+type StatusUpdate struct {
+	Status string `json:"status"`
+	Reason string `json:"reason,omitempty"`
+}
+
+// PipelineCloneOptions controls the copy of a pipeline. Without name, the clone is named after the
+// source with a " (copy)" suffix. CopyPermissions copies the sharing of the source, which requires
+// the administrate permission. Clones start as draft unless Draft is false, then they start stopped.
+type PipelineCloneOptions struct {
+	Name            string `json:"name,omitempty"`
+	CopyPermissions bool   `json:"copyPermissions,omitempty"`
+	Draft           *bool  `json:"draft,omitempty"`
 }
 
 type UpstreamConfig struct {
@@ -111,3 +152,94 @@ type FlowUsage struct {
 	Count       int32    `json:"count,omitempty" bson:"count"`
 	PipelineIds []string `json:"pipelineIds,omitempty" bson:"pipelineIds"`
 }
+
+// OperatorReference identifies an operator by its pipeline and its id inside the pipeline.
+type OperatorReference struct {
+	PipelineId string `json:"pipelineId" bson:"pipelineId"`
+	OperatorId string `json:"operatorId" bson:"operatorId"`
+}
+
+type TopicUsage struct {
+	Topic       string              `json:"topic,omitempty"`
+	Count       int32               `json:"count,omitempty"`
+	PipelineIds []string            `json:"pipelineIds,omitempty"`
+	Operators   []OperatorReference `json:"operators,omitempty"`
+}
+
+// SelectableUsage lists the operators referencing a device or device group, either as
+// selectable of an input selection or as DeviceId filter of an input topic.
+type SelectableUsage struct {
+	SelectableId string              `json:"selectableId,omitempty"`
+	Count        int32               `json:"count,omitempty"`
+	PipelineIds  []string            `json:"pipelineIds,omitempty"`
+	Operators    []OperatorReference `json:"operators,omitempty"`
+}
+
+// PipelineRevision is an immutable snapshot of a pipeline, stored on every save, update and rollback.
+// Revision equals the version of the pipeline snapshot. Pipeline is omitted when listing revisions.
+type PipelineRevision struct {
+	PipelineId string    `json:"pipelineId"`
+	Revision   int       `json:"revision"`
+	UserId     string    `json:"userId,omitempty"`
+	CreatedAt  time.Time `json:"createdAt"`
+	RollbackOf int       `json:"rollbackOf,omitempty"`
+	Pipeline   *Pipeline `json:"pipeline,omitempty"`
+}
+
+const (
+	PipelineEventCreated = "created"
+	PipelineEventUpdated = "updated"
+	PipelineEventDeleted = "deleted"
+)
+
+// PipelineEvent notifies other services about a changed pipeline. Revision is the version of the
+// pipeline after the change, Pipeline the full document.
+type PipelineEvent struct {
+	Id         string    `json:"id"`
+	Type       string    `json:"type"`
+	PipelineId string    `json:"pipelineId"`
+	UserId     string    `json:"userId,omitempty"`
+	Revision   int       `json:"revision"`
+	Timestamp  time.Time `json:"timestamp"`
+	Pipeline   *Pipeline `json:"pipeline,omitempty"`
+}
+
+// WebhookSubscription registers a URL that receives pipeline events of the given types. Empty
+// EventTypes subscribe to all types, an empty PipelineId to all pipelines readable by the user.
+// The secret is used to sign deliveries and is never returned.
+type WebhookSubscription struct {
+	Id         string    `json:"id"`
+	UserId     string    `json:"userId,omitempty"`
+	Url        string    `json:"url"`
+	Secret     string    `json:"secret,omitempty"`
+	EventTypes []string  `json:"eventTypes,omitempty"`
+	PipelineId string    `json:"pipelineId,omitempty"`
+	CreatedAt  time.Time `json:"createdAt"`
+	// Roles and Groups of the subscriber when subscribing, used to check group and role permissions.
+	Roles  []string `json:"-"`
+	Groups []string `json:"-"`
+}
+
+// WebhookDelivery logs a single delivery attempt.
+type WebhookDelivery struct {
+	Id             string    `json:"id"`
+	SubscriptionId string    `json:"subscriptionId"`
+	EventId        string    `json:"eventId"`
+	EventType      string    `json:"eventType"`
+	PipelineId     string    `json:"pipelineId"`
+	Attempt        int       `json:"attempt"`
+	StatusCode     int       `json:"statusCode,omitempty"`
+	Error          string    `json:"error,omitempty"`
+	Success        bool      `json:"success"`
+	Timestamp      time.Time `json:"timestamp"`
+}
+
+// WebhookDeadLetter keeps an event that could not be delivered after all attempts.
+type WebhookDeadLetter struct {
+	Id             string        `json:"id"`
+	SubscriptionId string        `json:"subscriptionId"`
+	Event          PipelineEvent `json:"event"`
+	Attempts       int           `json:"attempts"`
+	LastError      string        `json:"lastError,omitempty"`
+	CreatedAt      time.Time     `json:"createdAt"`
+}
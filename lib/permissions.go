@@ -0,0 +1,31 @@
+/*
+ * Copyright 2025 InfAI (CC SES)
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *      http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package lib
+
+// PipelinePermissions are the permissions granted on a pipeline per user, group and role id.
+type PipelinePermissions struct {
+	UserPermissions  map[string]PermissionsMap `json:"userPermissions"`
+	GroupPermissions map[string]PermissionsMap `json:"groupPermissions"`
+	RolePermissions  map[string]PermissionsMap `json:"rolePermissions"`
+}
+
+type PermissionsMap struct {
+	Read         bool `json:"read"`
+	Write        bool `json:"write"`
+	Execute      bool `json:"execute"`
+	Administrate bool `json:"administrate"`
+}
@@ -0,0 +1,49 @@
+/*
+ * Copyright 2025 InfAI (CC SES)
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *      http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package lib
+
+import "time"
+
+// ReconcileReport lists the permissions-v2 resources a reconciliation changed, or would change
+// in a dry run. Created resources belong to pipelines without permissions, repaired ones lacked
+// the permissions of the pipeline owner and deleted ones had no pipeline. Failed lists the ids
+// whose change failed, the reconciliation continues with the remaining ones.
+type ReconcileReport struct {
+	DryRun     bool      `json:"dryRun"`
+	StartedAt  time.Time `json:"startedAt"`
+	DurationMs int64     `json:"durationMs"`
+	Pipelines  int       `json:"pipelines"`
+	Resources  int       `json:"resources"`
+	Created    []string  `json:"created"`
+	Repaired   []string  `json:"repaired"`
+	Deleted    []string  `json:"deleted"`
+	Failed     []string  `json:"failed"`
+}
+
+// ReconcileMetrics sums up the reconciliations since the service started. Dry runs are only
+// counted, their changes are not added to the totals.
+type ReconcileMetrics struct {
+	Runs       int              `json:"runs"`
+	DryRuns    int              `json:"dryRuns"`
+	FailedRuns int              `json:"failedRuns"`
+	Created    int              `json:"created"`
+	Repaired   int              `json:"repaired"`
+	Deleted    int              `json:"deleted"`
+	Failed     int              `json:"failed"`
+	LastRun    *ReconcileReport `json:"lastRun,omitempty"`
+	LastError  string           `json:"lastError,omitempty"`
+}
@@ -0,0 +1,200 @@
+/*
+ * Copyright 2025 InfAI (CC SES)
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *      http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package lib
+
+import (
+	"fmt"
+	"strings"
+)
+
+const (
+	ExportFormatDOT     = "dot"
+	ExportFormatMermaid = "mermaid"
+)
+
+// renderGraph is the renderer independent view of a pipeline. Operators are numbered by their
+// index, input topics not produced inside the pipeline become source nodes.
+type renderGraph struct {
+	title     string
+	operators []renderNode
+	sources   []string
+	edges     []renderEdge
+}
+
+type renderNode struct {
+	lines      []string
+	upstream   bool
+	downstream bool
+	persist    bool
+}
+
+// renderEdge connects two nodes, from is an operator index or, with fromSource, a source index.
+type renderEdge struct {
+	from       int
+	fromSource bool
+	to         int
+	lines      []string
+}
+
+func newRenderGraph(pipeline Pipeline) (graph renderGraph) {
+	graph.title = pipeline.Name
+	index := map[string]int{}
+	for i, operator := range pipeline.Operators {
+		index[operator.Id] = i
+		node := renderNode{
+			lines:      []string{operator.Name},
+			upstream:   operator.UpstreamConfig.Enabled,
+			downstream: operator.DownstreamConfig.Enabled,
+			persist:    operator.PersistData,
+		}
+		if operator.Name == "" {
+			node.lines[0] = operator.Id
+		}
+		if operator.OperatorId != "" && operator.OperatorId != node.lines[0] {
+			node.lines = append(node.lines, operator.OperatorId)
+		}
+		var marks []string
+		if node.upstream {
+			marks = append(marks, "upstream")
+		}
+		if node.downstream {
+			marks = append(marks, "downstream")
+		}
+		if node.persist {
+			marks = append(marks, "persisted")
+		}
+		if len(marks) > 0 {
+			node.lines = append(node.lines, "("+strings.Join(marks, ", ")+")")
+		}
+		graph.operators = append(graph.operators, node)
+	}
+	sources := map[string]int{}
+	for i, operator := range pipeline.Operators {
+		for _, topic := range operator.InputTopics {
+			lines := edgeLines(topic)
+			producers := producersOf(pipeline.Operators, topic)
+			for _, producer := range producers {
+				graph.edges = append(graph.edges, renderEdge{from: index[producer.Id], to: i, lines: lines})
+			}
+			if len(producers) > 0 {
+				continue
+			}
+			source, ok := sources[topic.Name]
+			if !ok {
+				source = len(graph.sources)
+				sources[topic.Name] = source
+				graph.sources = append(graph.sources, topic.Name)
+			}
+			graph.edges = append(graph.edges, renderEdge{from: source, fromSource: true, to: i, lines: lines})
+		}
+	}
+	return
+}
+
+// edgeLines labels an edge with the topic, its filter and its mappings.
+func edgeLines(topic InputTopic) []string {
+	lines := []string{topic.Name}
+	if topic.FilterType != "" {
+		lines = append(lines, topic.FilterType+"="+topic.FilterValue)
+	}
+	for _, mapping := range topic.Mappings {
+		lines = append(lines, mapping.Source+" → "+mapping.Dest)
+	}
+	return lines
+}
+
+// RenderDOT renders the operators of a pipeline as Graphviz DOT graph. Operators are boxes,
+// topics are edges labelled with filter and mappings. Fog operators have dashed borders,
+// operators persisting their data double borders.
+func RenderDOT(pipeline Pipeline) string {
+	graph := newRenderGraph(pipeline)
+	var sb strings.Builder
+	sb.WriteString("digraph " + dotQuote(graph.title) + " {\n")
+	sb.WriteString("  rankdir=LR;\n")
+	sb.WriteString("  node [shape=box, style=rounded];\n")
+	for i, source := range graph.sources {
+		sb.WriteString(fmt.Sprintf("  s%d [label=%s, shape=ellipse, style=solid];\n", i, dotQuote(source)))
+	}
+	for i, node := range graph.operators {
+		attributes := []string{"label=" + dotQuote(strings.Join(node.lines, "\n"))}
+		if node.upstream || node.downstream {
+			attributes = append(attributes, `style="rounded,dashed"`)
+		}
+		if node.persist {
+			attributes = append(attributes, "peripheries=2")
+		}
+		sb.WriteString(fmt.Sprintf("  o%d [%s];\n", i, strings.Join(attributes, ", ")))
+	}
+	for _, edge := range graph.edges {
+		from := fmt.Sprintf("o%d", edge.from)
+		if edge.fromSource {
+			from = fmt.Sprintf("s%d", edge.from)
+		}
+		sb.WriteString(fmt.Sprintf("  %s -> o%d [label=%s];\n", from, edge.to, dotQuote(strings.Join(edge.lines, "\n"))))
+	}
+	sb.WriteString("}\n")
+	return sb.String()
+}
+
+func dotQuote(value string) string {
+	value = strings.ReplaceAll(value, `\`, `\\`)
+	value = strings.ReplaceAll(value, `"`, `\"`)
+	value = strings.ReplaceAll(value, "\n", `\n`)
+	return `"` + value + `"`
+}
+
+// RenderMermaid renders the operators of a pipeline as Mermaid flowchart, marked like RenderDOT.
+func RenderMermaid(pipeline Pipeline) string {
+	graph := newRenderGraph(pipeline)
+	var sb strings.Builder
+	if graph.title != "" {
+		sb.WriteString("---\ntitle: " + mermaidQuote(graph.title) + "\n---\n")
+	}
+	sb.WriteString("flowchart LR\n")
+	sb.WriteString("  classDef fog stroke-dasharray: 5 5\n")
+	sb.WriteString("  classDef persisted stroke-width: 4px\n")
+	for i, source := range graph.sources {
+		sb.WriteString(fmt.Sprintf("  s%d([%s])\n", i, mermaidQuote(source)))
+	}
+	for i, node := range graph.operators {
+		sb.WriteString(fmt.Sprintf("  o%d[%s]\n", i, mermaidQuote(strings.Join(node.lines, "\n"))))
+		var classes []string
+		if node.upstream || node.downstream {
+			classes = append(classes, "fog")
+		}
+		if node.persist {
+			classes = append(classes, "persisted")
+		}
+		if len(classes) > 0 {
+			sb.WriteString(fmt.Sprintf("  class o%d %s\n", i, strings.Join(classes, ",")))
+		}
+	}
+	for _, edge := range graph.edges {
+		from := fmt.Sprintf("o%d", edge.from)
+		if edge.fromSource {
+			from = fmt.Sprintf("s%d", edge.from)
+		}
+		sb.WriteString(fmt.Sprintf("  %s -->|%s| o%d\n", from, mermaidQuote(strings.Join(edge.lines, "\n")), edge.to))
+	}
+	return sb.String()
+}
+
+func mermaidQuote(value string) string {
+	value = strings.ReplaceAll(value, `"`, "#quot;")
+	value = strings.ReplaceAll(value, "\n", "<br/>")
+	return `"` + value + `"`
+}
@@ -0,0 +1,66 @@
+/*
+ * Copyright 2025 InfAI (CC SES)
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *      http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package lib
+
+import (
+	"strings"
+	"testing"
+)
+
+func renderTestPipeline() Pipeline {
+	return Pipeline{Name: `demo "1"`, Operators: []Operator{
+		{Id: "a", Name: "adder", OperatorId: "adder-op", OutputTopic: "topic-a", UpstreamConfig: UpstreamConfig{Enabled: true}, InputTopics: []InputTopic{
+			{Name: "device", FilterType: FilterTypeDeviceId, FilterValue: "d1", Mappings: []Mapping{{Dest: "value", Source: "value.root"}}},
+		}},
+		{Id: "b", Name: "sum", OperatorId: "sum", PersistData: true, InputTopics: []InputTopic{
+			{Name: "topic-a", FilterType: FilterTypeOperatorId, FilterValue: "a:p1", Mappings: []Mapping{{Dest: "v", Source: "sum"}}},
+		}},
+	}}
+}
+
+func TestRenderDOT(t *testing.T) {
+	dot := RenderDOT(renderTestPipeline())
+	for _, expected := range []string{
+		`digraph "demo \"1\"" {`,
+		`s0 [label="device", shape=ellipse, style=solid];`,
+		`o0 [label="adder\nadder-op\n(upstream)", style="rounded,dashed"];`,
+		`o1 [label="sum\n(persisted)", peripheries=2];`,
+		`s0 -> o0 [label="device\nDeviceId=d1\nvalue.root → value"];`,
+		`o0 -> o1 [label="topic-a\nOperatorId=a:p1\nsum → v"];`,
+	} {
+		if !strings.Contains(dot, expected) {
+			t.Errorf("missing %s in:\n%s", expected, dot)
+		}
+	}
+}
+
+func TestRenderMermaid(t *testing.T) {
+	mermaid := RenderMermaid(renderTestPipeline())
+	for _, expected := range []string{
+		`title: "demo #quot;1#quot;"`,
+		"flowchart LR",
+		`s0(["device"])`,
+		`o0["adder<br/>adder-op<br/>(upstream)"]`,
+		"class o0 fog",
+		"class o1 persisted",
+		`o0 -->|"topic-a<br/>OperatorId=a:p1<br/>sum → v"| o1`,
+	} {
+		if !strings.Contains(mermaid, expected) {
+			t.Errorf("missing %s in:\n%s", expected, mermaid)
+		}
+	}
+}
@@ -0,0 +1,119 @@
+/*
+ * Copyright 2025 InfAI (CC SES)
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *      http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package lib
+
+import (
+	"fmt"
+	"html"
+	"slices"
+	"strings"
+)
+
+// SearchHighlight is a field of a pipeline that matched the search. Value is the HTML escaped
+// field value with every match wrapped in <em></em>.
+type SearchHighlight struct {
+	Field string `json:"field"`
+	Value string `json:"value"`
+}
+
+// SearchTerms splits a search into plain terms. Quotes and leading dashes are removed, so that
+// user input is never interpreted as phrase or negation by the text search.
+func SearchTerms(search string) (terms []string) {
+	for _, word := range strings.Fields(search) {
+		word = strings.TrimLeft(strings.ReplaceAll(word, "\"", ""), "-")
+		if word != "" {
+			terms = append(terms, word)
+		}
+	}
+	return
+}
+
+// HighlightPipeline returns the searchable fields of the pipeline that contain any of the terms,
+// ignoring case. Searchable are the name, the description and the name, config values and topic names
+// of operators.
+func HighlightPipeline(pipeline Pipeline, terms []string) (highlights []SearchHighlight) {
+	add := func(field string, value string) {
+		if highlighted, ok := highlight(value, terms); ok {
+			highlights = append(highlights, SearchHighlight{Field: field, Value: highlighted})
+		}
+	}
+	add("name", pipeline.Name)
+	add("description", pipeline.Description)
+	for i, operator := range pipeline.Operators {
+		add(fmt.Sprintf("operators[%d].name", i), operator.Name)
+		keys := make([]string, 0, len(operator.Config))
+		for key := range operator.Config {
+			keys = append(keys, key)
+		}
+		slices.Sort(keys)
+		for _, key := range keys {
+			add(fmt.Sprintf("operators[%d].config.%s", i, key), operator.Config[key])
+		}
+		for j, topic := range operator.InputTopics {
+			add(fmt.Sprintf("operators[%d].inputTopics[%d].name", i, j), topic.Name)
+		}
+		add(fmt.Sprintf("operators[%d].outputTopic", i), operator.OutputTopic)
+	}
+	return
+}
+
+func highlight(value string, terms []string) (string, bool) {
+	lower := strings.ToLower(value)
+	if len(lower) != len(value) {
+		// lower casing changed byte offsets, report the match without marking it
+		for _, term := range terms {
+			if strings.Contains(lower, strings.ToLower(term)) {
+				return html.EscapeString(value), true
+			}
+		}
+		return "", false
+	}
+	// marks[i] is true if byte i of value is part of a match
+	marks := make([]bool, len(value))
+	found := false
+	for _, term := range terms {
+		term = strings.ToLower(term)
+		for offset := 0; term != ""; {
+			i := strings.Index(lower[offset:], term)
+			if i < 0 {
+				break
+			}
+			for j := offset + i; j < offset+i+len(term); j++ {
+				marks[j] = true
+			}
+			found = true
+			offset += i + len(term)
+		}
+	}
+	if !found {
+		return "", false
+	}
+	var sb strings.Builder
+	for i := 0; i < len(value); {
+		j := i
+		for j < len(value) && marks[j] == marks[i] {
+			j++
+		}
+		if marks[i] {
+			sb.WriteString("<em>" + html.EscapeString(value[i:j]) + "</em>")
+		} else {
+			sb.WriteString(html.EscapeString(value[i:j]))
+		}
+		i = j
+	}
+	return sb.String(), true
+}
@@ -0,0 +1,55 @@
+/*
+ * Copyright 2025 InfAI (CC SES)
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *      http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package lib
+
+import (
+	"reflect"
+	"slices"
+	"testing"
+)
+
+func TestSearchTerms(t *testing.T) {
+	terms := SearchTerms(` -energy "peak load"  .*( `)
+	if !slices.Equal(terms, []string{"energy", "peak", "load", ".*("}) {
+		t.Errorf("unexpected terms: %q", terms)
+	}
+}
+
+func TestHighlightPipeline(t *testing.T) {
+	pipeline := Pipeline{
+		Name:        "Energy <Peak>",
+		Description: "nothing to see",
+		Operators: []Operator{
+			{Name: "adder", Config: map[string]string{"unit": "kWh", "topic": "peak-energy"}},
+			{
+				Name:        "sum",
+				InputTopics: []InputTopic{{Name: "device-topic"}, {Name: "energy-meter"}},
+				OutputTopic: "analytics-energy-sum",
+			},
+		},
+	}
+	highlights := HighlightPipeline(pipeline, []string{"peak", "ENERGY"})
+	expected := []SearchHighlight{
+		{Field: "name", Value: "<em>Energy</em> &lt;<em>Peak</em>&gt;"},
+		{Field: "operators[0].config.topic", Value: "<em>peak</em>-<em>energy</em>"},
+		{Field: "operators[1].inputTopics[1].name", Value: "<em>energy</em>-meter"},
+		{Field: "operators[1].outputTopic", Value: "analytics-<em>energy</em>-sum"},
+	}
+	if !reflect.DeepEqual(highlights, expected) {
+		t.Errorf("unexpected highlights: got %+v want %+v", highlights, expected)
+	}
+}
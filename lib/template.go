@@ -0,0 +1,244 @@
+/*
+ * Copyright 2025 InfAI (CC SES)
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *      http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package lib
+
+import (
+	"fmt"
+	"math"
+	"regexp"
+	"slices"
+	"strconv"
+	"strings"
+	"time"
+)
+
+const (
+	TemplateParamString  = "string"
+	TemplateParamNumber  = "number"
+	TemplateParamInteger = "integer"
+	TemplateParamBoolean = "boolean"
+)
+
+var TemplateParamTypes = []string{TemplateParamString, TemplateParamNumber, TemplateParamInteger, TemplateParamBoolean}
+
+// PipelineTemplate is a pipeline skeleton with ${param} placeholders in the config, input topics
+// and input selections of its operators. Every placeholder has to be declared in Params, a
+// literal ${name} is written as $${name}. Like in bundles, topic names and filter values may
+// reference the id of the created pipeline as {pipeline}. Version is incremented on every update.
+type PipelineTemplate struct {
+	Id          string          `json:"id"`
+	UserId      string          `json:"userId,omitempty"`
+	Name        string          `json:"name"`
+	Description string          `json:"description,omitempty"`
+	Version     int             `json:"version,omitempty"`
+	Params      []TemplateParam `json:"params,omitempty"`
+	Pipeline    Pipeline        `json:"pipeline"`
+	CreatedAt   time.Time       `json:"createdAt"`
+	UpdatedAt   time.Time       `json:"updatedAt"`
+}
+
+// TemplateParam declares a parameter of a template. Parameters without default are required.
+type TemplateParam struct {
+	Name        string  `json:"name"`
+	Type        string  `json:"type"`
+	Description string  `json:"description,omitempty"`
+	Default     *string `json:"default,omitempty"`
+}
+
+// TemplateReference links a pipeline to the template version it was rendered from. Values holds
+// the rendered value of every parameter, so that the pipeline can be rendered again.
+type TemplateReference struct {
+	TemplateId string            `json:"templateId"`
+	Version    int               `json:"version"`
+	Values     map[string]string `json:"values,omitempty"`
+}
+
+// TemplateInstantiation is the request to create a pipeline from a template. Values are strings,
+// numbers or booleans according to the parameter types, Name overrides the name of the skeleton.
+type TemplateInstantiation struct {
+	Name   string         `json:"name,omitempty"`
+	Values map[string]any `json:"values,omitempty"`
+}
+
+var templatePlaceholder = regexp.MustCompile(`\$(\$?)\{([A-Za-z_][A-Za-z0-9_]*)\}`)
+
+// TemplatePlaceholders returns the sorted names of all parameters used by the operators of pipeline.
+func TemplatePlaceholders(pipeline Pipeline) (names []string) {
+	forEachTemplateField(&pipeline, true, func(value string) string {
+		for _, match := range templatePlaceholder.FindAllStringSubmatch(value, -1) {
+			if match[1] == "" && !slices.Contains(names, match[2]) {
+				names = append(names, match[2])
+			}
+		}
+		return value
+	})
+	slices.Sort(names)
+	return
+}
+
+// RenderTemplate replaces the placeholders of template with values, or the defaults of the
+// parameters, and references to the pipeline with pipelineId. Values are checked against the
+// parameter types, violations are reported as ValidationError. The returned map holds the
+// rendered value of every parameter.
+func RenderTemplate(template PipelineTemplate, pipelineId string, values map[string]any) (pipeline Pipeline, rendered map[string]string, err error) {
+	rendered = map[string]string{}
+	var violations []Violation
+	for name := range values {
+		if !slices.ContainsFunc(template.Params, func(param TemplateParam) bool { return param.Name == name }) {
+			violations = append(violations, Violation{Path: "values." + name, Message: "unknown parameter"})
+		}
+	}
+	for _, param := range template.Params {
+		value, ok := values[param.Name]
+		if !ok || value == nil {
+			if param.Default == nil {
+				violations = append(violations, Violation{Path: "values." + param.Name, Message: "must not be empty"})
+				continue
+			}
+			value = *param.Default
+		}
+		s, err := TemplateValue(param.Type, value)
+		if err != nil {
+			violations = append(violations, Violation{Path: "values." + param.Name, Message: err.Error()})
+			continue
+		}
+		rendered[param.Name] = s
+	}
+	if len(violations) > 0 {
+		slices.SortFunc(violations, func(a, b Violation) int { return strings.Compare(a.Path, b.Path) })
+		return pipeline, nil, NewValidationError(violations)
+	}
+	pipeline = template.Pipeline
+	pipeline.Operators = make([]Operator, len(template.Pipeline.Operators))
+	for i, operator := range template.Pipeline.Operators {
+		pipeline.Operators[i] = copyOperator(operator)
+		for j, selection := range operator.InputSelections {
+			pipeline.Operators[i].InputSelections[j].CharacteristicIds = slices.Clone(selection.CharacteristicIds)
+		}
+		for j, topic := range operator.InputTopics {
+			pipeline.Operators[i].InputTopics[j].Mappings = slices.Clone(topic.Mappings)
+		}
+	}
+	forEachTemplateField(&pipeline, false, func(value string) string {
+		return templatePlaceholder.ReplaceAllStringFunc(value, func(match string) string {
+			groups := templatePlaceholder.FindStringSubmatch(match)
+			if groups[1] != "" {
+				return match[1:]
+			}
+			return rendered[groups[2]]
+		})
+	})
+	pipeline.Id = pipelineId
+	for i := range pipeline.Operators {
+		operator := &pipeline.Operators[i]
+		operator.OutputTopic = strings.ReplaceAll(operator.OutputTopic, BundlePipelineRef, pipelineId)
+		for j := range operator.InputTopics {
+			operator.InputTopics[j].Name = strings.ReplaceAll(operator.InputTopics[j].Name, BundlePipelineRef, pipelineId)
+			operator.InputTopics[j].FilterValue = strings.ReplaceAll(operator.InputTopics[j].FilterValue, BundlePipelineRef, pipelineId)
+		}
+	}
+	return
+}
+
+// TemplateValue checks value against a parameter type and returns its string representation.
+// Besides values of the matching JSON type, strings that parse as the type are accepted.
+func TemplateValue(paramType string, value any) (string, error) {
+	switch paramType {
+	case TemplateParamString:
+		if s, ok := value.(string); ok {
+			return s, nil
+		}
+	case TemplateParamNumber, TemplateParamInteger:
+		var f float64
+		switch v := value.(type) {
+		case float64:
+			f = v
+		case int:
+			f = float64(v)
+		case string:
+			var err error
+			f, err = strconv.ParseFloat(v, 64)
+			if err != nil {
+				return "", fmt.Errorf("expected %s", paramType)
+			}
+		default:
+			return "", fmt.Errorf("expected %s", paramType)
+		}
+		if paramType == TemplateParamInteger {
+			if f != math.Trunc(f) || math.IsInf(f, 0) {
+				return "", fmt.Errorf("expected %s", paramType)
+			}
+			return strconv.FormatInt(int64(f), 10), nil
+		}
+		return strconv.FormatFloat(f, 'f', -1, 64), nil
+	case TemplateParamBoolean:
+		switch v := value.(type) {
+		case bool:
+			return strconv.FormatBool(v), nil
+		case string:
+			b, err := strconv.ParseBool(v)
+			if err == nil {
+				return strconv.FormatBool(b), nil
+			}
+		}
+	default:
+		return "", fmt.Errorf("unknown parameter type %q", paramType)
+	}
+	return "", fmt.Errorf("expected %s", paramType)
+}
+
+// forEachTemplateField applies f to every field of the operators that may contain placeholders.
+// With readOnly, the results of f are discarded.
+func forEachTemplateField(pipeline *Pipeline, readOnly bool, f func(string) string) {
+	apply := func(value *string) {
+		result := f(*value)
+		if !readOnly {
+			*value = result
+		}
+	}
+	for i := range pipeline.Operators {
+		operator := &pipeline.Operators[i]
+		for _, key := range sortedKeys(operator.Config, nil) {
+			value := operator.Config[key]
+			apply(&value)
+			if !readOnly {
+				operator.Config[key] = value
+			}
+		}
+		for j := range operator.InputTopics {
+			topic := &operator.InputTopics[j]
+			apply(&topic.Name)
+			apply(&topic.FilterType)
+			apply(&topic.FilterValue)
+			apply(&topic.FilterValue2)
+			for k := range topic.Mappings {
+				apply(&topic.Mappings[k].Source)
+				apply(&topic.Mappings[k].Dest)
+			}
+		}
+		for j := range operator.InputSelections {
+			selection := &operator.InputSelections[j]
+			apply(&selection.InputName)
+			apply(&selection.AspectId)
+			apply(&selection.FunctionId)
+			apply(&selection.SelectableId)
+			for k := range selection.CharacteristicIds {
+				apply(&selection.CharacteristicIds[k])
+			}
+		}
+	}
+}
@@ -0,0 +1,89 @@
+/*
+ * Copyright 2025 InfAI (CC SES)
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *      http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package lib
+
+import (
+	"errors"
+	"slices"
+	"testing"
+)
+
+func templateTestTemplate() PipelineTemplate {
+	threshold := "10"
+	return PipelineTemplate{
+		Id:      "t1",
+		Version: 2,
+		Params: []TemplateParam{
+			{Name: "device", Type: TemplateParamString},
+			{Name: "threshold", Type: TemplateParamNumber, Default: &threshold},
+			{Name: "window", Type: TemplateParamInteger},
+		},
+		Pipeline: Pipeline{Name: "alarm", Operators: []Operator{
+			{Id: "o1", OperatorId: "threshold", OutputTopic: "alarms", Config: map[string]string{"limit": "${threshold}", "window": "${window}s", "literal": "$${device}"},
+				InputTopics:     []InputTopic{{Name: "${device}-topic", FilterType: FilterTypeDeviceId, FilterValue: "${device}"}},
+				InputSelections: []InputSelection{{InputName: "value", SelectableId: "${device}"}},
+			},
+			{Id: "o2", OperatorId: "notify", InputTopics: []InputTopic{{Name: "alarms", FilterType: FilterTypeOperatorId, FilterValue: "o1:{pipeline}"}}},
+		}},
+	}
+}
+
+func TestTemplatePlaceholders(t *testing.T) {
+	names := TemplatePlaceholders(templateTestTemplate().Pipeline)
+	if !slices.Equal(names, []string{"device", "threshold", "window"}) {
+		t.Errorf("unexpected placeholders %v", names)
+	}
+}
+
+func TestRenderTemplate(t *testing.T) {
+	template := templateTestTemplate()
+	pipeline, values, err := RenderTemplate(template, "p1", map[string]any{"device": "d1", "window": float64(30)})
+	if err != nil {
+		t.Fatal(err)
+	}
+	operator := pipeline.Operators[0]
+	if operator.Config["limit"] != "10" || operator.Config["window"] != "30s" || operator.Config["literal"] != "${device}" {
+		t.Errorf("unexpected config %v", operator.Config)
+	}
+	if operator.InputTopics[0].Name != "d1-topic" || operator.InputTopics[0].FilterValue != "d1" || operator.InputSelections[0].SelectableId != "d1" {
+		t.Errorf("unexpected operator %+v", operator)
+	}
+	if pipeline.Id != "p1" || pipeline.Operators[1].InputTopics[0].FilterValue != "o1:p1" {
+		t.Errorf("pipeline reference not replaced: %+v", pipeline)
+	}
+	if values["threshold"] != "10" || values["window"] != "30" || values["device"] != "d1" {
+		t.Errorf("unexpected values %v", values)
+	}
+	if template.Pipeline.Operators[0].Config["limit"] != "${threshold}" || template.Pipeline.Operators[0].InputTopics[0].Name != "${device}-topic" {
+		t.Error("template was modified")
+	}
+}
+
+func TestRenderTemplate_Violations(t *testing.T) {
+	_, _, err := RenderTemplate(templateTestTemplate(), "p1", map[string]any{"window": 1.5, "threshold": "high", "unknown": true})
+	var validationErr *ValidationError
+	if !errors.As(err, &validationErr) {
+		t.Fatalf("expected validation error, got %v", err)
+	}
+	var paths []string
+	for _, violation := range validationErr.Violations {
+		paths = append(paths, violation.Path)
+	}
+	if !slices.Equal(paths, []string{"values.device", "values.threshold", "values.unknown", "values.window"}) {
+		t.Errorf("unexpected violations %v", validationErr.Violations)
+	}
+}
@@ -0,0 +1,38 @@
+/*
+ * Copyright 2025 InfAI (CC SES)
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *      http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package lib
+
+// PipelineTransfer hands a pipeline over to UserId, who gets all permissions. The previous owner
+// keeps its permissions as regular sharing with KeepPermissions, otherwise they are removed.
+type PipelineTransfer struct {
+	UserId          string `json:"userId"`
+	KeepPermissions bool   `json:"keepPermissions,omitempty"`
+}
+
+// PipelinesTransfer hands all pipelines of FromUserId over to ToUserId, trashed ones included.
+type PipelinesTransfer struct {
+	FromUserId      string `json:"fromUserId"`
+	ToUserId        string `json:"toUserId"`
+	KeepPermissions bool   `json:"keepPermissions,omitempty"`
+}
+
+// PipelinesTransferResult lists the transferred pipelines and the error per pipeline that could
+// not be transferred. Every pipeline is transferred on its own.
+type PipelinesTransferResult struct {
+	Transferred []string          `json:"transferred"`
+	Failed      map[string]string `json:"failed,omitempty"`
+}
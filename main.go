@@ -74,7 +74,7 @@ func main() {
 		perm = permV2Client.New(cfg.PermissionsV2Url)
 	}
 
-	httpHandler, err := api.CreateServer(cfg, perm)
+	httpHandler, err := api.CreateServer(ctx, cfg, perm)
 	if err != nil {
 		util.Logger.Error("error creating http engine", "error", err)
 		ec = 1
@@ -17,6 +17,7 @@
 package api
 
 import (
+	"context"
 	"errors"
 	"net/http"
 	"slices"
@@ -38,7 +39,8 @@ import (
 
 // CreateServer creates a new gin.Engine instance and configures it according to the given config.
 // It sets up the middleware for logging, recovery, authentication, and request ID tracking.
-// It also sets up the routes for the API using the given registry.
+// It also sets up the routes for the API using the given registry and starts its background jobs,
+// which stop when ctx is done.
 // The server is started at the port specified in the config.
 // @title Analytics-Pipeline API
 // @version {version}
@@ -46,7 +48,7 @@ import (
 // @license.name Apache-2.0
 // @license.url http://www.apache.org/licenses/LICENSE-2.0.html
 // @BasePath /
-func CreateServer(cfg *config.Config, perm permV2Client.Client) (r *gin.Engine, err error) {
+func CreateServer(ctx context.Context, cfg *config.Config, perm permV2Client.Client) (r *gin.Engine, err error) {
 	port := strconv.FormatInt(int64(cfg.ServerPort), 10)
 	util.Logger.Info("Starting api server at port " + port)
 
@@ -57,9 +59,9 @@ func CreateServer(cfg *config.Config, perm permV2Client.Client) (r *gin.Engine,
 	r.RedirectTrailingSlash = false
 	r.Use(cors.New(cors.Config{
 		AllowOrigins:     []string{"*"},
-		AllowMethods:     []string{"GET", "POST", "DELETE", "OPTIONS", "PUT"},
-		AllowHeaders:     []string{"Origin", "Content-Type", "Authorization"},
-		ExposeHeaders:    []string{"Content-Length"},
+		AllowMethods:     []string{"GET", "POST", "DELETE", "OPTIONS", "PUT", "PATCH"},
+		AllowHeaders:     []string{"Origin", "Content-Type", "Authorization", HeaderIfMatch, HeaderLastEventID},
+		ExposeHeaders:    []string{"Content-Length", HeaderETag},
 		AllowCredentials: true,
 	}))
 	var middleware []gin.HandlerFunc
@@ -75,6 +77,7 @@ func CreateServer(cfg *config.Config, perm permV2Client.Client) (r *gin.Engine,
 	middleware = append(middleware,
 		requestid.New(requestid.WithCustomHeaderStrKey(HeaderRequestID)),
 		gin_mw.ErrorHandler(util.GetStatusCode, ", "),
+		ValidationErrorHandler(),
 		gin_mw.StructRecoveryHandler(util.Logger, gin_mw.DefaultRecoveryFunc),
 	)
 	r.Use(middleware...)
@@ -82,9 +85,23 @@ func CreateServer(cfg *config.Config, perm permV2Client.Client) (r *gin.Engine,
 	prefix := r.Group(cfg.URLPrefix)
 
 	REGISTRY := service.NewRegistry(db.NewMongoRepo(), perm)
-	err = REGISTRY.ValidateOperatorPermissions()
-	if err != nil {
-		return nil, err
+	REGISTRY.StartJournal(ctx, service.JournalConfig{
+		RetryInterval:  cfg.Journal.RetryInterval,
+		InitialBackoff: cfg.Journal.InitialBackoff,
+	})
+	REGISTRY.StartReconciler(ctx, cfg.ReconcileInterval)
+	REGISTRY.StartTrashPurger(ctx, cfg.TrashRetention, cfg.TrashPurgeInterval)
+	REGISTRY.StartWebhookDispatcher(ctx, service.WebhookConfig{
+		MaxAttempts:          cfg.Webhook.MaxAttempts,
+		InitialBackoff:       cfg.Webhook.InitialBackoff,
+		Timeout:              cfg.Webhook.Timeout,
+		AllowedHosts:         cfg.Webhook.AllowedHosts,
+		DeniedHosts:          cfg.Webhook.DeniedHosts,
+		AllowPrivateNetworks: cfg.Webhook.AllowPrivateNetworks,
+	})
+	if cfg.Kafka.Bootstrap != "" {
+		publisher := service.NewKafkaPublisher(cfg.Kafka.Bootstrap, cfg.Kafka.PipelineEventsTopic)
+		REGISTRY.StartEventPublisher(ctx, publisher, cfg.Kafka.PublishInterval)
 	}
 	setRoutes, err := routes.Set(*REGISTRY, prefix)
 	if err != nil {
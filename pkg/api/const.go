@@ -16,9 +16,17 @@
 
 package api
 
+import "time"
+
+// EventStreamHeartbeat is the interval of comments sent on idle event streams to keep proxies from closing them.
+const EventStreamHeartbeat = 30 * time.Second
+
 const (
 	HeaderRequestID     = "X-Request-ID"
 	HeaderAuthorization = "Authorization"
+	HeaderETag          = "ETag"
+	HeaderIfMatch       = "If-Match"
+	HeaderLastEventID   = "Last-Event-ID"
 	UserIdKey           = "UserId"
 	AdminKey            = "admin"
 )
@@ -26,6 +34,8 @@ const (
 const (
 	HealthCheckPath = "/health-check"
 	PipelinePath    = "/pipeline"
+	WebhookPath     = "/webhook"
+	TemplatePath    = "/template"
 )
 
 const (
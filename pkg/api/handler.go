@@ -20,6 +20,9 @@ import (
 	"errors"
 	"net/http"
 	"os"
+	"strconv"
+	"strings"
+	"time"
 
 	"github.com/SENERGY-Platform/analytics-pipeline/lib"
 	"github.com/SENERGY-Platform/analytics-pipeline/pkg/service"
@@ -29,13 +32,13 @@ import (
 
 // postPipeline returns a handler function for the "/pipeline" endpoint that saves a pipeline
 // @Summary Save a pipeline
-// @Description Saves a pipeline given a pipeline request
+// @Description Saves a pipeline given a pipeline request. Invalid pipelines are rejected with a list of violations and their JSON paths.
 // @Tags pipelines
 // @Accept json
 // @Produce json
 // @Param request body lib.Pipeline true "Pipeline request"
 // @Success 200 {object} map[string]string "Pipeline ID"
-// @Failure 400 {string} MessageBadInput
+// @Failure 400 {object} lib.ValidationError
 // @Failure 401
 // @Failure 500 {string} MessageSomethingWrong
 // @Router /pipeline [post]
@@ -48,7 +51,7 @@ func postPipeline(registry service.Registry) (string, string, gin.HandlerFunc) {
 			_ = c.Error(lib.NewInputError(errors.New(MessageBadInput)))
 			return
 		}
-		id, err := registry.SavePipeline(request, c.GetString(UserIdKey))
+		id, err := registry.SavePipeline(request, c.GetString(UserIdKey), c.GetHeader(HeaderAuthorization))
 		if err != nil {
 			util.Logger.Error("could not get save pipeline", "error", err, "method", "POST", "path", PipelinePath)
 			_ = c.Error(handleError(err))
@@ -60,16 +63,21 @@ func postPipeline(registry service.Registry) (string, string, gin.HandlerFunc) {
 
 // putPipeline returns a handler function for the "/pipeline" endpoint that updates a pipeline
 // @Summary Update a pipeline
-// @Description Updates a pipeline given a pipeline request
+// @Description Updates a pipeline given a pipeline request. Invalid pipelines are rejected with a list of violations and their JSON paths.
+// @Description If If-Match is set, the update is only applied to the given version. Otherwise, a version sent with the pipeline has to match the stored version.
 // @Tags pipelines
 // @Accept json
 // @Produce json
 // @Param request body lib.Pipeline true "Pipeline request"
+// @Param If-Match header string false "ETag of the pipeline version to update"
 // @Success 200 {object} map[string]string "Pipeline ID"
-// @Failure 400 {string} MessageBadInput
+// @Header 200 {string} ETag "Version of the updated pipeline"
+// @Failure 400 {object} lib.ValidationError
 // @Failure 401
 // @Failure 403 {string} MessageForbidden
 // @Failure 404 {string} MessageNotFound
+// @Failure 409 {string} db.MessageVersionConflict
+// @Failure 412 {string} service.MessageVersionMismatch
 // @Failure 500 {string} MessageSomethingWrong
 // @Router /pipeline [put]
 // @Security Bearer
@@ -81,13 +89,64 @@ func putPipeline(registry service.Registry) (string, string, gin.HandlerFunc) {
 			_ = c.Error(lib.NewInputError(errors.New(MessageBadInput)))
 			return
 		}
-		id, err := registry.UpdatePipeline(request, c.GetString(UserIdKey), c.GetHeader(HeaderAuthorization))
+		ifMatch, err := parseIfMatch(c.GetHeader(HeaderIfMatch))
+		if err != nil {
+			_ = c.Error(err)
+			return
+		}
+		pipe, err := registry.UpdatePipeline(request, c.GetString(UserIdKey), c.GetHeader(HeaderAuthorization), ifMatch)
 		if err != nil {
 			util.Logger.Error("could not get save pipeline", "error", err, "method", "POST", "path", PipelinePath)
 			_ = c.Error(handleError(err))
 			return
 		}
-		c.JSON(http.StatusOK, gin.H{"id": id})
+		c.Header(HeaderETag, etag(pipe.Version))
+		c.JSON(http.StatusOK, gin.H{"id": pipe.Id})
+	}
+}
+
+// patchPipeline returns a handler function for the "/pipeline/:id" endpoint that partially updates a pipeline
+// @Summary Partially update a pipeline
+// @Description Applies a JSON merge patch (RFC 7386, application/merge-patch+json) or a JSON patch (RFC 6902, application/json-patch+json) to the stored pipeline.
+// @Description The patched pipeline is validated like a full update. If-Match is honored like on PUT.
+// @Tags pipelines
+// @Accept json
+// @Produce json
+// @Param id path string true "Pipeline ID"
+// @Param request body object true "Merge patch object or JSON patch operation list"
+// @Param If-Match header string false "ETag of the pipeline version to patch"
+// @Success 200 {object} lib.Pipeline
+// @Header 200 {string} ETag "Version of the updated pipeline"
+// @Failure 400 {object} lib.ValidationError
+// @Failure 401
+// @Failure 403 {string} MessageForbidden
+// @Failure 404 {string} MessageNotFound
+// @Failure 409 {string} db.MessageVersionConflict
+// @Failure 412 {string} service.MessageVersionMismatch
+// @Failure 500 {string} MessageSomethingWrong
+// @Router /pipeline/:id [patch]
+// @Security Bearer
+func patchPipeline(registry service.Registry) (string, string, gin.HandlerFunc) {
+	return http.MethodPatch, "/pipeline/:id", func(c *gin.Context) {
+		id := c.Param("id")
+		patch, err := c.GetRawData()
+		if err != nil {
+			_ = c.Error(lib.NewInputError(errors.New(MessageBadInput)))
+			return
+		}
+		ifMatch, err := parseIfMatch(c.GetHeader(HeaderIfMatch))
+		if err != nil {
+			_ = c.Error(err)
+			return
+		}
+		pipe, err := registry.PatchPipeline(id, patch, c.ContentType(), c.GetString(UserIdKey), c.GetHeader(HeaderAuthorization), ifMatch)
+		if err != nil {
+			util.Logger.Error("could not patch pipeline", "error", err, "method", "PATCH", "path", "/pipeline/"+id)
+			_ = c.Error(handleError(err))
+			return
+		}
+		c.Header(HeaderETag, etag(pipe.Version))
+		c.JSON(http.StatusOK, pipe)
 	}
 }
 
@@ -99,6 +158,7 @@ func putPipeline(registry service.Registry) (string, string, gin.HandlerFunc) {
 // @Produce json
 // @Param id path string true "Pipeline ID"
 // @Success 200 {object} lib.Pipeline
+// @Header 200 {string} ETag "Version of the pipeline"
 // @Failure 401
 // @Failure 403 {string} MessageForbidden
 // @Failure 404 {string} MessageNotFound
@@ -114,28 +174,537 @@ func getPipeline(registry service.Registry) (string, string, gin.HandlerFunc) {
 			_ = c.Error(handleError(err))
 			return
 		}
+		c.Header(HeaderETag, etag(pipe.Version))
+		c.JSON(http.StatusOK, pipe)
+	}
+}
+
+// getPipelineGraph returns a handler function for the "/pipeline/:id/graph" endpoint that retrieves the operator graph of a pipeline
+// @Summary Retrieve the operator graph of a pipeline
+// @Description Retrieves the operators of a pipeline as nodes and the topics connecting them as edges, including cycles, dangling input topics and operators whose output is never consumed or persisted
+// @Tags pipelines
+// @Accept json
+// @Produce json
+// @Param id path string true "Pipeline ID"
+// @Success 200 {object} lib.PipelineGraph
+// @Failure 401
+// @Failure 403 {string} MessageForbidden
+// @Failure 404 {string} MessageNotFound
+// @Failure 500 {string} MessageSomethingWrong
+// @Router /pipeline/:id/graph [get]
+// @Security Bearer
+func getPipelineGraph(registry service.Registry) (string, string, gin.HandlerFunc) {
+	return http.MethodGet, "/pipeline/:id/graph", func(c *gin.Context) {
+		id := c.Param("id")
+		graph, err := registry.GetPipelineGraph(id, c.GetString(UserIdKey), c.GetHeader(HeaderAuthorization))
+		if err != nil {
+			util.Logger.Error("could not get pipeline graph", "error", err, "method", "GET", "path", "/pipeline/"+id+"/graph")
+			_ = c.Error(handleError(err))
+			return
+		}
+		c.JSON(http.StatusOK, graph)
+	}
+}
+
+// getPipelineExport returns a handler function for the "/pipeline/:id/export" endpoint that renders a pipeline in an export format
+// @Summary Export a pipeline
+// @Description Exports a pipeline as versioned bundle (format json or yaml), which can be imported with POST /pipeline/import. Bundles contain no ids, user ids or timestamps.
+// @Description Alternatively, renders the operators of a pipeline as Graphviz DOT (format dot) or Mermaid flowchart (format mermaid).
+// @Description Operators are nodes and topics are edges labelled with filter and mappings. Fog operators are drawn dashed, operators persisting their data with a bold or double border.
+// @Tags pipelines
+// @Produce json,plain
+// @Param id path string true "Pipeline ID"
+// @Param format query string false "json, yaml, dot or mermaid, defaults to json"
+// @Success 200 {object} lib.PipelineBundle
+// @Failure 400 {string} MessageBadInput
+// @Failure 401
+// @Failure 403 {string} MessageForbidden
+// @Failure 404 {string} MessageNotFound
+// @Failure 500 {string} MessageSomethingWrong
+// @Router /pipeline/:id/export [get]
+// @Security Bearer
+func getPipelineExport(registry service.Registry) (string, string, gin.HandlerFunc) {
+	return http.MethodGet, "/pipeline/:id/export", func(c *gin.Context) {
+		id := c.Param("id")
+		content, contentType, err := registry.ExportPipeline(id, c.GetString(UserIdKey), c.GetHeader(HeaderAuthorization), c.Query("format"))
+		if err != nil {
+			util.Logger.Error("could not export pipeline", "error", err, "method", "GET", "path", "/pipeline/"+id+"/export")
+			_ = c.Error(handleError(err))
+			return
+		}
+		c.Data(http.StatusOK, contentType, content)
+	}
+}
+
+// postPipelineImport returns a handler function for the "/pipeline/import" endpoint that creates a pipeline from a bundle
+// @Summary Import a pipeline
+// @Description Creates a pipeline from a bundle exported with GET /pipeline/:id/export. The pipeline and its operators get new ids, references to them in topic names and filter values are remapped.
+// @Description The bundle is read as YAML if format is yaml or the content type contains yaml, otherwise as JSON. With dryRun, the pipeline is validated and returned without being created.
+// @Tags pipelines
+// @Accept json
+// @Produce json
+// @Param request body lib.PipelineBundle true "Pipeline bundle"
+// @Param format query string false "json or yaml"
+// @Param dryRun query bool false "Only report the pipeline that would be created"
+// @Success 200 {object} lib.PipelineImportResult
+// @Failure 400 {object} lib.ValidationError
+// @Failure 401
+// @Failure 500 {string} MessageSomethingWrong
+// @Router /pipeline/import [post]
+// @Security Bearer
+func postPipelineImport(registry service.Registry) (string, string, gin.HandlerFunc) {
+	return http.MethodPost, "/pipeline/import", func(c *gin.Context) {
+		dryRun := false
+		if value := c.Query("dryRun"); value != "" {
+			var err error
+			dryRun, err = strconv.ParseBool(value)
+			if err != nil {
+				_ = c.Error(lib.NewInputError(errors.New(MessageBadInput)))
+				return
+			}
+		}
+		format := c.Query("format")
+		if format == "" {
+			format = lib.ExportFormatJSON
+			if strings.Contains(c.ContentType(), "yaml") {
+				format = lib.ExportFormatYAML
+			}
+		}
+		data, err := c.GetRawData()
+		if err != nil {
+			_ = c.Error(lib.NewInputError(errors.New(MessageBadInput)))
+			return
+		}
+		result, err := registry.ImportPipeline(data, format, c.GetString(UserIdKey), c.GetHeader(HeaderAuthorization), dryRun)
+		if err != nil {
+			util.Logger.Error("could not import pipeline", "error", err, "method", "POST", "path", "/pipeline/import")
+			_ = c.Error(handleError(err))
+			return
+		}
+		c.JSON(http.StatusOK, result)
+	}
+}
+
+// postPipelineClone returns a handler function for the "/pipeline/:id/clone" endpoint that copies a pipeline
+// @Summary Clone a pipeline
+// @Description Copies a pipeline the user can read under a new id owned by the user. Operators get new ids, references to them or the pipeline in topic names and filter values are remapped.
+// @Description Sharing is only copied with copyPermissions, which requires the administrate permission on the source. Clones start as draft unless draft is false, then they start stopped.
+// @Tags pipelines
+// @Accept json
+// @Produce json
+// @Param id path string true "Pipeline ID"
+// @Param request body lib.PipelineCloneOptions false "Clone options"
+// @Success 200 {object} map[string]string "Pipeline ID"
+// @Failure 400 {object} lib.ValidationError
+// @Failure 401
+// @Failure 403 {string} MessageForbidden
+// @Failure 404 {string} MessageNotFound
+// @Failure 500 {string} MessageSomethingWrong
+// @Router /pipeline/:id/clone [post]
+// @Security Bearer
+func postPipelineClone(registry service.Registry) (string, string, gin.HandlerFunc) {
+	return http.MethodPost, "/pipeline/:id/clone", func(c *gin.Context) {
+		id := c.Param("id")
+		var request lib.PipelineCloneOptions
+		if c.Request.ContentLength != 0 {
+			if err := c.ShouldBindJSON(&request); err != nil {
+				util.Logger.Error("error parsing request", "error", err, "method", "POST", "path", "/pipeline/"+id+"/clone")
+				_ = c.Error(lib.NewInputError(errors.New(MessageBadInput)))
+				return
+			}
+		}
+		cloneId, err := registry.ClonePipeline(id, request, c.GetString(UserIdKey), c.GetHeader(HeaderAuthorization))
+		if err != nil {
+			util.Logger.Error("could not clone pipeline", "error", err, "method", "POST", "path", "/pipeline/"+id+"/clone")
+			_ = c.Error(handleError(err))
+			return
+		}
+		c.JSON(http.StatusOK, gin.H{"id": cloneId})
+	}
+}
+
+// postPipelineBatch returns a handler function for the "/pipeline/batch" endpoint that creates, updates and deletes several pipelines at once
+// @Summary Create, update and delete pipelines in a batch
+// @Description Applies up to 100 operations with a single write. In atomic mode, the default, no operation is applied if any of them fails, valid operations then report status 424.
+// @Description In bestEffort mode, every valid operation is applied. Each result reports the status code and error the corresponding single request would have returned. Deletes move pipelines to the trash.
+// @Tags pipelines
+// @Accept json
+// @Produce json
+// @Param request body lib.PipelineBatchRequest true "Batch operations"
+// @Success 200 {object} lib.PipelineBatchResponse
+// @Failure 400 {string} MessageBadInput
+// @Failure 401
+// @Failure 500 {string} MessageSomethingWrong
+// @Router /pipeline/batch [post]
+// @Security Bearer
+func postPipelineBatch(registry service.Registry) (string, string, gin.HandlerFunc) {
+	return http.MethodPost, "/pipeline/batch", func(c *gin.Context) {
+		var request lib.PipelineBatchRequest
+		if err := c.ShouldBindJSON(&request); err != nil {
+			util.Logger.Error("error parsing request", "error", err, "method", "POST", "path", "/pipeline/batch")
+			_ = c.Error(lib.NewInputError(errors.New(MessageBadInput)))
+			return
+		}
+		response, err := registry.BatchPipelines(request, c.GetString(UserIdKey), c.GetHeader(HeaderAuthorization))
+		if err != nil {
+			util.Logger.Error("could not apply pipeline batch", "error", err, "method", "POST", "path", "/pipeline/batch")
+			_ = c.Error(handleError(err))
+			return
+		}
+		c.JSON(http.StatusOK, response)
+	}
+}
+
+// getPipelinePermissions returns a handler function for the "/pipeline/:id/permissions" endpoint that retrieves the sharing of a pipeline
+// @Summary Retrieve the permissions of a pipeline
+// @Description Lists the permissions granted on a pipeline per user, group and role. Requires the administrate permission.
+// @Tags pipelines
+// @Produce json
+// @Param id path string true "Pipeline ID"
+// @Success 200 {object} lib.PipelinePermissions
+// @Failure 401
+// @Failure 403 {string} MessageForbidden
+// @Failure 404 {string} MessageNotFound
+// @Failure 500 {string} MessageSomethingWrong
+// @Router /pipeline/:id/permissions [get]
+// @Security Bearer
+func getPipelinePermissions(registry service.Registry) (string, string, gin.HandlerFunc) {
+	return http.MethodGet, "/pipeline/:id/permissions", func(c *gin.Context) {
+		id := c.Param("id")
+		permissions, err := registry.GetPipelinePermissions(id, c.GetString(UserIdKey), c.GetHeader(HeaderAuthorization))
+		if err != nil {
+			util.Logger.Error("could not get pipeline permissions", "error", err, "method", "GET", "path", "/pipeline/"+id+"/permissions")
+			_ = c.Error(handleError(err))
+			return
+		}
+		c.JSON(http.StatusOK, permissions)
+	}
+}
+
+// putPipelinePermissions returns a handler function for the "/pipeline/:id/permissions" endpoint that replaces the sharing of a pipeline
+// @Summary Replace the permissions of a pipeline
+// @Description Replaces the permissions granted on a pipeline per user, group and role. Requires the administrate permission.
+// @Description At least one user has to keep the administrate permission and the owner keeps all permissions, the owner is changed by transferring the pipeline.
+// @Tags pipelines
+// @Accept json
+// @Produce json
+// @Param id path string true "Pipeline ID"
+// @Param request body lib.PipelinePermissions true "Permissions"
+// @Success 200 {object} lib.PipelinePermissions
+// @Failure 400 {string} MessageBadInput
+// @Failure 401
+// @Failure 403 {string} MessageForbidden
+// @Failure 404 {string} MessageNotFound
+// @Failure 500 {string} MessageSomethingWrong
+// @Router /pipeline/:id/permissions [put]
+// @Security Bearer
+func putPipelinePermissions(registry service.Registry) (string, string, gin.HandlerFunc) {
+	return http.MethodPut, "/pipeline/:id/permissions", func(c *gin.Context) {
+		id := c.Param("id")
+		var request lib.PipelinePermissions
+		if err := c.ShouldBindJSON(&request); err != nil {
+			util.Logger.Error("error parsing request", "error", err, "method", "PUT", "path", "/pipeline/"+id+"/permissions")
+			_ = c.Error(lib.NewInputError(errors.New(MessageBadInput)))
+			return
+		}
+		permissions, err := registry.SetPipelinePermissions(id, request, c.GetString(UserIdKey), c.GetHeader(HeaderAuthorization))
+		if err != nil {
+			util.Logger.Error("could not set pipeline permissions", "error", err, "method", "PUT", "path", "/pipeline/"+id+"/permissions")
+			_ = c.Error(handleError(err))
+			return
+		}
+		c.JSON(http.StatusOK, permissions)
+	}
+}
+
+// postPipelineTransfer returns a handler function for the "/pipeline/:id/transfer" endpoint that hands a pipeline over to another user
+// @Summary Transfer a pipeline
+// @Description Makes another user the owner of a pipeline and grants them all permissions. The previous owner loses their permissions unless keepPermissions is set, other sharing is kept.
+// @Description Only the owner or an admin can transfer a pipeline, trashed pipelines included.
+// @Tags pipelines
+// @Accept json
+// @Param id path string true "Pipeline ID"
+// @Param request body lib.PipelineTransfer true "New owner"
+// @Success 204
+// @Failure 400 {string} MessageBadInput
+// @Failure 401
+// @Failure 403 {string} MessageForbidden
+// @Failure 404 {string} MessageNotFound
+// @Failure 500 {string} MessageSomethingWrong
+// @Router /pipeline/:id/transfer [post]
+// @Security Bearer
+func postPipelineTransfer(registry service.Registry) (string, string, gin.HandlerFunc) {
+	return http.MethodPost, "/pipeline/:id/transfer", func(c *gin.Context) {
+		id := c.Param("id")
+		var request lib.PipelineTransfer
+		if err := c.ShouldBindJSON(&request); err != nil {
+			util.Logger.Error("error parsing request", "error", err, "method", "POST", "path", "/pipeline/"+id+"/transfer")
+			_ = c.Error(lib.NewInputError(errors.New(MessageBadInput)))
+			return
+		}
+		admin, err := isAdmin(c)
+		if err != nil {
+			util.Logger.Error("could not check admin role", "error", err)
+			c.AbortWithStatus(http.StatusUnauthorized)
+			return
+		}
+		err = registry.TransferPipeline(id, request, c.GetString(UserIdKey), admin)
+		if err != nil {
+			util.Logger.Error("could not transfer pipeline", "error", err, "method", "POST", "path", "/pipeline/"+id+"/transfer")
+			_ = c.Error(handleError(err))
+			return
+		}
+		c.Status(http.StatusNoContent)
+	}
+}
+
+// getPipelineLineage returns a handler function for the "/pipeline/:id/lineage" endpoint that retrieves the pipelines linked by topics
+// @Summary Retrieve the lineage of a pipeline
+// @Description Walks the links between pipelines, where an output topic of one pipeline is an input topic of another, across all pipelines the user can read.
+// @Description Downstream lineage shows the pipelines affected by changing or deleting the pipeline.
+// @Tags pipelines
+// @Produce json
+// @Param id path string true "Pipeline ID"
+// @Param direction query string false "up or down, defaults to down"
+// @Param depth query int false "Maximum distance to the pipeline, 0 or empty for unlimited"
+// @Success 200 {object} lib.PipelineLineage
+// @Failure 400 {string} MessageBadInput
+// @Failure 401
+// @Failure 403 {string} MessageForbidden
+// @Failure 404 {string} MessageNotFound
+// @Failure 500 {string} MessageSomethingWrong
+// @Router /pipeline/:id/lineage [get]
+// @Security Bearer
+func getPipelineLineage(registry service.Registry) (string, string, gin.HandlerFunc) {
+	return http.MethodGet, "/pipeline/:id/lineage", func(c *gin.Context) {
+		id := c.Param("id")
+		depth := 0
+		if value := c.Query("depth"); value != "" {
+			var err error
+			depth, err = strconv.Atoi(value)
+			if err != nil {
+				_ = c.Error(lib.NewInputError(errors.New(MessageBadInput)))
+				return
+			}
+		}
+		lineage, err := registry.GetPipelineLineage(id, c.GetString(UserIdKey), c.GetHeader(HeaderAuthorization), c.Query("direction"), depth)
+		if err != nil {
+			util.Logger.Error("could not get pipeline lineage", "error", err, "method", "GET", "path", "/pipeline/"+id+"/lineage")
+			_ = c.Error(handleError(err))
+			return
+		}
+		c.JSON(http.StatusOK, lineage)
+	}
+}
+
+// getPipelineRevisions returns a handler function for the "/pipeline/:id/revisions" endpoint that lists the revisions of a pipeline
+// @Summary Retrieve the revisions of a pipeline
+// @Description Lists all revisions of a pipeline, newest first, without their pipeline snapshots
+// @Tags pipelines
+// @Accept json
+// @Produce json
+// @Param id path string true "Pipeline ID"
+// @Success 200 {object} []lib.PipelineRevision
+// @Failure 401
+// @Failure 403 {string} MessageForbidden
+// @Failure 500 {string} MessageSomethingWrong
+// @Router /pipeline/:id/revisions [get]
+// @Security Bearer
+func getPipelineRevisions(registry service.Registry) (string, string, gin.HandlerFunc) {
+	return http.MethodGet, "/pipeline/:id/revisions", func(c *gin.Context) {
+		id := c.Param("id")
+		revisions, err := registry.GetPipelineRevisions(id, c.GetHeader(HeaderAuthorization))
+		if err != nil {
+			util.Logger.Error("could not get pipeline revisions", "error", err, "method", "GET", "path", "/pipeline/"+id+"/revisions")
+			_ = c.Error(handleError(err))
+			return
+		}
+		c.JSON(http.StatusOK, revisions)
+	}
+}
+
+// getPipelineRevision returns a handler function for the "/pipeline/:id/revisions/:revision" endpoint that retrieves a single revision of a pipeline
+// @Summary Retrieve a revision of a pipeline
+// @Description Retrieves a revision of a pipeline including its pipeline snapshot
+// @Tags pipelines
+// @Accept json
+// @Produce json
+// @Param id path string true "Pipeline ID"
+// @Param revision path int true "Revision"
+// @Success 200 {object} lib.PipelineRevision
+// @Failure 400 {string} MessageBadInput
+// @Failure 401
+// @Failure 403 {string} MessageForbidden
+// @Failure 404 {string} MessageNotFound
+// @Failure 500 {string} MessageSomethingWrong
+// @Router /pipeline/:id/revisions/:revision [get]
+// @Security Bearer
+func getPipelineRevision(registry service.Registry) (string, string, gin.HandlerFunc) {
+	return http.MethodGet, "/pipeline/:id/revisions/:revision", func(c *gin.Context) {
+		id := c.Param("id")
+		revision, err := parseRevision(c, "revision")
+		if err != nil {
+			_ = c.Error(err)
+			return
+		}
+		rev, err := registry.GetPipelineRevision(id, revision, c.GetHeader(HeaderAuthorization))
+		if err != nil {
+			util.Logger.Error("could not get pipeline revision", "error", err, "method", "GET", "path", "/pipeline/"+id+"/revisions/"+c.Param("revision"))
+			_ = c.Error(handleError(err))
+			return
+		}
+		c.JSON(http.StatusOK, rev)
+	}
+}
+
+// postPipelineRollback returns a handler function for the "/pipeline/:id/revisions/:revision/rollback" endpoint that rolls a pipeline back to a revision
+// @Summary Roll back a pipeline
+// @Description Restores the pipeline snapshot of a revision. The rollback is stored as a new revision.
+// @Tags pipelines
+// @Accept json
+// @Produce json
+// @Param id path string true "Pipeline ID"
+// @Param revision path int true "Revision"
+// @Success 200 {object} map[string]string "Pipeline ID"
+// @Failure 400 {object} lib.ValidationError
+// @Failure 401
+// @Failure 403 {string} MessageForbidden
+// @Failure 404 {string} MessageNotFound
+// @Failure 500 {string} MessageSomethingWrong
+// @Router /pipeline/:id/revisions/:revision/rollback [post]
+// @Security Bearer
+func postPipelineRollback(registry service.Registry) (string, string, gin.HandlerFunc) {
+	return http.MethodPost, "/pipeline/:id/revisions/:revision/rollback", func(c *gin.Context) {
+		id := c.Param("id")
+		revision, err := parseRevision(c, "revision")
+		if err != nil {
+			_ = c.Error(err)
+			return
+		}
+		err = registry.RollbackPipeline(id, revision, c.GetString(UserIdKey), c.GetHeader(HeaderAuthorization))
+		if err != nil {
+			util.Logger.Error("could not roll back pipeline", "error", err, "method", "POST", "path", "/pipeline/"+id+"/revisions/"+c.Param("revision")+"/rollback")
+			_ = c.Error(handleError(err))
+			return
+		}
+		c.JSON(http.StatusOK, gin.H{"id": id})
+	}
+}
+
+// getPipelineDiff returns a handler function for the "/pipeline/:id/diff" endpoint that compares two revisions of a pipeline
+// @Summary Compare two revisions of a pipeline
+// @Description Reports added, removed and changed fields of the pipeline, its operators (matched by id) and their input topics. If to is omitted, the current pipeline is used. With format=text a plain-text unified view is returned.
+// @Tags pipelines
+// @Accept json
+// @Produce json,plain
+// @Param id path string true "Pipeline ID"
+// @Param from query int true "Revision to compare from"
+// @Param to query int false "Revision to compare to"
+// @Param format query string false "json (default) or text"
+// @Success 200 {object} lib.PipelineDiff
+// @Failure 400 {string} MessageBadInput
+// @Failure 401
+// @Failure 403 {string} MessageForbidden
+// @Failure 404 {string} MessageNotFound
+// @Failure 500 {string} MessageSomethingWrong
+// @Router /pipeline/:id/diff [get]
+// @Security Bearer
+func getPipelineDiff(registry service.Registry) (string, string, gin.HandlerFunc) {
+	return http.MethodGet, "/pipeline/:id/diff", func(c *gin.Context) {
+		id := c.Param("id")
+		from, err := strconv.Atoi(c.Query("from"))
+		if err != nil || from < 1 {
+			_ = c.Error(lib.NewInputError(errors.New(MessageBadInput)))
+			return
+		}
+		to := 0
+		if c.Query("to") != "" {
+			to, err = strconv.Atoi(c.Query("to"))
+			if err != nil || to < 1 {
+				_ = c.Error(lib.NewInputError(errors.New(MessageBadInput)))
+				return
+			}
+		}
+		diff, err := registry.DiffPipeline(id, from, to, c.GetString(UserIdKey), c.GetHeader(HeaderAuthorization))
+		if err != nil {
+			util.Logger.Error("could not diff pipeline", "error", err, "method", "GET", "path", "/pipeline/"+id+"/diff")
+			_ = c.Error(handleError(err))
+			return
+		}
+		if c.Query("format") == "text" {
+			c.String(http.StatusOK, diff.Text())
+			return
+		}
+		c.JSON(http.StatusOK, diff)
+	}
+}
+
+// postPipelineStatus returns a handler function for the "/pipeline/:id/status" endpoint that records a status transition of a pipeline
+// @Summary Report a pipeline status
+// @Description Records a status transition of a pipeline. Allowed transitions are draft -> deploying|deleting, deploying -> running|failed|stopped, running -> stopped|failed|deploying, stopped -> deploying|deleting, failed -> deploying|stopped|deleting and deleting -> failed.
+// @Tags pipelines
+// @Accept json
+// @Produce json
+// @Param id path string true "Pipeline ID"
+// @Param request body lib.StatusUpdate true "Status update"
+// @Success 200 {object} lib.Pipeline
+// @Failure 400 {string} MessageBadInput
+// @Failure 401
+// @Failure 403 {string} MessageForbidden
+// @Failure 404 {string} MessageNotFound
+// @Failure 409 {string} service.MessageStatusTransitionNotAllowed
+// @Failure 500 {string} MessageSomethingWrong
+// @Router /pipeline/:id/status [post]
+// @Security Bearer
+func postPipelineStatus(registry service.Registry) (string, string, gin.HandlerFunc) {
+	return http.MethodPost, "/pipeline/:id/status", func(c *gin.Context) {
+		id := c.Param("id")
+		var request lib.StatusUpdate
+		if err := c.ShouldBindJSON(&request); err != nil {
+			util.Logger.Error("error parsing request", "error", err, "method", "POST", "path", "/pipeline/"+id+"/status")
+			_ = c.Error(lib.NewInputError(errors.New(MessageBadInput)))
+			return
+		}
+		pipe, err := registry.UpdatePipelineStatus(id, request, c.GetString(UserIdKey), c.GetHeader(HeaderAuthorization))
+		if err != nil {
+			util.Logger.Error("could not update pipeline status", "error", err, "method", "POST", "path", "/pipeline/"+id+"/status")
+			_ = c.Error(handleError(err))
+			return
+		}
 		c.JSON(http.StatusOK, pipe)
 	}
 }
 
 // deletePipeline returns a handler function for the "/pipeline/:id" endpoint that deletes a pipeline
 // @Summary Delete a pipeline
-// @Description Deletes a pipeline given a pipeline ID
+// @Description Moves a pipeline to the trash given a pipeline ID. Trashed pipelines keep their permissions and can be restored until they are purged.
 // @Tags pipelines
 // @Accept json
 // @Produce json
 // @Param id path string true "Pipeline ID"
+// @Param checkDownstream query bool false "Refuse to delete pipelines consumed by other pipelines"
 // @Success 200
+// @Failure 400 {string} MessageBadInput
 // @Failure 401
 // @Failure 403 {string} MessageForbidden
 // @Failure 404 {string} MessageNotFound
+// @Failure 409 {string} service.MessageDownstreamPipelines
 // @Failure 500 {string} MessageSomethingWrong
 // @Router /pipeline/:id [delete]
 // @Security Bearer
 func deletePipeline(registry service.Registry) (string, string, gin.HandlerFunc) {
 	return http.MethodDelete, "/pipeline/:id", func(c *gin.Context) {
 		id := c.Param("id")
-		err := registry.DeletePipeline(id, c.GetString(UserIdKey), c.GetHeader(HeaderAuthorization))
+		checkDownstream := false
+		if value := c.Query("checkDownstream"); value != "" {
+			var err error
+			checkDownstream, err = strconv.ParseBool(value)
+			if err != nil {
+				_ = c.Error(lib.NewInputError(errors.New(MessageBadInput)))
+				return
+			}
+		}
+		err := registry.DeletePipeline(id, c.GetString(UserIdKey), c.GetHeader(HeaderAuthorization), checkDownstream)
 		if err != nil {
 			util.Logger.Error("could not delete pipeline", "error", err, "method", "DELETE", "path", "/pipeline/"+id, "userId", c.GetString(UserIdKey))
 			_ = c.Error(handleError(err))
@@ -145,14 +714,130 @@ func deletePipeline(registry service.Registry) (string, string, gin.HandlerFunc)
 	}
 }
 
+// getTrashedPipelines returns a handler function for the "/pipeline/trash" endpoint that retrieves a list of trashed pipelines
+// @Summary Retrieve a list of trashed pipelines
+// @Description Retrieves the trashed pipelines the user may restore given a set of query parameters
+// @Tags pipelines
+// @Accept json
+// @Produce json
+// @Param query query string false "Query parameters"
+// @Success 200 {object} lib.PipelinesResponse
+// @Failure 401
+// @Failure 500 {string} MessageSomethingWrong
+// @Router /pipeline/trash [get]
+// @Security Bearer
+func getTrashedPipelines(registry service.Registry) (string, string, gin.HandlerFunc) {
+	return http.MethodGet, "/pipeline/trash", func(c *gin.Context) {
+		args := c.Request.URL.Query()
+		pipes, err := registry.GetTrashedPipelines(c.GetString(UserIdKey), args, c.GetHeader(HeaderAuthorization))
+		if err != nil {
+			util.Logger.Error("could not get trashed pipelines", "error", err, "method", "GET", "path", "/pipeline/trash")
+			_ = c.Error(handleError(err))
+			return
+		}
+		c.JSON(http.StatusOK, pipes)
+	}
+}
+
+// getPipelineEvents returns a handler function for the "/pipeline/events" endpoint that streams pipeline changes
+// @Summary Stream pipeline changes
+// @Description Streams created, updated and deleted notifications of all pipelines the user may read as server-sent events.
+// @Description The SSE id of an event can be sent as Last-Event-ID header or lastEventId query parameter to resume a stream.
+// @Description Ids are only known to the instance that sent them and only while the event is in its history, otherwise the stream is rejected and the pipelines have to be reloaded.
+// @Tags pipelines
+// @Produce text/event-stream
+// @Param Last-Event-ID header string false "Id of the last received event"
+// @Param lastEventId query string false "Id of the last received event"
+// @Success 200 {object} lib.PipelineEvent
+// @Failure 401
+// @Failure 409 {string} service.MessageUnknownEventId
+// @Failure 500 {string} MessageSomethingWrong
+// @Router /pipeline/events [get]
+// @Security Bearer
+func getPipelineEvents(registry service.Registry) (string, string, gin.HandlerFunc) {
+	return http.MethodGet, "/pipeline/events", func(c *gin.Context) {
+		lastEventId := c.GetHeader(HeaderLastEventID)
+		if lastEventId == "" {
+			lastEventId = c.Query("lastEventId")
+		}
+		events, err := registry.SubscribePipelineEvents(c.Request.Context(), c.GetHeader(HeaderAuthorization), lastEventId)
+		if err != nil {
+			util.Logger.Error("could not subscribe to pipeline events", "error", err, "method", "GET", "path", "/pipeline/events")
+			_ = c.Error(handleError(err))
+			return
+		}
+		c.Header("Content-Type", "text/event-stream")
+		c.Header("Cache-Control", "no-cache")
+		c.Header("Connection", "keep-alive")
+		c.Header("X-Accel-Buffering", "no")
+		c.Status(http.StatusOK)
+		c.Writer.Flush()
+		heartbeat := time.NewTicker(EventStreamHeartbeat)
+		defer heartbeat.Stop()
+		for {
+			select {
+			case <-c.Request.Context().Done():
+				return
+			case <-heartbeat.C:
+				_, err = c.Writer.WriteString(": heartbeat\n\n")
+			case event, ok := <-events:
+				if !ok {
+					return
+				}
+				err = writeServerSentEvent(c.Writer, event)
+			}
+			if err != nil {
+				return
+			}
+			c.Writer.Flush()
+		}
+	}
+}
+
+// postRestorePipeline returns a handler function for the "/pipeline/:id/restore" endpoint that restores a trashed pipeline
+// @Summary Restore a pipeline
+// @Description Restores a trashed pipeline given a pipeline ID
+// @Tags pipelines
+// @Accept json
+// @Produce json
+// @Param id path string true "Pipeline ID"
+// @Success 200
+// @Failure 401
+// @Failure 403 {string} MessageForbidden
+// @Failure 404 {string} MessageNotFound
+// @Failure 500 {string} MessageSomethingWrong
+// @Router /pipeline/:id/restore [post]
+// @Security Bearer
+func postRestorePipeline(registry service.Registry) (string, string, gin.HandlerFunc) {
+	return http.MethodPost, "/pipeline/:id/restore", func(c *gin.Context) {
+		id := c.Param("id")
+		err := registry.RestorePipeline(id, c.GetString(UserIdKey), c.GetHeader(HeaderAuthorization))
+		if err != nil {
+			util.Logger.Error("could not restore pipeline", "error", err, "method", "POST", "path", "/pipeline/"+id+"/restore")
+			_ = c.Error(handleError(err))
+			return
+		}
+		c.Status(http.StatusOK)
+	}
+}
+
 // getPipelines returns a handler function for the "/pipeline" endpoint that retrieves a list of pipelines
 // @Summary Retrieve a list of pipelines
-// @Description Retrieves a list of pipelines given a set of query parameters
+// @Description Retrieves a list of pipelines given a set of query parameters.
+// @Description Pages can be walked with limit and offset or with the nextCursor of the previous page as cursor, which stays stable while pipelines are added.
 // @Tags pipelines
 // @Accept json
 // @Produce json
 // @Param query query string false "Query parameters"
-// @Success 200 {object} []lib.Pipeline
+// @Param status query string false "Comma separated list of pipeline states"
+// @Param limit query int false "Page size"
+// @Param offset query int false "Number of pipelines to skip, ignored if cursor is set"
+// @Param order query string false "Sort field and direction, e.g. name:asc"
+// @Param cursor query string false "nextCursor of the previous page"
+// @Param search query string false "Full-text search over name, description, operator names, config values and topic names. Results are ranked by relevance unless order is set, matches are returned as highlights"
+// @Param filter query string false "Filter expression, e.g. (operator:adder or flow:f1) and not metrics:true and createdAt>=2025-01-01. Fields: name, owner, status, flow, image, mergeStrategy, metrics, consumeAllMessages, createdAt, updatedAt, operator, deploymentType, persistData, inputTopic"
+// @Success 200 {object} lib.PipelinesResponse
+// @Failure 400 {string} MessageBadInput
 // @Failure 401
 // @Failure 500 {string} MessageSomethingWrong
 // @Router /pipeline [get]
@@ -250,6 +935,66 @@ func getFlowUsageById(registry service.Registry) (string, string, gin.HandlerFun
 	}
 }
 
+// getTopicUsage returns a handler function for the "/pipeline/statistics/topicusage/:topic" endpoint that checks if a topic is consumed by pipelines
+// @Summary Retrieve the pipelines consuming a topic
+// @Description Retrieves the readable pipelines and operators that consume a Kafka topic
+// @Tags pipelines
+// @Accept json
+// @Produce json
+// @Param topic path string true "Topic name"
+// @Success 200 {object} lib.TopicUsage
+// @Success 204
+// @Failure 401
+// @Failure 500 {string} MessageSomethingWrong
+// @Router /pipeline/statistics/topicusage/:topic [get]
+// @Security Bearer
+func getTopicUsage(registry service.Registry) (string, string, gin.HandlerFunc) {
+	return http.MethodGet, "/pipeline/statistics/topicusage/:topic", func(c *gin.Context) {
+		topic := c.Param("topic")
+		usage, err := registry.GetTopicUsage(topic, c.GetString(UserIdKey), c.GetHeader(HeaderAuthorization))
+		if err != nil {
+			util.Logger.Error("could not get TopicUsage statistics", "error", err, "method", "GET", "path", "/pipeline/statistics/topicusage/"+topic)
+			_ = c.Error(handleError(err))
+			return
+		}
+		if usage == nil {
+			c.Status(http.StatusNoContent)
+			return
+		}
+		c.JSON(http.StatusOK, usage)
+	}
+}
+
+// getSelectableUsage returns a handler function for the "/pipeline/statistics/selectableusage/:id" endpoint that checks if a device or device group is used by pipelines
+// @Summary Retrieve the pipelines using a device or device group
+// @Description Retrieves the readable pipelines and operators that reference a device or device group as input selection or DeviceId filter
+// @Tags pipelines
+// @Accept json
+// @Produce json
+// @Param id path string true "Device or device group ID"
+// @Success 200 {object} lib.SelectableUsage
+// @Success 204
+// @Failure 401
+// @Failure 500 {string} MessageSomethingWrong
+// @Router /pipeline/statistics/selectableusage/:id [get]
+// @Security Bearer
+func getSelectableUsage(registry service.Registry) (string, string, gin.HandlerFunc) {
+	return http.MethodGet, "/pipeline/statistics/selectableusage/:id", func(c *gin.Context) {
+		id := c.Param("id")
+		usage, err := registry.GetSelectableUsage(id, c.GetString(UserIdKey), c.GetHeader(HeaderAuthorization))
+		if err != nil {
+			util.Logger.Error("could not get SelectableUsage statistics", "error", err, "method", "GET", "path", "/pipeline/statistics/selectableusage/"+id)
+			_ = c.Error(handleError(err))
+			return
+		}
+		if usage == nil {
+			c.Status(http.StatusNoContent)
+			return
+		}
+		c.JSON(http.StatusOK, usage)
+	}
+}
+
 func deletePipelineAdmin(registry service.Registry) (string, string, gin.HandlerFunc) {
 	return http.MethodDelete, "/admin/pipeline/:id", func(c *gin.Context) {
 		id := c.Param("id")
@@ -263,6 +1008,51 @@ func deletePipelineAdmin(registry service.Registry) (string, string, gin.Handler
 	}
 }
 
+func postReconcileAdmin(registry service.Registry) (string, string, gin.HandlerFunc) {
+	return http.MethodPost, "/admin/pipeline/reconcile", func(c *gin.Context) {
+		dryRun := false
+		if value := c.Query("dryRun"); value != "" {
+			var err error
+			dryRun, err = strconv.ParseBool(value)
+			if err != nil {
+				_ = c.Error(lib.NewInputError(errors.New(MessageBadInput)))
+				return
+			}
+		}
+		report, err := registry.Reconcile(dryRun)
+		if err != nil {
+			util.Logger.Error("could not reconcile pipeline permissions for admin", "error", err, "method", "POST", "path", "/admin/pipeline/reconcile")
+			_ = c.Error(handleError(err))
+			return
+		}
+		c.JSON(http.StatusOK, report)
+	}
+}
+
+func getReconcileMetricsAdmin(registry service.Registry) (string, string, gin.HandlerFunc) {
+	return http.MethodGet, "/admin/pipeline/reconcile", func(c *gin.Context) {
+		c.JSON(http.StatusOK, registry.GetReconcileMetrics())
+	}
+}
+
+func postPipelinesTransferAdmin(registry service.Registry) (string, string, gin.HandlerFunc) {
+	return http.MethodPost, "/admin/pipeline/transfer", func(c *gin.Context) {
+		var request lib.PipelinesTransfer
+		if err := c.ShouldBindJSON(&request); err != nil {
+			util.Logger.Error("error parsing request", "error", err, "method", "POST", "path", "/admin/pipeline/transfer")
+			_ = c.Error(lib.NewInputError(errors.New(MessageBadInput)))
+			return
+		}
+		result, err := registry.TransferPipelinesAdmin(request, c.GetString(UserIdKey))
+		if err != nil {
+			util.Logger.Error("could not transfer pipelines for admin", "error", err, "method", "POST", "path", "/admin/pipeline/transfer")
+			_ = c.Error(handleError(err))
+			return
+		}
+		c.JSON(http.StatusOK, result)
+	}
+}
+
 func getHealthCheckH(_ service.Registry) (string, string, gin.HandlerFunc) {
 	return http.MethodGet, HealthCheckPath, func(c *gin.Context) {
 		c.Status(http.StatusOK)
@@ -17,16 +17,33 @@
 package api
 
 import (
+	"encoding/json"
 	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"strconv"
+	"strings"
 
 	"github.com/SENERGY-Platform/analytics-pipeline/lib"
+	"github.com/SENERGY-Platform/analytics-pipeline/pkg/service"
+	"github.com/gin-gonic/gin"
 	"go.mongodb.org/mongo-driver/mongo"
 )
 
 func handleError(err error) error {
 	var ie *lib.ForbiddenError
+	var pe *lib.InputError
+	var ce *lib.ConflictError
+	var pfe *lib.PreconditionFailedError
 	if errors.Is(err, mongo.ErrNoDocuments) {
 		err = lib.NewNotFoundError(errors.New(MessageNotFound))
+	} else if errors.As(err, &pe) {
+		return pe
+	} else if errors.As(err, &ce) {
+		return ce
+	} else if errors.As(err, &pfe) {
+		return pfe
 	} else if errors.As(err, &ie) {
 		err = lib.NewForbiddenError(errors.New(MessageForbidden))
 	} else {
@@ -34,3 +51,56 @@ func handleError(err error) error {
 	}
 	return err
 }
+
+// ValidationErrorHandler renders validation errors as JSON with their violations.
+// It has to be registered after gin_mw.ErrorHandler, which renders all other errors as plain text
+// and skips aborted requests.
+func ValidationErrorHandler() gin.HandlerFunc {
+	return func(gc *gin.Context) {
+		gc.Next()
+		if gc.IsAborted() {
+			return
+		}
+		for _, err := range gc.Errors {
+			var ve *lib.ValidationError
+			if errors.As(err.Err, &ve) {
+				gc.AbortWithStatusJSON(http.StatusBadRequest, ve)
+				return
+			}
+		}
+	}
+}
+
+func etag(version int) string {
+	return "\"" + strconv.Itoa(version) + "\""
+}
+
+// parseIfMatch returns the version of an If-Match header or nil if the header is empty or "*".
+func parseIfMatch(header string) (*int, error) {
+	header = strings.TrimPrefix(strings.TrimSpace(header), "W/")
+	if header == "" || header == "*" {
+		return nil, nil
+	}
+	version, err := strconv.Atoi(strings.Trim(header, "\""))
+	if err != nil {
+		return nil, lib.NewInputError(errors.New(MessageBadInput))
+	}
+	return &version, nil
+}
+
+func parseRevision(c *gin.Context, param string) (revision int, err error) {
+	revision, err = strconv.Atoi(c.Param(param))
+	if err != nil || revision < 1 {
+		return revision, lib.NewInputError(errors.New(MessageBadInput))
+	}
+	return
+}
+
+func writeServerSentEvent(w io.Writer, event service.StreamEvent) error {
+	data, err := json.Marshal(event.Event)
+	if err != nil {
+		return err
+	}
+	_, err = fmt.Fprintf(w, "id: %s\nevent: %s\ndata: %s\n\n", event.Id(), event.Event.Type, data)
+	return err
+}
@@ -29,10 +29,44 @@ var routes = gin_mw.Routes[service.Registry]{
 var routesAuth = gin_mw.Routes[service.Registry]{
 	postPipeline,
 	putPipeline,
+	patchPipeline,
 	getPipeline,
+	getPipelineGraph,
+	getPipelineLineage,
+	getPipelineExport,
+	postPipelineImport,
+	postPipelineClone,
+	postPipelineBatch,
+	getPipelinePermissions,
+	putPipelinePermissions,
+	postPipelineTransfer,
+	getPipelineRevisions,
+	getPipelineRevision,
+	postPipelineRollback,
+	postPipelineStatus,
+	getPipelineDiff,
 	deletePipeline,
+	getTrashedPipelines,
+	getPipelineEvents,
+	postRestorePipeline,
 	getPipelines,
 	getFlowUsageById,
+	getTopicUsage,
+	getSelectableUsage,
+	postWebhook,
+	getWebhooks,
+	getWebhook,
+	deleteWebhook,
+	getWebhookDeliveries,
+	getWebhookDeadLetters,
+	postWebhookRedeliver,
+	postTemplate,
+	getTemplates,
+	getTemplate,
+	putTemplate,
+	deleteTemplate,
+	postTemplateInstantiate,
+	postPipelineRender,
 }
 
 var routesAdmin = gin_mw.Routes[service.Registry]{
@@ -41,4 +75,7 @@ var routesAdmin = gin_mw.Routes[service.Registry]{
 	getPipelineUserCountAdmin,
 	getOperatorUsageAdmin,
 	getFlowUsageAdmin,
+	postReconcileAdmin,
+	getReconcileMetricsAdmin,
+	postPipelinesTransferAdmin,
 }
@@ -0,0 +1,241 @@
+/*
+ * Copyright 2025 InfAI (CC SES)
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *      http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package api
+
+import (
+	"errors"
+	"net/http"
+
+	"github.com/SENERGY-Platform/analytics-pipeline/lib"
+	"github.com/SENERGY-Platform/analytics-pipeline/pkg/service"
+	"github.com/SENERGY-Platform/analytics-pipeline/pkg/util"
+	"github.com/gin-gonic/gin"
+)
+
+// postTemplate returns a handler function for the "/template" endpoint that creates a pipeline template
+// @Summary Create a template
+// @Description Creates a pipeline template. Operator config, input topics and input selections of the pipeline may contain ${param} placeholders, which have to be declared in params.
+// @Description Parameter types are string, number, integer and boolean. Parameters without default are required on instantiation.
+// @Tags templates
+// @Accept json
+// @Produce json
+// @Param request body lib.PipelineTemplate true "Pipeline template"
+// @Success 200 {object} lib.PipelineTemplate
+// @Failure 400 {object} lib.ValidationError
+// @Failure 401
+// @Failure 500 {string} MessageSomethingWrong
+// @Router /template [post]
+// @Security Bearer
+func postTemplate(registry service.Registry) (string, string, gin.HandlerFunc) {
+	return http.MethodPost, TemplatePath, func(c *gin.Context) {
+		var request lib.PipelineTemplate
+		if err := c.ShouldBindJSON(&request); err != nil {
+			util.Logger.Error("error parsing request", "error", err, "method", "POST", "path", TemplatePath)
+			_ = c.Error(lib.NewInputError(errors.New(MessageBadInput)))
+			return
+		}
+		template, err := registry.CreateTemplate(request, c.GetString(UserIdKey))
+		if err != nil {
+			util.Logger.Error("could not create template", "error", err, "method", "POST", "path", TemplatePath)
+			_ = c.Error(handleError(err))
+			return
+		}
+		c.JSON(http.StatusOK, template)
+	}
+}
+
+// getTemplates returns a handler function for the "/template" endpoint that lists the templates of the user
+// @Summary List templates
+// @Description Lists the pipeline templates of the user
+// @Tags templates
+// @Produce json
+// @Success 200 {array} lib.PipelineTemplate
+// @Failure 401
+// @Failure 500 {string} MessageSomethingWrong
+// @Router /template [get]
+// @Security Bearer
+func getTemplates(registry service.Registry) (string, string, gin.HandlerFunc) {
+	return http.MethodGet, TemplatePath, func(c *gin.Context) {
+		templates, err := registry.GetTemplates(c.GetString(UserIdKey))
+		if err != nil {
+			util.Logger.Error("could not get templates", "error", err, "method", "GET", "path", TemplatePath)
+			_ = c.Error(handleError(err))
+			return
+		}
+		c.JSON(http.StatusOK, templates)
+	}
+}
+
+// getTemplate returns a handler function for the "/template/:id" endpoint that retrieves a template
+// @Summary Retrieve a template
+// @Description Retrieves a pipeline template of the user
+// @Tags templates
+// @Produce json
+// @Param id path string true "Template ID"
+// @Success 200 {object} lib.PipelineTemplate
+// @Failure 401
+// @Failure 404 {string} MessageNotFound
+// @Failure 500 {string} MessageSomethingWrong
+// @Router /template/:id [get]
+// @Security Bearer
+func getTemplate(registry service.Registry) (string, string, gin.HandlerFunc) {
+	return http.MethodGet, TemplatePath + "/:id", func(c *gin.Context) {
+		id := c.Param("id")
+		template, err := registry.GetTemplate(id, c.GetString(UserIdKey))
+		if err != nil {
+			util.Logger.Error("could not get template", "error", err, "method", "GET", "path", TemplatePath+"/"+id)
+			_ = c.Error(handleError(err))
+			return
+		}
+		c.JSON(http.StatusOK, template)
+	}
+}
+
+// putTemplate returns a handler function for the "/template/:id" endpoint that updates a template
+// @Summary Update a template
+// @Description Replaces a pipeline template of the user and increments its version. A version sent with the template has to match the stored version.
+// @Description Pipelines created from the template keep the version they were rendered from until they are rendered again with POST /pipeline/:id/render.
+// @Tags templates
+// @Accept json
+// @Produce json
+// @Param id path string true "Template ID"
+// @Param request body lib.PipelineTemplate true "Pipeline template"
+// @Success 200 {object} lib.PipelineTemplate
+// @Failure 400 {object} lib.ValidationError
+// @Failure 401
+// @Failure 404 {string} MessageNotFound
+// @Failure 409 {string} db.MessageTemplateConflict
+// @Failure 500 {string} MessageSomethingWrong
+// @Router /template/:id [put]
+// @Security Bearer
+func putTemplate(registry service.Registry) (string, string, gin.HandlerFunc) {
+	return http.MethodPut, TemplatePath + "/:id", func(c *gin.Context) {
+		id := c.Param("id")
+		var request lib.PipelineTemplate
+		if err := c.ShouldBindJSON(&request); err != nil {
+			util.Logger.Error("error parsing request", "error", err, "method", "PUT", "path", TemplatePath+"/"+id)
+			_ = c.Error(lib.NewInputError(errors.New(MessageBadInput)))
+			return
+		}
+		request.Id = id
+		template, err := registry.UpdateTemplate(request, c.GetString(UserIdKey))
+		if err != nil {
+			util.Logger.Error("could not update template", "error", err, "method", "PUT", "path", TemplatePath+"/"+id)
+			_ = c.Error(handleError(err))
+			return
+		}
+		c.JSON(http.StatusOK, template)
+	}
+}
+
+// deleteTemplate returns a handler function for the "/template/:id" endpoint that removes a template
+// @Summary Delete a template
+// @Description Deletes a pipeline template of the user. Pipelines created from it are kept, but can no longer be rendered again.
+// @Tags templates
+// @Param id path string true "Template ID"
+// @Success 204
+// @Failure 401
+// @Failure 404 {string} MessageNotFound
+// @Failure 500 {string} MessageSomethingWrong
+// @Router /template/:id [delete]
+// @Security Bearer
+func deleteTemplate(registry service.Registry) (string, string, gin.HandlerFunc) {
+	return http.MethodDelete, TemplatePath + "/:id", func(c *gin.Context) {
+		id := c.Param("id")
+		err := registry.DeleteTemplate(id, c.GetString(UserIdKey))
+		if err != nil {
+			util.Logger.Error("could not delete template", "error", err, "method", "DELETE", "path", TemplatePath+"/"+id)
+			_ = c.Error(handleError(err))
+			return
+		}
+		c.Status(http.StatusNoContent)
+	}
+}
+
+// postTemplateInstantiate returns a handler function for the "/template/:id/instantiate" endpoint that creates a pipeline from a template
+// @Summary Instantiate a template
+// @Description Creates a pipeline from a template of the user by replacing the placeholders with the given values or the parameter defaults.
+// @Description The pipeline references the template version and the values, so that it can be rendered again with POST /pipeline/:id/render.
+// @Tags templates
+// @Accept json
+// @Produce json
+// @Param id path string true "Template ID"
+// @Param request body lib.TemplateInstantiation true "Parameter values"
+// @Success 200 {object} map[string]string "Pipeline ID"
+// @Failure 400 {object} lib.ValidationError
+// @Failure 401
+// @Failure 404 {string} MessageNotFound
+// @Failure 500 {string} MessageSomethingWrong
+// @Router /template/:id/instantiate [post]
+// @Security Bearer
+func postTemplateInstantiate(registry service.Registry) (string, string, gin.HandlerFunc) {
+	return http.MethodPost, TemplatePath + "/:id/instantiate", func(c *gin.Context) {
+		id := c.Param("id")
+		var request lib.TemplateInstantiation
+		if err := c.ShouldBindJSON(&request); err != nil {
+			util.Logger.Error("error parsing request", "error", err, "method", "POST", "path", TemplatePath+"/"+id+"/instantiate")
+			_ = c.Error(lib.NewInputError(errors.New(MessageBadInput)))
+			return
+		}
+		pipelineId, err := registry.InstantiateTemplate(id, request, c.GetString(UserIdKey), c.GetHeader(HeaderAuthorization))
+		if err != nil {
+			util.Logger.Error("could not instantiate template", "error", err, "method", "POST", "path", TemplatePath+"/"+id+"/instantiate")
+			_ = c.Error(handleError(err))
+			return
+		}
+		c.JSON(http.StatusOK, gin.H{"id": pipelineId})
+	}
+}
+
+// postPipelineRender returns a handler function for the "/pipeline/:id/render" endpoint that renders a pipeline again from its template
+// @Summary Render a pipeline from its template
+// @Description Renders a pipeline created from a template again with the current template version. The stored values are used unless overridden in the request, the pipeline name is kept.
+// @Tags templates
+// @Accept json
+// @Produce json
+// @Param id path string true "Pipeline ID"
+// @Param request body lib.TemplateInstantiation false "Parameter values to override, name is ignored"
+// @Success 200 {object} lib.Pipeline
+// @Failure 400 {object} lib.ValidationError
+// @Failure 401
+// @Failure 403 {string} MessageForbidden
+// @Failure 404 {string} MessageNotFound
+// @Failure 409 {string} db.MessageVersionConflict
+// @Failure 500 {string} MessageSomethingWrong
+// @Router /pipeline/:id/render [post]
+// @Security Bearer
+func postPipelineRender(registry service.Registry) (string, string, gin.HandlerFunc) {
+	return http.MethodPost, "/pipeline/:id/render", func(c *gin.Context) {
+		id := c.Param("id")
+		var request lib.TemplateInstantiation
+		if c.Request.ContentLength != 0 {
+			if err := c.ShouldBindJSON(&request); err != nil {
+				util.Logger.Error("error parsing request", "error", err, "method", "POST", "path", "/pipeline/"+id+"/render")
+				_ = c.Error(lib.NewInputError(errors.New(MessageBadInput)))
+				return
+			}
+		}
+		pipeline, err := registry.RenderPipeline(id, request.Values, c.GetString(UserIdKey), c.GetHeader(HeaderAuthorization))
+		if err != nil {
+			util.Logger.Error("could not render pipeline", "error", err, "method", "POST", "path", "/pipeline/"+id+"/render")
+			_ = c.Error(handleError(err))
+			return
+		}
+		c.Header(HeaderETag, etag(pipeline.Version))
+		c.JSON(http.StatusOK, pipeline)
+	}
+}
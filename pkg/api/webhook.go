@@ -0,0 +1,206 @@
+/*
+ * Copyright 2025 InfAI (CC SES)
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *      http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package api
+
+import (
+	"errors"
+	"net/http"
+
+	"github.com/SENERGY-Platform/analytics-pipeline/lib"
+	"github.com/SENERGY-Platform/analytics-pipeline/pkg/service"
+	"github.com/SENERGY-Platform/analytics-pipeline/pkg/util"
+	"github.com/gin-gonic/gin"
+)
+
+// postWebhook returns a handler function for the "/webhook" endpoint that registers a webhook subscription
+// @Summary Register a webhook
+// @Description Registers a URL that receives created, updated and deleted events of pipelines the user may read as JSON POST requests.
+// @Description Requests are signed with the secret, the X-Pipeline-Signature header contains "sha256=" followed by the hex encoded HMAC-SHA256 of the body.
+// @Description Failed deliveries are retried with exponential backoff and end up in the dead letters of the subscription.
+// @Tags webhooks
+// @Accept json
+// @Produce json
+// @Param request body lib.WebhookSubscription true "Webhook subscription"
+// @Success 200 {object} lib.WebhookSubscription
+// @Failure 400 {string} MessageBadInput
+// @Failure 401
+// @Failure 500 {string} MessageSomethingWrong
+// @Router /webhook [post]
+// @Security Bearer
+func postWebhook(registry service.Registry) (string, string, gin.HandlerFunc) {
+	return http.MethodPost, WebhookPath, func(c *gin.Context) {
+		var request lib.WebhookSubscription
+		if err := c.ShouldBindJSON(&request); err != nil {
+			util.Logger.Error("error parsing request", "error", err, "method", "POST", "path", WebhookPath)
+			_ = c.Error(lib.NewInputError(errors.New(MessageBadInput)))
+			return
+		}
+		subscription, err := registry.CreateWebhook(request, c.GetString(UserIdKey), c.GetHeader(HeaderAuthorization))
+		if err != nil {
+			util.Logger.Error("could not create webhook", "error", err, "method", "POST", "path", WebhookPath)
+			_ = c.Error(handleError(err))
+			return
+		}
+		c.JSON(http.StatusOK, subscription)
+	}
+}
+
+// getWebhooks returns a handler function for the "/webhook" endpoint that lists the webhook subscriptions of the user
+// @Summary List webhooks
+// @Description Lists the webhook subscriptions of the user, secrets are omitted
+// @Tags webhooks
+// @Produce json
+// @Success 200 {array} lib.WebhookSubscription
+// @Failure 401
+// @Failure 500 {string} MessageSomethingWrong
+// @Router /webhook [get]
+// @Security Bearer
+func getWebhooks(registry service.Registry) (string, string, gin.HandlerFunc) {
+	return http.MethodGet, WebhookPath, func(c *gin.Context) {
+		subscriptions, err := registry.GetWebhooks(c.GetString(UserIdKey))
+		if err != nil {
+			util.Logger.Error("could not get webhooks", "error", err, "method", "GET", "path", WebhookPath)
+			_ = c.Error(handleError(err))
+			return
+		}
+		c.JSON(http.StatusOK, subscriptions)
+	}
+}
+
+// getWebhook returns a handler function for the "/webhook/:id" endpoint that retrieves a webhook subscription
+// @Summary Retrieve a webhook
+// @Description Retrieves a webhook subscription of the user, the secret is omitted
+// @Tags webhooks
+// @Produce json
+// @Param id path string true "Webhook ID"
+// @Success 200 {object} lib.WebhookSubscription
+// @Failure 401
+// @Failure 404 {string} MessageNotFound
+// @Failure 500 {string} MessageSomethingWrong
+// @Router /webhook/:id [get]
+// @Security Bearer
+func getWebhook(registry service.Registry) (string, string, gin.HandlerFunc) {
+	return http.MethodGet, WebhookPath + "/:id", func(c *gin.Context) {
+		id := c.Param("id")
+		subscription, err := registry.GetWebhook(id, c.GetString(UserIdKey))
+		if err != nil {
+			util.Logger.Error("could not get webhook", "error", err, "method", "GET", "path", WebhookPath+"/"+id)
+			_ = c.Error(handleError(err))
+			return
+		}
+		c.JSON(http.StatusOK, subscription)
+	}
+}
+
+// deleteWebhook returns a handler function for the "/webhook/:id" endpoint that removes a webhook subscription
+// @Summary Delete a webhook
+// @Description Deletes a webhook subscription of the user together with its delivery log and dead letters
+// @Tags webhooks
+// @Param id path string true "Webhook ID"
+// @Success 204
+// @Failure 401
+// @Failure 404 {string} MessageNotFound
+// @Failure 500 {string} MessageSomethingWrong
+// @Router /webhook/:id [delete]
+// @Security Bearer
+func deleteWebhook(registry service.Registry) (string, string, gin.HandlerFunc) {
+	return http.MethodDelete, WebhookPath + "/:id", func(c *gin.Context) {
+		id := c.Param("id")
+		err := registry.DeleteWebhook(id, c.GetString(UserIdKey))
+		if err != nil {
+			util.Logger.Error("could not delete webhook", "error", err, "method", "DELETE", "path", WebhookPath+"/"+id)
+			_ = c.Error(handleError(err))
+			return
+		}
+		c.Status(http.StatusNoContent)
+	}
+}
+
+// getWebhookDeliveries returns a handler function for the "/webhook/:id/deliveries" endpoint that retrieves the delivery log
+// @Summary Retrieve the delivery log of a webhook
+// @Description Retrieves the latest delivery attempts of a webhook subscription, newest first
+// @Tags webhooks
+// @Produce json
+// @Param id path string true "Webhook ID"
+// @Success 200 {array} lib.WebhookDelivery
+// @Failure 401
+// @Failure 404 {string} MessageNotFound
+// @Failure 500 {string} MessageSomethingWrong
+// @Router /webhook/:id/deliveries [get]
+// @Security Bearer
+func getWebhookDeliveries(registry service.Registry) (string, string, gin.HandlerFunc) {
+	return http.MethodGet, WebhookPath + "/:id/deliveries", func(c *gin.Context) {
+		id := c.Param("id")
+		deliveries, err := registry.GetWebhookDeliveries(id, c.GetString(UserIdKey))
+		if err != nil {
+			util.Logger.Error("could not get webhook deliveries", "error", err, "method", "GET", "path", WebhookPath+"/"+id+"/deliveries")
+			_ = c.Error(handleError(err))
+			return
+		}
+		c.JSON(http.StatusOK, deliveries)
+	}
+}
+
+// getWebhookDeadLetters returns a handler function for the "/webhook/:id/deadletters" endpoint that lists undeliverable events
+// @Summary List dead letters of a webhook
+// @Description Lists the events that could not be delivered to a webhook subscription after all attempts
+// @Tags webhooks
+// @Produce json
+// @Param id path string true "Webhook ID"
+// @Success 200 {array} lib.WebhookDeadLetter
+// @Failure 401
+// @Failure 404 {string} MessageNotFound
+// @Failure 500 {string} MessageSomethingWrong
+// @Router /webhook/:id/deadletters [get]
+// @Security Bearer
+func getWebhookDeadLetters(registry service.Registry) (string, string, gin.HandlerFunc) {
+	return http.MethodGet, WebhookPath + "/:id/deadletters", func(c *gin.Context) {
+		id := c.Param("id")
+		letters, err := registry.GetWebhookDeadLetters(id, c.GetString(UserIdKey))
+		if err != nil {
+			util.Logger.Error("could not get webhook dead letters", "error", err, "method", "GET", "path", WebhookPath+"/"+id+"/deadletters")
+			_ = c.Error(handleError(err))
+			return
+		}
+		c.JSON(http.StatusOK, letters)
+	}
+}
+
+// postWebhookRedeliver returns a handler function for the "/webhook/:id/deadletters/:letterId/redeliver" endpoint that retries a dead letter
+// @Summary Redeliver a dead letter
+// @Description Delivers the event of a dead letter again, with the usual retries. The letter is removed once the event is delivered.
+// @Tags webhooks
+// @Param id path string true "Webhook ID"
+// @Param letterId path string true "Dead letter ID"
+// @Success 202
+// @Failure 401
+// @Failure 404 {string} MessageNotFound
+// @Failure 500 {string} MessageSomethingWrong
+// @Router /webhook/:id/deadletters/:letterId/redeliver [post]
+// @Security Bearer
+func postWebhookRedeliver(registry service.Registry) (string, string, gin.HandlerFunc) {
+	return http.MethodPost, WebhookPath + "/:id/deadletters/:letterId/redeliver", func(c *gin.Context) {
+		id := c.Param("id")
+		err := registry.RedeliverWebhookDeadLetter(id, c.Param("letterId"), c.GetString(UserIdKey))
+		if err != nil {
+			util.Logger.Error("could not redeliver webhook dead letter", "error", err, "method", "POST", "path", WebhookPath+"/"+id+"/deadletters")
+			_ = c.Error(handleError(err))
+			return
+		}
+		c.Status(http.StatusAccepted)
+	}
+}
@@ -16,7 +16,11 @@
 
 package config
 
-import sb_config_hdl "github.com/SENERGY-Platform/go-service-base/config-hdl"
+import (
+	"time"
+
+	sb_config_hdl "github.com/SENERGY-Platform/go-service-base/config-hdl"
+)
 
 type LoggerConfig struct {
 	Level string `json:"level" env_var:"LOGGER_LEVEL"`
@@ -26,13 +30,39 @@ type MongoConfig struct {
 	Host string `json:"host" env_var:"MONGO"`
 	Port int    `json:"port" env_var:"MONGO_PORT"`
 }
+type KafkaConfig struct {
+	Bootstrap           string        `json:"bootstrap" env_var:"KAFKA_BOOTSTRAP"`
+	PipelineEventsTopic string        `json:"pipeline_events_topic" env_var:"KAFKA_PIPELINE_EVENTS_TOPIC"`
+	PublishInterval     time.Duration `json:"publish_interval" env_var:"KAFKA_PUBLISH_INTERVAL"`
+}
+
+type WebhookConfig struct {
+	MaxAttempts          int           `json:"max_attempts" env_var:"WEBHOOK_MAX_ATTEMPTS"`
+	InitialBackoff       time.Duration `json:"initial_backoff" env_var:"WEBHOOK_INITIAL_BACKOFF"`
+	Timeout              time.Duration `json:"timeout" env_var:"WEBHOOK_TIMEOUT"`
+	AllowedHosts         []string      `json:"allowed_hosts" env_var:"WEBHOOK_ALLOWED_HOSTS"`
+	DeniedHosts          []string      `json:"denied_hosts" env_var:"WEBHOOK_DENIED_HOSTS"`
+	AllowPrivateNetworks bool          `json:"allow_private_networks" env_var:"WEBHOOK_ALLOW_PRIVATE_NETWORKS"`
+}
+
+type JournalConfig struct {
+	RetryInterval  time.Duration `json:"retry_interval" env_var:"JOURNAL_RETRY_INTERVAL"`
+	InitialBackoff time.Duration `json:"initial_backoff" env_var:"JOURNAL_INITIAL_BACKOFF"`
+}
+
 type Config struct {
-	Logger           LoggerConfig `json:"logger" env_var:"LOGGER_CONFIG"`
-	ServerPort       int          `json:"server_port" env_var:"SERVER_PORT"`
-	Debug            bool         `json:"debug" env_var:"DEBUG"`
-	URLPrefix        string       `json:"url_prefix" env_var:"URL_PREFIX"`
-	Mongo            MongoConfig  `json:"mongo" env_var:"MONGO_CONFIG"`
-	PermissionsV2Url string       `json:"permissions_v2_url" env_var:"PERMISSIONS_V2_URL"`
+	Logger             LoggerConfig  `json:"logger" env_var:"LOGGER_CONFIG"`
+	ServerPort         int           `json:"server_port" env_var:"SERVER_PORT"`
+	Debug              bool          `json:"debug" env_var:"DEBUG"`
+	URLPrefix          string        `json:"url_prefix" env_var:"URL_PREFIX"`
+	Mongo              MongoConfig   `json:"mongo" env_var:"MONGO_CONFIG"`
+	PermissionsV2Url   string        `json:"permissions_v2_url" env_var:"PERMISSIONS_V2_URL"`
+	TrashRetention     time.Duration `json:"trash_retention" env_var:"TRASH_RETENTION"`
+	TrashPurgeInterval time.Duration `json:"trash_purge_interval" env_var:"TRASH_PURGE_INTERVAL"`
+	ReconcileInterval  time.Duration `json:"reconcile_interval" env_var:"RECONCILE_INTERVAL"`
+	Kafka              KafkaConfig   `json:"kafka" env_var:"KAFKA_CONFIG"`
+	Webhook            WebhookConfig `json:"webhook" env_var:"WEBHOOK_CONFIG"`
+	Journal            JournalConfig `json:"journal" env_var:"JOURNAL_CONFIG"`
 }
 
 func New(path string) (*Config, error) {
@@ -47,6 +77,22 @@ func New(path string) (*Config, error) {
 			Host: "localhost",
 			Port: 27017,
 		},
+		TrashRetention:     30 * 24 * time.Hour,
+		TrashPurgeInterval: time.Hour,
+		ReconcileInterval:  time.Hour,
+		Kafka: KafkaConfig{
+			PipelineEventsTopic: "pipelines",
+			PublishInterval:     time.Second,
+		},
+		Webhook: WebhookConfig{
+			MaxAttempts:    5,
+			InitialBackoff: 5 * time.Second,
+			Timeout:        10 * time.Second,
+		},
+		Journal: JournalConfig{
+			RetryInterval:  30 * time.Second,
+			InitialBackoff: 10 * time.Second,
+		},
 	}
 	err := sb_config_hdl.Load(&cfg, nil, envTypeParser, nil, path)
 	return &cfg, err
@@ -0,0 +1,143 @@
+/*
+ * Copyright 2025 InfAI (CC SES)
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *      http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package db
+
+import (
+	"errors"
+	"time"
+
+	"github.com/SENERGY-Platform/analytics-pipeline/lib"
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/mongo"
+	"go.mongodb.org/mongo-driver/mongo/options"
+)
+
+// PipelineBulk collects the writes of BulkWritePipelines. Updates replace the pipeline like
+// UpdatePipeline, Trash marks pipelines as deleted like TrashPipeline.
+type PipelineBulk struct {
+	Insert []lib.Pipeline
+	Update []PipelineUpdate
+	Trash  []string
+}
+
+type PipelineUpdate struct {
+	Pipeline        lib.Pipeline
+	ExpectedVersion int
+}
+
+func (r *MongoRepo) FindPipelines(ids []string) (pipelines []lib.Pipeline, err error) {
+	cur, err := Mongo().Find(CTX, bson.M{"id": bson.M{"$in": ids}, "deletedat": nil})
+	if err != nil {
+		return
+	}
+	pipelines = make([]lib.Pipeline, 0)
+	err = cur.All(CTX, &pipelines)
+	return
+}
+
+// BulkWritePipelines applies all writes with a single unordered bulk write. Writes that failed
+// or did not match, like updates of a modified pipeline, are returned per pipeline id.
+func (r *MongoRepo) BulkWritePipelines(bulk PipelineBulk) (failed map[string]error, err error) {
+	failed = map[string]error{}
+	var models []mongo.WriteModel
+	var ids []string
+	for _, pipeline := range bulk.Insert {
+		doc, err := pipelineDocument(pipeline)
+		if err != nil {
+			return nil, err
+		}
+		models = append(models, mongo.NewInsertOneModel().SetDocument(doc))
+		ids = append(ids, pipeline.Id)
+	}
+	for _, update := range bulk.Update {
+		set, err := pipelineDocument(update.Pipeline)
+		if err != nil {
+			return nil, err
+		}
+		delete(set, "status")
+		delete(set, "statustransitions")
+		delete(set, "userid")
+		models = append(models, mongo.NewUpdateOneModel().
+			SetFilter(versionFilter(update.Pipeline.Id, update.ExpectedVersion)).
+			SetUpdate(bson.M{"$set": set}))
+		ids = append(ids, update.Pipeline.Id)
+	}
+	now := time.Now()
+	for _, id := range bulk.Trash {
+		models = append(models, mongo.NewUpdateOneModel().
+			SetFilter(bson.M{"id": id, "deletedat": nil}).
+			SetUpdate(bson.M{"$set": bson.M{"deletedat": now}}))
+		ids = append(ids, id)
+	}
+	if len(models) == 0 {
+		return
+	}
+	res, err := Mongo().BulkWrite(CTX, models, options.BulkWrite().SetOrdered(false))
+	var bulkErr mongo.BulkWriteException
+	if errors.As(err, &bulkErr) && bulkErr.WriteConcernError == nil {
+		for _, writeErr := range bulkErr.WriteErrors {
+			failed[ids[writeErr.Index]] = writeErr
+		}
+		err = nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	if res == nil || res.MatchedCount+int64(len(failed)) >= int64(len(bulk.Update)+len(bulk.Trash)) {
+		return
+	}
+	// some updates did not match, find out which by looking at the stored versions
+	var matchIds []string
+	for _, update := range bulk.Update {
+		matchIds = append(matchIds, update.Pipeline.Id)
+	}
+	matchIds = append(matchIds, bulk.Trash...)
+	cur, err := Mongo().Find(CTX, bson.M{"id": bson.M{"$in": matchIds}}, options.Find().SetProjection(bson.M{"id": 1, "version": 1, "deletedat": 1}))
+	if err != nil {
+		return nil, err
+	}
+	var stored []lib.Pipeline
+	err = cur.All(CTX, &stored)
+	if err != nil {
+		return nil, err
+	}
+	byId := map[string]lib.Pipeline{}
+	for _, pipeline := range stored {
+		byId[pipeline.Id] = pipeline
+	}
+	for _, update := range bulk.Update {
+		pipeline, ok := byId[update.Pipeline.Id]
+		if _, done := failed[update.Pipeline.Id]; !done && (!ok || pipeline.DeletedAt != nil || pipeline.Version != update.Pipeline.Version) {
+			failed[update.Pipeline.Id] = lib.NewConflictError(errors.New(MessageVersionConflict))
+		}
+	}
+	for _, id := range bulk.Trash {
+		// trashed by a concurrent request
+		if pipeline, ok := byId[id]; !ok || pipeline.DeletedAt != nil {
+			failed[id] = mongo.ErrNoDocuments
+		}
+	}
+	return failed, nil
+}
+
+func (r *MockRepo) FindPipelines(_ []string) (pipelines []lib.Pipeline, err error) {
+	return
+}
+
+func (r *MockRepo) BulkWritePipelines(_ PipelineBulk) (failed map[string]error, err error) {
+	return map[string]error{}, nil
+}
@@ -0,0 +1,43 @@
+/*
+ * Copyright 2025 InfAI (CC SES)
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *      http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package db
+
+import "time"
+
+const MaxStatusTransitions = 100
+
+// MaxWebhookDeliveries limits the number of deliveries returned per subscription.
+const MaxWebhookDeliveries = 100
+
+// WebhookDeliveryRetention is the time after which logged deliveries are removed.
+const WebhookDeliveryRetention = 7 * 24 * time.Hour
+
+// OutboxRetention is the time after which unpublished events are removed from the outbox,
+// e.g. because no event publisher is configured.
+const OutboxRetention = 7 * 24 * time.Hour
+
+// RevisionLease is the time after which a revision is considered orphaned if the pipeline was
+// not updated to its version, e.g. because the service crashed in between.
+const RevisionLease = time.Minute
+
+const (
+	MessageVersionConflict     = "pipeline was modified concurrently"
+	MessageTemplateConflict    = "template was modified concurrently"
+	MessageStatusConflict      = "pipeline status was changed concurrently"
+	MessageInvalidCursor       = "invalid cursor"
+	MessageCursorOrderMismatch = "cursor does not match order"
+)
@@ -0,0 +1,98 @@
+/*
+ * Copyright 2025 InfAI (CC SES)
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *      http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package db
+
+import (
+	"encoding/base64"
+	"encoding/json"
+	"errors"
+	"time"
+
+	"github.com/SENERGY-Platform/analytics-pipeline/lib"
+	"go.mongodb.org/mongo-driver/bson"
+)
+
+// pageCursor points behind the last pipeline of a page. It is handed out as opaque token and
+// stores the sort key and the id of that pipeline, the id breaks ties of equal sort values.
+type pageCursor struct {
+	Field string `json:"f"`
+	Order int    `json:"o"`
+	Value string `json:"v"`
+	Id    string `json:"i"`
+}
+
+func (c pageCursor) encode() string {
+	b, _ := json.Marshal(c)
+	return base64.RawURLEncoding.EncodeToString(b)
+}
+
+func decodeCursor(token string) (cursor pageCursor, err error) {
+	b, err := base64.RawURLEncoding.DecodeString(token)
+	if err == nil {
+		err = json.Unmarshal(b, &cursor)
+	}
+	if err != nil || !isSortField(cursor.Field) || (cursor.Order != 1 && cursor.Order != -1) {
+		return cursor, lib.NewInputError(errors.New(MessageInvalidCursor))
+	}
+	return cursor, nil
+}
+
+func newCursor(pipeline lib.Pipeline, field string, order int) pageCursor {
+	cursor := pageCursor{Field: field, Order: order, Id: pipeline.Id}
+	switch field {
+	case "name":
+		cursor.Value = pipeline.Name
+	case "id":
+		cursor.Value = pipeline.Id
+	case "createdat":
+		cursor.Value = pipeline.CreatedAt.Format(time.RFC3339Nano)
+	case "updatedat":
+		cursor.Value = pipeline.UpdatedAt.Format(time.RFC3339Nano)
+	}
+	return cursor
+}
+
+// filter matches all pipelines sorted behind the cursor.
+func (c pageCursor) filter() (bson.M, error) {
+	var value any = c.Value
+	if c.Field == "createdat" || c.Field == "updatedat" {
+		t, err := time.Parse(time.RFC3339Nano, c.Value)
+		if err != nil {
+			return nil, lib.NewInputError(errors.New(MessageInvalidCursor))
+		}
+		value = t
+	}
+	op := "$gt"
+	if c.Order == -1 {
+		op = "$lt"
+	}
+	if c.Field == "id" {
+		return bson.M{"id": bson.M{op: c.Id}}, nil
+	}
+	return bson.M{"$or": bson.A{
+		bson.M{c.Field: bson.M{op: value}},
+		bson.M{c.Field: value, "id": bson.M{op: c.Id}},
+	}}, nil
+}
+
+func isSortField(field string) bool {
+	switch field {
+	case "name", "id", "createdat", "updatedat":
+		return true
+	}
+	return false
+}
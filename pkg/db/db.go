@@ -23,6 +23,7 @@ import (
 
 	"github.com/SENERGY-Platform/analytics-pipeline/pkg/config"
 	"github.com/SENERGY-Platform/analytics-pipeline/pkg/util"
+	"go.mongodb.org/mongo-driver/bson"
 	"go.mongodb.org/mongo-driver/mongo"
 	"go.mongodb.org/mongo-driver/mongo/options"
 )
@@ -39,12 +40,88 @@ func InitDB(cfg *config.MongoConfig) {
 		util.Logger.Info("connected to db")
 	}
 	DB = client
+	ensureIndexes()
+	runMigrations()
+}
+
+func ensureIndexes() {
+	_, err := Revisions().Indexes().CreateOne(context.Background(), mongo.IndexModel{
+		Keys:    bson.D{{Key: "pipelineid", Value: 1}, {Key: "revision", Value: -1}},
+		Options: options.Index().SetUnique(true),
+	})
+	if err != nil {
+		util.Logger.Error("failed to create revision index", "error", err)
+	}
+	_, err = Mongo().Indexes().CreateMany(context.Background(), []mongo.IndexModel{
+		{Keys: bson.D{{Key: "operators.inputtopics.name", Value: 1}}},
+		{Keys: bson.D{{Key: "operators.outputtopic", Value: 1}}},
+	})
+	if err != nil {
+		util.Logger.Error("failed to create topic index", "error", err)
+	}
+	_, err = Outbox().Indexes().CreateMany(context.Background(), []mongo.IndexModel{
+		{Keys: bson.D{{Key: "id", Value: 1}}},
+		{
+			Keys:    bson.D{{Key: "timestamp", Value: 1}},
+			Options: options.Index().SetExpireAfterSeconds(int32(OutboxRetention.Seconds())),
+		},
+	})
+	if err != nil {
+		util.Logger.Error("failed to create outbox index", "error", err)
+	}
+	_, err = Journal().Indexes().CreateMany(context.Background(), []mongo.IndexModel{
+		{Keys: bson.D{{Key: "id", Value: 1}}},
+		{Keys: bson.D{{Key: "nextattempt", Value: 1}}},
+	})
+	if err != nil {
+		util.Logger.Error("failed to create journal index", "error", err)
+	}
+	_, err = WebhookDeliveries().Indexes().CreateOne(context.Background(), mongo.IndexModel{
+		Keys:    bson.D{{Key: "timestamp", Value: 1}},
+		Options: options.Index().SetExpireAfterSeconds(int32(WebhookDeliveryRetention.Seconds())),
+	})
+	if err != nil {
+		util.Logger.Error("failed to create webhook delivery index", "error", err)
+	}
+	ensureTextIndex()
 }
 
 func Mongo() *mongo.Collection {
 	return DB.Database("service").Collection("pipelines")
 }
 
+func Revisions() *mongo.Collection {
+	return DB.Database("service").Collection("pipeline_revisions")
+}
+
+func Outbox() *mongo.Collection {
+	return DB.Database("service").Collection("pipeline_outbox")
+}
+
+func Journal() *mongo.Collection {
+	return DB.Database("service").Collection("pipeline_journal")
+}
+
+func Migrations() *mongo.Collection {
+	return DB.Database("service").Collection("pipeline_migrations")
+}
+
+func Webhooks() *mongo.Collection {
+	return DB.Database("service").Collection("pipeline_webhooks")
+}
+
+func WebhookDeliveries() *mongo.Collection {
+	return DB.Database("service").Collection("pipeline_webhook_deliveries")
+}
+
+func WebhookDeadLetters() *mongo.Collection {
+	return DB.Database("service").Collection("pipeline_webhook_dead_letters")
+}
+
+func Templates() *mongo.Collection {
+	return DB.Database("service").Collection("pipeline_templates")
+}
+
 func CloseDB() {
 	err := DB.Disconnect(CTX)
 	if err != nil {
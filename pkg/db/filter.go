@@ -0,0 +1,78 @@
+/*
+ * Copyright 2025 InfAI (CC SES)
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *      http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package db
+
+import (
+	"github.com/SENERGY-Platform/analytics-pipeline/lib"
+	"go.mongodb.org/mongo-driver/bson"
+)
+
+// filterPaths maps the fields of lib.FilterFields to document paths.
+var filterPaths = map[string]string{
+	"name":               "name",
+	"owner":              "userid",
+	"status":             "status",
+	"flow":               "flowid",
+	"image":              "image",
+	"mergeStrategy":      "mergestrategy",
+	"metrics":            "metrics",
+	"consumeAllMessages": "consumeallmessages",
+	"createdAt":          "createdat",
+	"updatedAt":          "updatedat",
+	"operator":           "operators.operatorid",
+	"deploymentType":     "operators.deploymenttype",
+	"persistData":        "operators.persistdata",
+	"inputTopic":         "operators.inputtopics.name",
+	"template":           "template.templateid",
+}
+
+var filterOperators = map[string]string{
+	lib.FilterOpGt:  "$gt",
+	lib.FilterOpGte: "$gte",
+	lib.FilterOpLt:  "$lt",
+	lib.FilterOpLte: "$lte",
+}
+
+// filterToBson translates a parsed filter into a query document.
+func filterToBson(expr lib.FilterExpr) bson.M {
+	switch e := expr.(type) {
+	case lib.FilterAnd:
+		return bson.M{"$and": filtersToBson(e.Terms)}
+	case lib.FilterOr:
+		return bson.M{"$or": filtersToBson(e.Terms)}
+	case lib.FilterNot:
+		return bson.M{"$nor": bson.A{filterToBson(e.Term)}}
+	case lib.FilterCondition:
+		path := filterPaths[e.Field]
+		if op, ok := filterOperators[e.Op]; ok {
+			return bson.M{path: bson.M{op: e.Values[0]}}
+		}
+		if len(e.Values) == 1 {
+			return bson.M{path: e.Values[0]}
+		}
+		return bson.M{path: bson.M{"$in": e.Values}}
+	}
+	return bson.M{}
+}
+
+func filtersToBson(terms []lib.FilterExpr) bson.A {
+	result := bson.A{}
+	for _, term := range terms {
+		result = append(result, filterToBson(term))
+	}
+	return result
+}
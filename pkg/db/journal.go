@@ -0,0 +1,130 @@
+/*
+ * Copyright 2025 InfAI (CC SES)
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *      http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package db
+
+import (
+	"time"
+
+	permV2Model "github.com/SENERGY-Platform/permissions-v2/pkg/model"
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/mongo"
+	"go.mongodb.org/mongo-driver/mongo/options"
+)
+
+const (
+	// OperationCreate is rolled forward, a stored pipeline gets its permissions, otherwise a
+	// permissions-v2 resource left behind is removed.
+	OperationCreate = "create"
+	// OperationPurge removes the pipeline, its revisions and its permissions-v2 resource.
+	OperationPurge = "purge"
+	// OperationTransfer is rolled forward, the pipeline is handed over to ToUserId, who gets all
+	// permissions. FromUserId loses theirs unless KeepPermissions is set.
+	OperationTransfer = "transfer"
+	// OperationRestoreOwner undoes a transfer, FromUserId becomes the owner again and the grants of
+	// both users are reset to FromPermissions and ToPermissions.
+	OperationRestoreOwner = "restoreOwner"
+)
+
+// PipelineOperation is an entry of the operation journal. It is stored before a pipeline is
+// changed in both the database and permissions-v2 and removed once both have been changed.
+// Entries left behind by failures or crashes are applied again after NextAttempt, applying
+// an operation has to be idempotent.
+type PipelineOperation struct {
+	Id          string                           `json:"id"`
+	Action      string                           `json:"action"`
+	PipelineId  string                           `json:"pipelineId"`
+	Permissions *permV2Model.ResourcePermissions `json:"permissions,omitempty"`
+	// fields of transfers, only the grants of both users are stored, since other permissions may
+	// be changed concurrently
+	FromUserId      string                      `json:"fromUserId,omitempty"`
+	ToUserId        string                      `json:"toUserId,omitempty"`
+	KeepPermissions bool                        `json:"keepPermissions,omitempty"`
+	FromPermissions *permV2Model.PermissionsMap `json:"fromPermissions,omitempty"`
+	ToPermissions   *permV2Model.PermissionsMap `json:"toPermissions,omitempty"`
+
+	Attempts    int       `json:"attempts"`
+	LastError   string    `json:"lastError,omitempty"`
+	CreatedAt   time.Time `json:"createdAt"`
+	NextAttempt time.Time `json:"nextAttempt"`
+}
+
+func (r *MongoRepo) InsertOperations(operations []PipelineOperation) (err error) {
+	if len(operations) == 0 {
+		return
+	}
+	docs := make([]any, len(operations))
+	for i, operation := range operations {
+		docs[i] = operation
+	}
+	_, err = Journal().InsertMany(CTX, docs)
+	return
+}
+
+// ClaimOperation returns the operation due longest and postpones it by lease, so that other
+// instances do not apply it at the same time. Without due operations, mongo.ErrNoDocuments is returned.
+func (r *MongoRepo) ClaimOperation(now time.Time, lease time.Duration) (operation PipelineOperation, err error) {
+	err = Journal().FindOneAndUpdate(CTX,
+		bson.M{"nextattempt": bson.M{"$lte": now}},
+		bson.M{"$set": bson.M{"nextattempt": now.Add(lease)}, "$inc": bson.M{"attempts": 1}},
+		options.FindOneAndUpdate().SetSort(bson.M{"nextattempt": 1}).SetReturnDocument(options.After),
+	).Decode(&operation)
+	return
+}
+
+func (r *MongoRepo) UpdateOperation(operation PipelineOperation) (err error) {
+	_, err = Journal().UpdateOne(CTX, bson.M{"id": operation.Id}, bson.M{"$set": bson.M{
+		"action":      operation.Action,
+		"attempts":    operation.Attempts,
+		"lasterror":   operation.LastError,
+		"nextattempt": operation.NextAttempt,
+	}})
+	return
+}
+
+func (r *MongoRepo) DeleteOperations(ids []string) (err error) {
+	if len(ids) == 0 {
+		return
+	}
+	_, err = Journal().DeleteMany(CTX, bson.M{"id": bson.M{"$in": ids}})
+	return
+}
+
+// PipelineExists reports whether a pipeline is stored, trashed pipelines included.
+func (r *MongoRepo) PipelineExists(id string) (exists bool, err error) {
+	count, err := Mongo().CountDocuments(CTX, bson.M{"id": id}, options.Count().SetLimit(1))
+	return count > 0, err
+}
+
+func (r *MockRepo) InsertOperations(_ []PipelineOperation) (err error) {
+	return
+}
+
+func (r *MockRepo) ClaimOperation(_ time.Time, _ time.Duration) (operation PipelineOperation, err error) {
+	return operation, mongo.ErrNoDocuments
+}
+
+func (r *MockRepo) UpdateOperation(_ PipelineOperation) (err error) {
+	return
+}
+
+func (r *MockRepo) DeleteOperations(_ []string) (err error) {
+	return
+}
+
+func (r *MockRepo) PipelineExists(_ string) (exists bool, err error) {
+	return
+}
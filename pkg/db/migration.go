@@ -0,0 +1,81 @@
+/*
+ * Copyright 2025 InfAI (CC SES)
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *      http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package db
+
+import (
+	"context"
+	"errors"
+	"time"
+
+	"github.com/SENERGY-Platform/analytics-pipeline/lib"
+	"github.com/SENERGY-Platform/analytics-pipeline/pkg/util"
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/mongo"
+)
+
+// migration changes stored documents once. Applied migrations are recorded in the migrations
+// collection. Instances starting at the same time may run a migration twice, so it has to be idempotent.
+type migration struct {
+	id  string
+	run func(ctx context.Context) error
+}
+
+// migrations are applied in order, a failed migration stops the following ones until the next start.
+var migrations = []migration{
+	{id: "pipeline-version", run: migratePipelineVersion},
+	{id: "pipeline-status", run: migratePipelineStatus},
+	{id: "pipeline-search-config", run: migrateSearchConfig},
+}
+
+func runMigrations() {
+	ctx := context.Background()
+	for _, m := range migrations {
+		err := Migrations().FindOne(ctx, bson.M{"id": m.id}).Err()
+		if err == nil {
+			continue
+		}
+		if !errors.Is(err, mongo.ErrNoDocuments) {
+			util.Logger.Error("failed to check migration", "error", err, "migration", m.id)
+			return
+		}
+		err = m.run(ctx)
+		if err != nil {
+			util.Logger.Error("failed to run migration", "error", err, "migration", m.id)
+			return
+		}
+		_, err = Migrations().InsertOne(ctx, bson.M{"id": m.id, "appliedat": time.Now()})
+		if err != nil {
+			util.Logger.Error("failed to record migration", "error", err, "migration", m.id)
+			return
+		}
+		util.Logger.Info("applied migration", "migration", m.id)
+	}
+}
+
+// migratePipelineVersion sets version 1 for pipelines stored before versioning, so that lost
+// updates are detected for them as well.
+func migratePipelineVersion(ctx context.Context) error {
+	_, err := Mongo().UpdateMany(ctx, bson.M{"version": bson.M{"$in": bson.A{0, nil}}}, bson.M{"$set": bson.M{"version": 1}})
+	return err
+}
+
+// migratePipelineStatus sets status draft for pipelines stored before statuses, so that they
+// match status filters and follow the status transitions.
+func migratePipelineStatus(ctx context.Context) error {
+	_, err := Mongo().UpdateMany(ctx, bson.M{"status": bson.M{"$in": bson.A{"", nil}}}, bson.M{"$set": bson.M{"status": lib.PipelineStatusDraft}})
+	return err
+}
@@ -0,0 +1,57 @@
+/*
+ * Copyright 2025 InfAI (CC SES)
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *      http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package db
+
+import (
+	"github.com/SENERGY-Platform/analytics-pipeline/lib"
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/mongo/options"
+)
+
+func (r *MongoRepo) InsertOutboxEvent(event lib.PipelineEvent) (err error) {
+	_, err = Outbox().InsertOne(CTX, event)
+	return
+}
+
+// PendingOutboxEvents returns the oldest events first. The generated _id is used for sorting,
+// because object ids created by one client are strictly increasing.
+func (r *MongoRepo) PendingOutboxEvents(limit int64) (events []lib.PipelineEvent, err error) {
+	cur, err := Outbox().Find(CTX, bson.M{}, options.Find().SetSort(bson.M{"_id": 1}).SetLimit(limit))
+	if err != nil {
+		return
+	}
+	events = make([]lib.PipelineEvent, 0)
+	err = cur.All(CTX, &events)
+	return
+}
+
+func (r *MongoRepo) DeleteOutboxEvent(id string) (err error) {
+	_, err = Outbox().DeleteOne(CTX, bson.M{"id": id})
+	return
+}
+
+func (r *MockRepo) InsertOutboxEvent(_ lib.PipelineEvent) (err error) {
+	return
+}
+
+func (r *MockRepo) PendingOutboxEvents(_ int64) (events []lib.PipelineEvent, err error) {
+	return
+}
+
+func (r *MockRepo) DeleteOutboxEvent(_ string) (err error) {
+	return
+}
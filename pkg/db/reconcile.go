@@ -0,0 +1,64 @@
+/*
+ * Copyright 2025 InfAI (CC SES)
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *      http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package db
+
+import (
+	"github.com/SENERGY-Platform/analytics-pipeline/lib"
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/mongo/options"
+)
+
+// PipelineOwners returns a page of pipelines, trashed ones included, ordered by id and starting
+// after afterId. Only the fields needed to derive permissions are loaded.
+func (r *MongoRepo) PipelineOwners(afterId string, limit int64) (pipelines []lib.Pipeline, err error) {
+	cur, err := Mongo().Find(CTX, bson.M{"id": bson.M{"$gt": afterId}}, options.Find().
+		SetSort(bson.M{"id": 1}).
+		SetLimit(limit).
+		SetProjection(bson.M{"id": 1, "userid": 1, "createdat": 1, "deletedat": 1}))
+	if err != nil {
+		return
+	}
+	pipelines = make([]lib.Pipeline, 0)
+	err = cur.All(CTX, &pipelines)
+	return
+}
+
+// ExistingPipelineIds returns the ids of stored pipelines, trashed ones included.
+func (r *MongoRepo) ExistingPipelineIds(ids []string) (existing []string, err error) {
+	existing = []string{}
+	if len(ids) == 0 {
+		return
+	}
+	values, err := Mongo().Distinct(CTX, "id", bson.M{"id": bson.M{"$in": ids}})
+	if err != nil {
+		return
+	}
+	for _, value := range values {
+		if id, ok := value.(string); ok {
+			existing = append(existing, id)
+		}
+	}
+	return
+}
+
+func (r *MockRepo) PipelineOwners(_ string, _ int64) (pipelines []lib.Pipeline, err error) {
+	return
+}
+
+func (r *MockRepo) ExistingPipelineIds(_ []string) (existing []string, err error) {
+	return
+}
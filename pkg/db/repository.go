@@ -18,9 +18,10 @@ package db
 
 import (
 	"context"
-	"slices"
+	"errors"
 	"strconv"
 	"strings"
+	"time"
 
 	"github.com/SENERGY-Platform/analytics-pipeline/lib"
 	"go.mongodb.org/mongo-driver/bson"
@@ -31,13 +32,58 @@ import (
 
 type PipelineRepository interface {
 	InsertPipeline(pipeline lib.Pipeline) (err error)
-	UpdatePipeline(pipeline lib.Pipeline, userId string) (err error)
+	UpdatePipeline(pipeline lib.Pipeline, userId string, expectedVersion int) (err error)
 	All(userId string, admin bool, args map[string][]string, ids []string) (pipelines lib.PipelinesResponse, err error)
+	AllTrashed(userId string, admin bool, args map[string][]string, ids []string) (pipelines lib.PipelinesResponse, err error)
 	FindPipeline(id string, userId string) (pipeline lib.Pipeline, err error)
+	FindPipelines(ids []string) (pipelines []lib.Pipeline, err error)
+	BulkWritePipelines(bulk PipelineBulk) (failed map[string]error, err error)
 	DeletePipeline(id string, userId string, admin bool) (err error)
+	TrashPipeline(id string) (err error)
+	RestorePipeline(id string) (err error)
+	TrashedBefore(before time.Time) (ids []string, err error)
+	UpdatePipelineStatus(id string, from string, transition lib.StatusTransition) (err error)
 	PipelineUserCount(userId string, admin bool, args map[string][]string) (statistics []lib.PipelineUserCount, err error)
 	OperatorUsage(userId string, admin bool, args map[string][]string) (statistics []lib.OperatorUsage, err error)
 	FlowUsage(id string) (statistics []lib.FlowUsage, err error)
+	TopicUsage(topic string, userId string, ids []string) (usage *lib.TopicUsage, err error)
+	SelectableUsage(id string, userId string, ids []string) (usage *lib.SelectableUsage, err error)
+	OutputTopics(excludePipelineId string, ids []string) (topics []string, err error)
+	LinkedPipelines(topics []string, direction string, userId string, admin bool, ids []string) (pipelines []lib.Pipeline, err error)
+	InsertRevision(revision lib.PipelineRevision) (err error)
+	DeleteRevision(pipelineId string, revision int) (err error)
+	Revisions(pipelineId string) (revisions []lib.PipelineRevision, err error)
+	FindRevision(pipelineId string, revision int) (result lib.PipelineRevision, err error)
+	DeleteRevisions(pipelineId string) (err error)
+	InsertOutboxEvent(event lib.PipelineEvent) (err error)
+	PendingOutboxEvents(limit int64) (events []lib.PipelineEvent, err error)
+	DeleteOutboxEvent(id string) (err error)
+	InsertOperations(operations []PipelineOperation) (err error)
+	ClaimOperation(now time.Time, lease time.Duration) (operation PipelineOperation, err error)
+	UpdateOperation(operation PipelineOperation) (err error)
+	DeleteOperations(ids []string) (err error)
+	PipelineExists(id string) (exists bool, err error)
+	PipelineOwners(afterId string, limit int64) (pipelines []lib.Pipeline, err error)
+	ExistingPipelineIds(ids []string) (existing []string, err error)
+	ChangePipelineOwner(id string, from string, to string) (pipeline lib.Pipeline, err error)
+	OwnedPipelineIds(userId string) (ids []string, err error)
+	PipelineOwner(id string) (userId string, err error)
+	InsertWebhook(subscription lib.WebhookSubscription) (err error)
+	Webhooks(userId string) (subscriptions []lib.WebhookSubscription, err error)
+	FindWebhook(id string, userId string) (subscription lib.WebhookSubscription, err error)
+	MatchingWebhooks(eventType string, pipelineId string) (subscriptions []lib.WebhookSubscription, err error)
+	DeleteWebhook(id string, userId string) (err error)
+	InsertWebhookDelivery(delivery lib.WebhookDelivery) (err error)
+	WebhookDeliveries(subscriptionId string) (deliveries []lib.WebhookDelivery, err error)
+	SaveWebhookDeadLetter(letter lib.WebhookDeadLetter) (err error)
+	WebhookDeadLetters(subscriptionId string) (letters []lib.WebhookDeadLetter, err error)
+	FindWebhookDeadLetter(id string, subscriptionId string) (letter lib.WebhookDeadLetter, err error)
+	DeleteWebhookDeadLetter(id string) (err error)
+	InsertTemplate(template lib.PipelineTemplate) (err error)
+	UpdateTemplate(template lib.PipelineTemplate, expectedVersion int) (err error)
+	Templates(userId string) (templates []lib.PipelineTemplate, err error)
+	FindTemplate(id string, userId string) (template lib.PipelineTemplate, err error)
+	DeleteTemplate(id string, userId string) (err error)
 }
 
 type MongoRepo struct {
@@ -48,32 +94,67 @@ func NewMongoRepo() *MongoRepo {
 }
 
 func (r *MongoRepo) InsertPipeline(pipeline lib.Pipeline) (err error) {
-	_, err = Mongo().InsertOne(CTX, pipeline)
+	doc, err := pipelineDocument(pipeline)
+	if err != nil {
+		return
+	}
+	_, err = Mongo().InsertOne(CTX, doc)
 	if err != nil {
 		return
 	}
 	return
 }
 
-func (r *MongoRepo) UpdatePipeline(pipeline lib.Pipeline, _ string) (err error) {
-	_, err = Mongo().ReplaceOne(CTX, bson.M{"id": pipeline.Id}, pipeline)
-
+// UpdatePipeline replaces the pipeline only if the stored version equals expectedVersion,
+// see versionFilter.
+// The status is left untouched, it is only changed by UpdatePipelineStatus, and so is the owner,
+// which is only changed by ChangePipelineOwner.
+func (r *MongoRepo) UpdatePipeline(pipeline lib.Pipeline, _ string, expectedVersion int) (err error) {
+	req := versionFilter(pipeline.Id, expectedVersion)
+	set, err := pipelineDocument(pipeline)
 	if err != nil {
 		return err
 	}
+	delete(set, "status")
+	delete(set, "statustransitions")
+	delete(set, "userid")
+	res, err := Mongo().UpdateOne(CTX, req, bson.M{"$set": set})
+	if err != nil {
+		return err
+	}
+	if res.MatchedCount == 0 {
+		return lib.NewConflictError(errors.New(MessageVersionConflict))
+	}
 	return nil
 }
 
+// versionFilter matches the pipeline with id if it is not trashed and its version equals expectedVersion.
+// Pipelines stored without a version are migrated to version 1 by migratePipelineVersion.
+func versionFilter(id string, expectedVersion int) bson.M {
+	return bson.M{"id": id, "version": expectedVersion, "deletedat": nil}
+}
+
 func (r *MongoRepo) All(userId string, admin bool, args map[string][]string, ids []string) (pipelines lib.PipelinesResponse, err error) {
+	return r.all(userId, admin, args, ids, false)
+}
+
+func (r *MongoRepo) AllTrashed(userId string, admin bool, args map[string][]string, ids []string) (pipelines lib.PipelinesResponse, err error) {
+	return r.all(userId, admin, args, ids, true)
+}
+
+func (r *MongoRepo) all(userId string, admin bool, args map[string][]string, ids []string, trashed bool) (pipelines lib.PipelinesResponse, err error) {
 
 	opt := options.Find()
+	var limit int64
+	sortField, order := "id", 1
+	ordered := false
 
 	for arg, value := range args {
 
 		switch arg {
 
 		case "limit":
-			limit, _ := strconv.ParseInt(value[0], 10, 64)
+			limit, _ = strconv.ParseInt(value[0], 10, 64)
 			opt.SetLimit(limit)
 
 		case "offset":
@@ -86,20 +167,44 @@ func (r *MongoRepo) All(userId string, admin bool, args map[string][]string, ids
 				break
 			}
 
-			order := 1
-			if ord[1] == "desc" {
-				order = -1
+			if isSortField(ord[0]) {
+				sortField, ordered = ord[0], true
+				if ord[1] == "desc" {
+					order = -1
+				}
 			}
+		}
+	}
 
-			sortFields := []string{"name", "id", "createdat", "updatedat"}
-			if slices.Contains(sortFields, ord[0]) {
-				opt.SetSort(bson.M{ord[0]: int64(order)})
-			}
+	var cursor *pageCursor
+	if val, ok := args["cursor"]; ok && len(val) > 0 && val[0] != "" {
+		c, err := decodeCursor(val[0])
+		if err != nil {
+			return pipelines, err
 		}
+		if ordered && (c.Field != sortField || c.Order != order) {
+			return pipelines, lib.NewInputError(errors.New(MessageCursorOrderMismatch))
+		}
+		sortField, order = c.Field, c.Order
+		cursor = &c
+		opt.SetSkip(0)
+	}
+
+	// the id makes the order stable for equal sort values, which cursors rely on
+	if sortField == "id" {
+		opt.SetSort(bson.D{{Key: "id", Value: order}})
+	} else {
+		opt.SetSort(bson.D{{Key: sortField, Value: order}, {Key: "id", Value: order}})
 	}
 
 	andFilters := bson.A{}
 
+	if trashed {
+		andFilters = append(andFilters, bson.M{"deletedat": bson.M{"$ne": nil}})
+	} else {
+		andFilters = append(andFilters, bson.M{"deletedat": nil})
+	}
+
 	if !admin {
 		if ids == nil {
 			ids = []string{}
@@ -113,42 +218,38 @@ func (r *MongoRepo) All(userId string, admin bool, args map[string][]string, ids
 		})
 	}
 
+	var searchTerms []string
 	if val, ok := args["search"]; ok && len(val) > 0 {
-		andFilters = append(andFilters, bson.M{
-			"name": bson.M{
-				"$regex":   val[0],
-				"$options": "i",
-			},
-		})
+		searchTerms = lib.SearchTerms(val[0])
+	}
+	// without an explicit order, search results are ranked by relevance
+	ranked := len(searchTerms) > 0 && !ordered && cursor == nil
+	searchFilter := len(andFilters)
+	if len(searchTerms) > 0 {
+		andFilters = append(andFilters, bson.M{"$text": bson.M{"$search": strings.Join(searchTerms, " ")}})
+	}
+	unrankedSort := opt.Sort
+	if ranked {
+		opt.SetSort(bson.D{{Key: "score", Value: bson.M{"$meta": "textScore"}}, {Key: "id", Value: 1}})
 	}
 
-	fieldMap := map[string]string{
-		"operator": "operators.operatorid",
-		"flow":     "flowid",
+	if vals, ok := args["status"]; ok && len(vals) > 0 {
+		var statuses []string
+		for _, val := range vals {
+			statuses = append(statuses, strings.Split(val, ",")...)
+		}
+		andFilters = append(andFilters, bson.M{"status": bson.M{"$in": statuses}})
 	}
 
 	if vals, ok := args["filter"]; ok {
 		for _, raw := range vals {
-			for _, f := range strings.Split(raw, "|") {
-
-				parts := strings.SplitN(f, ":", 2)
-				if len(parts) != 2 {
-					continue
-				}
-
-				field, exists := fieldMap[parts[0]]
-				if !exists {
-					continue
-				}
-
-				values := strings.Split(parts[1], ",")
-				if len(values) == 0 {
-					continue
-				}
-
-				andFilters = append(andFilters, bson.M{
-					field: bson.M{"$in": values},
-				})
+			var expr lib.FilterExpr
+			expr, err = lib.ParseFilter(raw)
+			if err != nil {
+				return
+			}
+			if expr != nil {
+				andFilters = append(andFilters, filterToBson(expr))
 			}
 		}
 	}
@@ -158,24 +259,59 @@ func (r *MongoRepo) All(userId string, admin bool, args map[string][]string, ids
 		req["$and"] = andFilters
 	}
 
-	var cur *mongo.Cursor
-	cur, err = Mongo().Find(CTX, req, opt)
+	pipelines.Total, err = Mongo().CountDocuments(CTX, req)
 	if err != nil {
 		return
 	}
+	if pipelines.Total == 0 && len(searchTerms) > 0 {
+		// the text index only matches whole words, partial words are looked up in the names
+		andFilters[searchFilter] = namePrefixFilter(searchTerms)
+		req = bson.M{"$and": andFilters}
+		ranked = false
+		opt.SetSort(unrankedSort)
+		pipelines.Total, err = Mongo().CountDocuments(CTX, req)
+		if err != nil {
+			return
+		}
+	}
 
-	pipelines.Total, err = Mongo().CountDocuments(CTX, req)
+	if cursor != nil {
+		var after bson.M
+		after, err = cursor.filter()
+		if err != nil {
+			return
+		}
+		req = bson.M{"$and": bson.A{req, after}}
+	}
+
+	var cur *mongo.Cursor
+	cur, err = Mongo().Find(CTX, req, opt)
 	if err != nil {
 		return
 	}
 
 	pipelines.Data = make([]lib.Pipeline, 0)
 	err = cur.All(context.TODO(), &pipelines.Data)
+	if err != nil {
+		return
+	}
+	if len(searchTerms) > 0 {
+		pipelines.Highlights = map[string][]lib.SearchHighlight{}
+		for _, pipeline := range pipelines.Data {
+			if highlights := lib.HighlightPipeline(pipeline, searchTerms); len(highlights) > 0 {
+				pipelines.Highlights[pipeline.Id] = highlights
+			}
+		}
+	}
+	// relevance cannot be resumed from a cursor, ranked results are paged by offset
+	if limit > 0 && int64(len(pipelines.Data)) == limit && !ranked {
+		pipelines.NextCursor = newCursor(pipelines.Data[len(pipelines.Data)-1], sortField, order).encode()
+	}
 	return
 }
 
 func (r *MongoRepo) FindPipeline(id string, _ string) (pipeline lib.Pipeline, err error) {
-	err = Mongo().FindOne(CTX, bson.M{"id": id}).Decode(&pipeline)
+	err = Mongo().FindOne(CTX, bson.M{"id": id, "deletedat": nil}).Decode(&pipeline)
 	return
 }
 
@@ -185,8 +321,65 @@ func (r *MongoRepo) DeletePipeline(id string, userId string, admin bool) (err er
 	return res.Err()
 }
 
+// TrashPipeline marks a pipeline as deleted, trashed pipelines are hidden until restored or purged.
+func (r *MongoRepo) TrashPipeline(id string) (err error) {
+	res, err := Mongo().UpdateOne(CTX, bson.M{"id": id, "deletedat": nil}, bson.M{"$set": bson.M{"deletedat": time.Now()}})
+	if err != nil {
+		return
+	}
+	if res.MatchedCount == 0 {
+		return mongo.ErrNoDocuments
+	}
+	return
+}
+
+func (r *MongoRepo) RestorePipeline(id string) (err error) {
+	res, err := Mongo().UpdateOne(CTX, bson.M{"id": id, "deletedat": bson.M{"$ne": nil}}, bson.M{"$unset": bson.M{"deletedat": ""}})
+	if err != nil {
+		return
+	}
+	if res.MatchedCount == 0 {
+		return mongo.ErrNoDocuments
+	}
+	return
+}
+
+func (r *MongoRepo) TrashedBefore(before time.Time) (ids []string, err error) {
+	cur, err := Mongo().Find(CTX, bson.M{"deletedat": bson.M{"$ne": nil, "$lt": before}}, options.Find().SetProjection(bson.M{"id": 1}))
+	if err != nil {
+		return
+	}
+	var pipelines []lib.Pipeline
+	err = cur.All(CTX, &pipelines)
+	for _, pipeline := range pipelines {
+		ids = append(ids, pipeline.Id)
+	}
+	return
+}
+
+// UpdatePipelineStatus applies the transition only if the pipeline is still in status from.
+// Only the latest MaxStatusTransitions transitions are kept.
+func (r *MongoRepo) UpdatePipelineStatus(id string, from string, transition lib.StatusTransition) (err error) {
+	req := bson.M{"id": id, "status": from, "deletedat": nil}
+	res, err := Mongo().UpdateOne(CTX, req, bson.M{
+		"$set": bson.M{"status": transition.To},
+		"$push": bson.M{"statustransitions": bson.M{
+			"$each":  bson.A{transition},
+			"$slice": -MaxStatusTransitions,
+		}},
+	})
+	if err != nil {
+		return
+	}
+	if res.MatchedCount == 0 {
+		return lib.NewConflictError(errors.New(MessageStatusConflict))
+	}
+	return
+}
+
 func (r *MongoRepo) PipelineUserCount(_ string, _ bool, _ map[string][]string) (statistics []lib.PipelineUserCount, err error) {
 	pipeline := mongo.Pipeline{
+		{{"$match", bson.D{{"deletedat", nil}}}},
 		{
 			{"$group", bson.D{
 				{"_id", "$userid"},
@@ -219,6 +412,7 @@ func (r *MongoRepo) PipelineUserCount(_ string, _ bool, _ map[string][]string) (
 
 func (r *MongoRepo) OperatorUsage(_ string, _ bool, _ map[string][]string) (statistics []lib.OperatorUsage, err error) {
 	pipeline := mongo.Pipeline{
+		{{"$match", bson.D{{"deletedat", nil}}}},
 		{{"$unwind", "$operators"}},
 
 		{{"$group", bson.D{
@@ -248,15 +442,11 @@ func (r *MongoRepo) OperatorUsage(_ string, _ bool, _ map[string][]string) (stat
 }
 
 func (r *MongoRepo) FlowUsage(id string) (statistics []lib.FlowUsage, err error) {
-	pipeline := mongo.Pipeline{}
-
+	match := bson.D{{"deletedat", nil}}
 	if id != "" {
-		pipeline = append(pipeline, bson.D{
-			{"$match", bson.D{
-				{"flowid", id},
-			}},
-		})
+		match = append(match, bson.E{"flowid", id})
 	}
+	pipeline := mongo.Pipeline{{{"$match", match}}}
 
 	pipeline = append(pipeline,
 		bson.D{{"$group", bson.D{
@@ -284,6 +474,21 @@ func (r *MongoRepo) FlowUsage(id string) (statistics []lib.FlowUsage, err error)
 	return
 }
 
+// OutputTopics returns the output topics of the pipelines with ids, except excludePipelineId.
+func (r *MongoRepo) OutputTopics(excludePipelineId string, ids []string) (topics []string, err error) {
+	values, err := Mongo().Distinct(CTX, "operators.outputtopic", bson.M{"id": bson.M{"$ne": excludePipelineId, "$in": ids}, "deletedat": nil})
+	if err != nil {
+		return
+	}
+	topics = make([]string, 0, len(values))
+	for _, value := range values {
+		if topic, ok := value.(string); ok && topic != "" {
+			topics = append(topics, topic)
+		}
+	}
+	return
+}
+
 type MockRepo struct {
 }
 
@@ -295,7 +500,7 @@ func (r *MockRepo) InsertPipeline(_ lib.Pipeline) (err error) {
 	return
 }
 
-func (r *MockRepo) UpdatePipeline(_ lib.Pipeline, _ string) (err error) {
+func (r *MockRepo) UpdatePipeline(_ lib.Pipeline, _ string, _ int) (err error) {
 	return
 }
 
@@ -303,6 +508,10 @@ func (r *MockRepo) All(_ string, _ bool, _ map[string][]string, _ []string) (pip
 	return
 }
 
+func (r *MockRepo) AllTrashed(_ string, _ bool, _ map[string][]string, _ []string) (pipelines lib.PipelinesResponse, err error) {
+	return
+}
+
 func (r *MockRepo) FindPipeline(_ string, _ string) (pipeline lib.Pipeline, err error) {
 	return
 }
@@ -311,6 +520,22 @@ func (r *MockRepo) DeletePipeline(_ string, _ string, _ bool) (err error) {
 	return
 }
 
+func (r *MockRepo) TrashPipeline(_ string) (err error) {
+	return
+}
+
+func (r *MockRepo) RestorePipeline(_ string) (err error) {
+	return
+}
+
+func (r *MockRepo) TrashedBefore(_ time.Time) (ids []string, err error) {
+	return
+}
+
+func (r *MockRepo) UpdatePipelineStatus(_ string, _ string, _ lib.StatusTransition) (err error) {
+	return
+}
+
 func (r *MockRepo) PipelineUserCount(_ string, _ bool, _ map[string][]string) (statistics []lib.PipelineUserCount, err error) {
 	return
 }
@@ -321,3 +546,7 @@ func (r *MockRepo) OperatorUsage(_ string, _ bool, _ map[string][]string) (stati
 func (r *MockRepo) FlowUsage(id string) (statistics []lib.FlowUsage, err error) {
 	return
 }
+
+func (r *MockRepo) OutputTopics(_ string, _ []string) (topics []string, err error) {
+	return
+}
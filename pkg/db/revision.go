@@ -0,0 +1,104 @@
+/*
+ * Copyright 2025 InfAI (CC SES)
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *      http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package db
+
+import (
+	"errors"
+	"time"
+
+	"github.com/SENERGY-Platform/analytics-pipeline/lib"
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/mongo"
+	"go.mongodb.org/mongo-driver/mongo/options"
+)
+
+// InsertRevision stores a revision before the pipeline is written with its version. An existing
+// revision with the same number belongs to a concurrent update and causes a conflict, unless it is
+// orphaned: the pipeline has an older version and the revision is older than RevisionLease.
+func (r *MongoRepo) InsertRevision(revision lib.PipelineRevision) (err error) {
+	_, err = Revisions().InsertOne(CTX, revision)
+	if !mongo.IsDuplicateKeyError(err) {
+		return
+	}
+	var stored lib.Pipeline
+	err = Mongo().FindOne(CTX, bson.M{"id": revision.PipelineId}).Decode(&stored)
+	if err != nil {
+		return
+	}
+	if stored.Version >= revision.Revision {
+		return lib.NewConflictError(errors.New(MessageVersionConflict))
+	}
+	res, err := Revisions().ReplaceOne(CTX, bson.M{
+		"pipelineid": revision.PipelineId,
+		"revision":   revision.Revision,
+		"createdat":  bson.M{"$lt": time.Now().Add(-RevisionLease)},
+	}, revision)
+	if err != nil {
+		return
+	}
+	if res.MatchedCount == 0 {
+		return lib.NewConflictError(errors.New(MessageVersionConflict))
+	}
+	return nil
+}
+
+// DeleteRevision removes the revision of an update which was not written.
+func (r *MongoRepo) DeleteRevision(pipelineId string, revision int) (err error) {
+	_, err = Revisions().DeleteOne(CTX, bson.M{"pipelineid": pipelineId, "revision": revision})
+	return
+}
+
+func (r *MongoRepo) Revisions(pipelineId string) (revisions []lib.PipelineRevision, err error) {
+	cur, err := Revisions().Find(CTX, bson.M{"pipelineid": pipelineId},
+		options.Find().SetSort(bson.M{"revision": -1}).SetProjection(bson.M{"pipeline": 0}))
+	if err != nil {
+		return
+	}
+	revisions = make([]lib.PipelineRevision, 0)
+	err = cur.All(CTX, &revisions)
+	return
+}
+
+func (r *MongoRepo) FindRevision(pipelineId string, revision int) (result lib.PipelineRevision, err error) {
+	err = Revisions().FindOne(CTX, bson.M{"pipelineid": pipelineId, "revision": revision}).Decode(&result)
+	return
+}
+
+func (r *MongoRepo) DeleteRevisions(pipelineId string) (err error) {
+	_, err = Revisions().DeleteMany(CTX, bson.M{"pipelineid": pipelineId})
+	return
+}
+
+func (r *MockRepo) InsertRevision(_ lib.PipelineRevision) (err error) {
+	return
+}
+
+func (r *MockRepo) DeleteRevision(_ string, _ int) (err error) {
+	return
+}
+
+func (r *MockRepo) Revisions(_ string) (revisions []lib.PipelineRevision, err error) {
+	return
+}
+
+func (r *MockRepo) FindRevision(_ string, _ int) (result lib.PipelineRevision, err error) {
+	return
+}
+
+func (r *MockRepo) DeleteRevisions(_ string) (err error) {
+	return
+}
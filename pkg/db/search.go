@@ -0,0 +1,125 @@
+/*
+ * Copyright 2025 InfAI (CC SES)
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *      http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package db
+
+import (
+	"context"
+	"errors"
+	"regexp"
+
+	"github.com/SENERGY-Platform/analytics-pipeline/lib"
+	"github.com/SENERGY-Platform/analytics-pipeline/pkg/util"
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/mongo"
+	"go.mongodb.org/mongo-driver/mongo/options"
+)
+
+// searchConfigField holds the config values of all operators. Config keys are arbitrary,
+// so the values are copied into a plain array that can be part of the text index.
+const searchConfigField = "searchconfig"
+
+// error codes of createIndexes if an index with the same name but other options or keys exists
+const (
+	errIndexOptionsConflict  = 85
+	errIndexKeySpecsConflict = 86
+)
+
+// pipelineDocument returns the stored representation of a pipeline.
+func pipelineDocument(pipeline lib.Pipeline) (doc bson.M, err error) {
+	b, err := bson.Marshal(pipeline)
+	if err != nil {
+		return
+	}
+	err = bson.Unmarshal(b, &doc)
+	if err != nil {
+		return
+	}
+	values := bson.A{}
+	for _, operator := range pipeline.Operators {
+		for _, value := range operator.Config {
+			values = append(values, value)
+		}
+	}
+	doc[searchConfigField] = values
+	return
+}
+
+// textIndexName is the name of the text index, a collection can only have one.
+const textIndexName = "pipeline_text"
+
+// ensureTextIndex creates the text index. An index with other fields or weights is replaced.
+func ensureTextIndex() {
+	err := createTextIndex()
+	var serverErr mongo.ServerError
+	if errors.As(err, &serverErr) && (serverErr.HasErrorCode(errIndexOptionsConflict) || serverErr.HasErrorCode(errIndexKeySpecsConflict)) {
+		util.Logger.Info("replacing text index")
+		_, err = Mongo().Indexes().DropOne(context.Background(), textIndexName)
+		if err == nil {
+			err = createTextIndex()
+		}
+	}
+	if err != nil {
+		util.Logger.Error("failed to create text index", "error", err)
+	}
+}
+
+func createTextIndex() error {
+	_, err := Mongo().Indexes().CreateOne(context.Background(), mongo.IndexModel{
+		Keys: bson.D{
+			{Key: "name", Value: "text"},
+			{Key: "description", Value: "text"},
+			{Key: "operators.name", Value: "text"},
+			{Key: searchConfigField, Value: "text"},
+			{Key: "operators.inputtopics.name", Value: "text"},
+			{Key: "operators.outputtopic", Value: "text"},
+		},
+		Options: options.Index().SetName(textIndexName).SetDefaultLanguage("none").SetWeights(bson.D{
+			{Key: "name", Value: 10},
+			{Key: "description", Value: 5},
+			{Key: "operators.name", Value: 3},
+			{Key: searchConfigField, Value: 1},
+			{Key: "operators.inputtopics.name", Value: 1},
+			{Key: "operators.outputtopic", Value: 1},
+		}),
+	})
+	return err
+}
+
+// namePrefixFilter matches pipelines whose name contains a word starting with each of the terms.
+func namePrefixFilter(terms []string) bson.M {
+	filters := bson.A{}
+	for _, term := range terms {
+		filters = append(filters, bson.M{"name": bson.M{"$regex": `(?:^|\W)` + regexp.QuoteMeta(term), "$options": "i"}})
+	}
+	return bson.M{"$and": filters}
+}
+
+// migrateSearchConfig fills the config values of pipelines stored before the text index.
+func migrateSearchConfig(ctx context.Context) error {
+	_, err := Mongo().UpdateMany(ctx, bson.M{searchConfigField: bson.M{"$exists": false}}, mongo.Pipeline{
+		{{Key: "$set", Value: bson.M{searchConfigField: bson.M{"$reduce": bson.M{
+			"input":        bson.M{"$ifNull": bson.A{"$operators", bson.A{}}},
+			"initialValue": bson.A{},
+			"in": bson.M{"$concatArrays": bson.A{"$$value", bson.M{"$map": bson.M{
+				"input": bson.M{"$objectToArray": bson.M{"$ifNull": bson.A{"$$this.config", bson.M{}}}},
+				"as":    "entry",
+				"in":    "$$entry.v",
+			}}}},
+		}}}}},
+	})
+	return err
+}
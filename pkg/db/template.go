@@ -0,0 +1,94 @@
+/*
+ * Copyright 2025 InfAI (CC SES)
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *      http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package db
+
+import (
+	"errors"
+
+	"github.com/SENERGY-Platform/analytics-pipeline/lib"
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/mongo"
+	"go.mongodb.org/mongo-driver/mongo/options"
+)
+
+func (r *MongoRepo) InsertTemplate(template lib.PipelineTemplate) (err error) {
+	_, err = Templates().InsertOne(CTX, template)
+	return
+}
+
+// UpdateTemplate replaces a template if its stored version equals expectedVersion.
+func (r *MongoRepo) UpdateTemplate(template lib.PipelineTemplate, expectedVersion int) (err error) {
+	res, err := Templates().ReplaceOne(CTX, bson.M{"id": template.Id, "userid": template.UserId, "version": expectedVersion}, template)
+	if err != nil {
+		return
+	}
+	if res.MatchedCount == 0 {
+		return lib.NewConflictError(errors.New(MessageTemplateConflict))
+	}
+	return
+}
+
+func (r *MongoRepo) Templates(userId string) (templates []lib.PipelineTemplate, err error) {
+	cur, err := Templates().Find(CTX, bson.M{"userid": userId}, options.Find().SetSort(bson.M{"name": 1}))
+	if err != nil {
+		return
+	}
+	templates = make([]lib.PipelineTemplate, 0)
+	err = cur.All(CTX, &templates)
+	return
+}
+
+// FindTemplate returns a template of userId, an empty userId matches templates of any user.
+func (r *MongoRepo) FindTemplate(id string, userId string) (template lib.PipelineTemplate, err error) {
+	filter := bson.M{"id": id}
+	if userId != "" {
+		filter["userid"] = userId
+	}
+	err = Templates().FindOne(CTX, filter).Decode(&template)
+	return
+}
+
+func (r *MongoRepo) DeleteTemplate(id string, userId string) (err error) {
+	res, err := Templates().DeleteOne(CTX, bson.M{"id": id, "userid": userId})
+	if err != nil {
+		return
+	}
+	if res.DeletedCount == 0 {
+		return mongo.ErrNoDocuments
+	}
+	return
+}
+
+func (r *MockRepo) InsertTemplate(_ lib.PipelineTemplate) (err error) {
+	return
+}
+
+func (r *MockRepo) UpdateTemplate(_ lib.PipelineTemplate, _ int) (err error) {
+	return
+}
+
+func (r *MockRepo) Templates(_ string) (templates []lib.PipelineTemplate, err error) {
+	return
+}
+
+func (r *MockRepo) FindTemplate(_ string, _ string) (template lib.PipelineTemplate, err error) {
+	return
+}
+
+func (r *MockRepo) DeleteTemplate(_ string, _ string) (err error) {
+	return
+}
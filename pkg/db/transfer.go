@@ -0,0 +1,77 @@
+/*
+ * Copyright 2025 InfAI (CC SES)
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *      http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package db
+
+import (
+	"errors"
+	"time"
+
+	"github.com/SENERGY-Platform/analytics-pipeline/lib"
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/mongo"
+	"go.mongodb.org/mongo-driver/mongo/options"
+)
+
+// ChangePipelineOwner hands a pipeline owned by from over to, trashed pipelines included. Like the
+// status, the owner is not part of the versioned content. A pipeline already owned by to is
+// returned unchanged, so that a transfer can be applied again. Otherwise mongo.ErrNoDocuments is returned.
+func (r *MongoRepo) ChangePipelineOwner(id string, from string, to string) (pipeline lib.Pipeline, err error) {
+	err = Mongo().FindOneAndUpdate(CTX,
+		bson.M{"id": id, "userid": from},
+		bson.M{"$set": bson.M{"userid": to, "updatedat": time.Now()}},
+		options.FindOneAndUpdate().SetReturnDocument(options.After),
+	).Decode(&pipeline)
+	if !errors.Is(err, mongo.ErrNoDocuments) {
+		return
+	}
+	err = Mongo().FindOne(CTX, bson.M{"id": id, "userid": to}).Decode(&pipeline)
+	return
+}
+
+// OwnedPipelineIds returns the ids of all pipelines of a user, trashed ones included.
+func (r *MongoRepo) OwnedPipelineIds(userId string) (ids []string, err error) {
+	ids = []string{}
+	values, err := Mongo().Distinct(CTX, "id", bson.M{"userid": userId})
+	if err != nil {
+		return
+	}
+	for _, value := range values {
+		if id, ok := value.(string); ok {
+			ids = append(ids, id)
+		}
+	}
+	return
+}
+
+// PipelineOwner returns the owner of a pipeline, trashed pipelines included.
+func (r *MongoRepo) PipelineOwner(id string) (userId string, err error) {
+	var pipeline lib.Pipeline
+	err = Mongo().FindOne(CTX, bson.M{"id": id}, options.FindOne().SetProjection(bson.M{"userid": 1})).Decode(&pipeline)
+	return pipeline.UserId, err
+}
+
+func (r *MockRepo) ChangePipelineOwner(_ string, _ string, _ string) (pipeline lib.Pipeline, err error) {
+	return
+}
+
+func (r *MockRepo) OwnedPipelineIds(_ string) (ids []string, err error) {
+	return
+}
+
+func (r *MockRepo) PipelineOwner(_ string) (userId string, err error) {
+	return
+}
@@ -0,0 +1,132 @@
+/*
+ * Copyright 2025 InfAI (CC SES)
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *      http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package db
+
+import (
+	"slices"
+
+	"github.com/SENERGY-Platform/analytics-pipeline/lib"
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/mongo"
+)
+
+// TopicUsage returns the operators consuming topic in pipelines owned by userId or listed in ids,
+// or nil if there are none.
+func (r *MongoRepo) TopicUsage(topic string, userId string, ids []string) (usage *lib.TopicUsage, err error) {
+	references, err := operatorReferences(bson.M{"operators.inputtopics.name": topic}, userId, ids)
+	if err != nil || len(references) == 0 {
+		return
+	}
+	pipelineIds := referencedPipelineIds(references)
+	return &lib.TopicUsage{
+		Topic:       topic,
+		Count:       int32(len(pipelineIds)),
+		PipelineIds: pipelineIds,
+		Operators:   references,
+	}, nil
+}
+
+// SelectableUsage returns the operators referencing the device or device group id in pipelines
+// owned by userId or listed in ids, or nil if there are none.
+func (r *MongoRepo) SelectableUsage(id string, userId string, ids []string) (usage *lib.SelectableUsage, err error) {
+	references, err := operatorReferences(bson.M{"$or": bson.A{
+		bson.M{"operators.inputselections.selectableid": id},
+		bson.M{"operators.inputtopics": bson.M{"$elemMatch": bson.M{"filtertype": lib.FilterTypeDeviceId, "filtervalue": id}}},
+	}}, userId, ids)
+	if err != nil || len(references) == 0 {
+		return
+	}
+	pipelineIds := referencedPipelineIds(references)
+	return &lib.SelectableUsage{
+		SelectableId: id,
+		Count:        int32(len(pipelineIds)),
+		PipelineIds:  pipelineIds,
+		Operators:    references,
+	}, nil
+}
+
+// LinkedPipelines returns the pipelines consuming one of topics downstream or producing one of topics
+// upstream. Unless admin is set, only pipelines owned by userId or listed in ids are returned.
+func (r *MongoRepo) LinkedPipelines(topics []string, direction string, userId string, admin bool, ids []string) (pipelines []lib.Pipeline, err error) {
+	pipelines = make([]lib.Pipeline, 0)
+	if len(topics) == 0 {
+		return
+	}
+	field := "operators.inputtopics.name"
+	if direction == lib.LineageUpstream {
+		field = "operators.outputtopic"
+	}
+	filters := bson.A{bson.M{"deletedat": nil}, bson.M{field: bson.M{"$in": topics}}}
+	if !admin {
+		if ids == nil {
+			ids = []string{}
+		}
+		filters = append(filters, bson.M{"$or": bson.A{bson.M{"id": bson.M{"$in": ids}}, bson.M{"userid": userId}}})
+	}
+	cur, err := Mongo().Find(CTX, bson.M{"$and": filters})
+	if err != nil {
+		return
+	}
+	err = cur.All(CTX, &pipelines)
+	return
+}
+
+// operatorReferences returns all operators matching operatorMatch, a filter on operators.* paths.
+func operatorReferences(operatorMatch bson.M, userId string, ids []string) (references []lib.OperatorReference, err error) {
+	if ids == nil {
+		ids = []string{}
+	}
+	pipeline := mongo.Pipeline{
+		{{Key: "$match", Value: bson.M{"$and": bson.A{
+			bson.M{"deletedat": nil},
+			bson.M{"$or": bson.A{bson.M{"id": bson.M{"$in": ids}}, bson.M{"userid": userId}}},
+			operatorMatch,
+		}}}},
+		{{Key: "$unwind", Value: "$operators"}},
+		{{Key: "$match", Value: operatorMatch}},
+		{{Key: "$project", Value: bson.M{"_id": 0, "pipelineId": "$id", "operatorId": "$operators.id"}}},
+		{{Key: "$sort", Value: bson.D{{Key: "pipelineId", Value: 1}, {Key: "operatorId", Value: 1}}}},
+	}
+	cur, err := Mongo().Aggregate(CTX, pipeline)
+	if err != nil {
+		return
+	}
+	references = make([]lib.OperatorReference, 0)
+	err = cur.All(CTX, &references)
+	return
+}
+
+func referencedPipelineIds(references []lib.OperatorReference) (ids []string) {
+	for _, reference := range references {
+		if !slices.Contains(ids, reference.PipelineId) {
+			ids = append(ids, reference.PipelineId)
+		}
+	}
+	return
+}
+
+func (r *MockRepo) TopicUsage(_ string, _ string, _ []string) (usage *lib.TopicUsage, err error) {
+	return
+}
+
+func (r *MockRepo) SelectableUsage(_ string, _ string, _ []string) (usage *lib.SelectableUsage, err error) {
+	return
+}
+
+func (r *MockRepo) LinkedPipelines(_ []string, _ string, _ string, _ bool, _ []string) (pipelines []lib.Pipeline, err error) {
+	return
+}
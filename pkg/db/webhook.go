@@ -0,0 +1,166 @@
+/*
+ * Copyright 2025 InfAI (CC SES)
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *      http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package db
+
+import (
+	"github.com/SENERGY-Platform/analytics-pipeline/lib"
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/mongo"
+	"go.mongodb.org/mongo-driver/mongo/options"
+)
+
+func (r *MongoRepo) InsertWebhook(subscription lib.WebhookSubscription) (err error) {
+	_, err = Webhooks().InsertOne(CTX, subscription)
+	return
+}
+
+func (r *MongoRepo) Webhooks(userId string) (subscriptions []lib.WebhookSubscription, err error) {
+	cur, err := Webhooks().Find(CTX, bson.M{"userid": userId}, options.Find().SetSort(bson.M{"createdat": 1}))
+	if err != nil {
+		return
+	}
+	subscriptions = make([]lib.WebhookSubscription, 0)
+	err = cur.All(CTX, &subscriptions)
+	return
+}
+
+func (r *MongoRepo) FindWebhook(id string, userId string) (subscription lib.WebhookSubscription, err error) {
+	err = Webhooks().FindOne(CTX, bson.M{"id": id, "userid": userId}).Decode(&subscription)
+	return
+}
+
+// MatchingWebhooks returns all subscriptions of any user for the event type and pipeline.
+func (r *MongoRepo) MatchingWebhooks(eventType string, pipelineId string) (subscriptions []lib.WebhookSubscription, err error) {
+	cur, err := Webhooks().Find(CTX, bson.M{"$and": bson.A{
+		bson.M{"$or": bson.A{
+			bson.M{"eventtypes": eventType},
+			bson.M{"eventtypes": nil},
+			bson.M{"eventtypes": bson.M{"$size": 0}},
+		}},
+		bson.M{"pipelineid": bson.M{"$in": bson.A{"", pipelineId}}},
+	}})
+	if err != nil {
+		return
+	}
+	subscriptions = make([]lib.WebhookSubscription, 0)
+	err = cur.All(CTX, &subscriptions)
+	return
+}
+
+// DeleteWebhook removes a subscription together with its delivery log and dead letters.
+func (r *MongoRepo) DeleteWebhook(id string, userId string) (err error) {
+	res, err := Webhooks().DeleteOne(CTX, bson.M{"id": id, "userid": userId})
+	if err != nil {
+		return
+	}
+	if res.DeletedCount == 0 {
+		return mongo.ErrNoDocuments
+	}
+	_, err = WebhookDeliveries().DeleteMany(CTX, bson.M{"subscriptionid": id})
+	if err != nil {
+		return
+	}
+	_, err = WebhookDeadLetters().DeleteMany(CTX, bson.M{"subscriptionid": id})
+	return
+}
+
+func (r *MongoRepo) InsertWebhookDelivery(delivery lib.WebhookDelivery) (err error) {
+	_, err = WebhookDeliveries().InsertOne(CTX, delivery)
+	return
+}
+
+// WebhookDeliveries returns the latest deliveries of a subscription first.
+func (r *MongoRepo) WebhookDeliveries(subscriptionId string) (deliveries []lib.WebhookDelivery, err error) {
+	cur, err := WebhookDeliveries().Find(CTX, bson.M{"subscriptionid": subscriptionId},
+		options.Find().SetSort(bson.M{"timestamp": -1}).SetLimit(MaxWebhookDeliveries))
+	if err != nil {
+		return
+	}
+	deliveries = make([]lib.WebhookDelivery, 0)
+	err = cur.All(CTX, &deliveries)
+	return
+}
+
+// SaveWebhookDeadLetter inserts a dead letter or replaces the one with the same id.
+func (r *MongoRepo) SaveWebhookDeadLetter(letter lib.WebhookDeadLetter) (err error) {
+	_, err = WebhookDeadLetters().ReplaceOne(CTX, bson.M{"id": letter.Id}, letter, options.Replace().SetUpsert(true))
+	return
+}
+
+func (r *MongoRepo) WebhookDeadLetters(subscriptionId string) (letters []lib.WebhookDeadLetter, err error) {
+	cur, err := WebhookDeadLetters().Find(CTX, bson.M{"subscriptionid": subscriptionId}, options.Find().SetSort(bson.M{"createdat": -1}))
+	if err != nil {
+		return
+	}
+	letters = make([]lib.WebhookDeadLetter, 0)
+	err = cur.All(CTX, &letters)
+	return
+}
+
+func (r *MongoRepo) FindWebhookDeadLetter(id string, subscriptionId string) (letter lib.WebhookDeadLetter, err error) {
+	err = WebhookDeadLetters().FindOne(CTX, bson.M{"id": id, "subscriptionid": subscriptionId}).Decode(&letter)
+	return
+}
+
+func (r *MongoRepo) DeleteWebhookDeadLetter(id string) (err error) {
+	_, err = WebhookDeadLetters().DeleteOne(CTX, bson.M{"id": id})
+	return
+}
+
+func (r *MockRepo) InsertWebhook(_ lib.WebhookSubscription) (err error) {
+	return
+}
+
+func (r *MockRepo) Webhooks(_ string) (subscriptions []lib.WebhookSubscription, err error) {
+	return
+}
+
+func (r *MockRepo) FindWebhook(_ string, _ string) (subscription lib.WebhookSubscription, err error) {
+	return
+}
+
+func (r *MockRepo) MatchingWebhooks(_ string, _ string) (subscriptions []lib.WebhookSubscription, err error) {
+	return
+}
+
+func (r *MockRepo) DeleteWebhook(_ string, _ string) (err error) {
+	return
+}
+
+func (r *MockRepo) InsertWebhookDelivery(_ lib.WebhookDelivery) (err error) {
+	return
+}
+
+func (r *MockRepo) WebhookDeliveries(_ string) (deliveries []lib.WebhookDelivery, err error) {
+	return
+}
+
+func (r *MockRepo) SaveWebhookDeadLetter(_ lib.WebhookDeadLetter) (err error) {
+	return
+}
+
+func (r *MockRepo) WebhookDeadLetters(_ string) (letters []lib.WebhookDeadLetter, err error) {
+	return
+}
+
+func (r *MockRepo) FindWebhookDeadLetter(_ string, _ string) (letter lib.WebhookDeadLetter, err error) {
+	return
+}
+
+func (r *MockRepo) DeleteWebhookDeadLetter(_ string) (err error) {
+	return
+}
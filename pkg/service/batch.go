@@ -0,0 +1,401 @@
+/*
+ * Copyright 2025 InfAI (CC SES)
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *      http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package service
+
+import (
+	"errors"
+	"fmt"
+	"net/http"
+
+	"github.com/SENERGY-Platform/analytics-pipeline/lib"
+	"github.com/SENERGY-Platform/analytics-pipeline/pkg/db"
+	"github.com/SENERGY-Platform/analytics-pipeline/pkg/util"
+	permV2Client "github.com/SENERGY-Platform/permissions-v2/pkg/client"
+	"github.com/google/uuid"
+	"go.mongodb.org/mongo-driver/mongo"
+)
+
+// batchItem is an operation of a batch together with the pipeline state it leads to.
+type batchItem struct {
+	op       lib.PipelineBatchOperation
+	id       string
+	pipeline lib.Pipeline // new state of created and updated pipelines
+	old      lib.Pipeline // stored state of updated and deleted pipelines
+	err      error
+	// written is set once the write reached the repository
+	written bool
+	// revision is set once the revision of a created or updated pipeline is stored
+	revision bool
+	// operation journals created pipelines until their permissions are set
+	operation db.PipelineOperation
+}
+
+// BatchPipelines applies create, update and delete operations with a single bulk write. All
+// operations are validated and checked for permissions before anything is written. In atomic
+// mode, nothing is written if any operation is invalid, and applied operations are rolled back
+// if another one fails while writing. Deletes move pipelines to the trash like DeletePipeline.
+func (r *Registry) BatchPipelines(request lib.PipelineBatchRequest, userId string, auth string) (response lib.PipelineBatchResponse, err error) {
+	mode := request.Mode
+	if mode == "" {
+		mode = lib.BatchModeAtomic
+	}
+	v := &validator{}
+	if mode != lib.BatchModeAtomic && mode != lib.BatchModeBestEffort {
+		v.add("mode", fmt.Sprintf(MessageUnknownBatchMode, mode))
+	}
+	if len(request.Operations) == 0 {
+		v.add("operations", MessageMustNotBeEmpty)
+	}
+	if len(request.Operations) > MaxBatchOperations {
+		v.add("operations", fmt.Sprintf(MessageTooManyOperations, MaxBatchOperations))
+	}
+	err = v.err()
+	if err != nil {
+		return
+	}
+	items := make([]*batchItem, len(request.Operations))
+	for i, op := range request.Operations {
+		items[i] = &batchItem{op: op}
+	}
+	err = r.prepareBatch(items, userId, auth)
+	if err != nil {
+		return
+	}
+	atomic := mode == lib.BatchModeAtomic
+	if atomic && batchFailed(items) {
+		return batchResponse(mode, items), nil
+	}
+
+	// the revisions reserve the new versions like single updates do
+	r.saveBatchRevisions(items, userId)
+	if atomic && batchFailed(items) {
+		for _, item := range items {
+			r.rollbackBatchItem(item, userId)
+		}
+		return batchResponse(mode, items), nil
+	}
+
+	bulk := db.PipelineBulk{}
+	var creates []*batchItem
+	for _, item := range items {
+		if item.err != nil {
+			continue
+		}
+		switch item.op.Op {
+		case lib.BatchOpCreate:
+			bulk.Insert = append(bulk.Insert, item.pipeline)
+			creates = append(creates, item)
+		case lib.BatchOpUpdate:
+			bulk.Update = append(bulk.Update, db.PipelineUpdate{Pipeline: item.pipeline, ExpectedVersion: item.old.Version})
+		case lib.BatchOpDelete:
+			bulk.Trash = append(bulk.Trash, item.id)
+		}
+	}
+	createIds := make([]string, len(creates))
+	for i, item := range creates {
+		createIds[i] = item.id
+	}
+	operations, err := r.beginOperations(db.OperationCreate, createIds, nil)
+	if err != nil {
+		return
+	}
+	for i, item := range creates {
+		item.operation = operations[i]
+	}
+	failed, err := r.repository.BulkWritePipelines(bulk)
+	for _, item := range items {
+		if item.err != nil {
+			continue
+		}
+		switch {
+		case err != nil:
+			// the outcome of single writes is unknown, all of them are rolled back
+			item.err = err
+			item.written = true
+		case failed[item.id] != nil:
+			item.err = failed[item.id]
+		default:
+			item.written = true
+		}
+	}
+	for _, item := range items {
+		if item.err == nil && item.op.Op == lib.BatchOpCreate {
+			_, item.err, _ = r.perm.SetPermission(permV2Client.InternalAdminToken, PermV2InstanceTopic, item.id, ownerPermissions(item.pipeline, nil))
+		}
+	}
+	if err != nil || (atomic && batchFailed(items)) {
+		for _, item := range items {
+			r.rollbackBatchItem(item, userId)
+		}
+		return batchResponse(mode, items), nil
+	}
+
+	var completed []db.PipelineOperation
+	var events []lib.PipelineEvent
+	for _, item := range items {
+		if item.err != nil {
+			// created pipelines without permissions would be inaccessible
+			r.rollbackBatchItem(item, userId)
+			continue
+		}
+		switch item.op.Op {
+		case lib.BatchOpCreate:
+			completed = append(completed, item.operation)
+			events = append(events, newEvent(lib.PipelineEventCreated, item.pipeline, userId))
+		case lib.BatchOpUpdate:
+			events = append(events, newEvent(lib.PipelineEventUpdated, item.pipeline, userId))
+		case lib.BatchOpDelete:
+			events = append(events, newEvent(lib.PipelineEventDeleted, item.old, userId))
+		}
+	}
+	if len(completed) > 0 {
+		r.endOperations(completed...)
+	}
+	for _, event := range events {
+		r.publishEvent(event)
+	}
+	return batchResponse(mode, items), nil
+}
+
+// prepareBatch validates the operations and computes the resulting pipelines. Errors of single
+// operations are recorded in the items, the returned error fails the whole batch.
+func (r *Registry) prepareBatch(items []*batchItem, userId string, auth string) (err error) {
+	var updateIds, deleteIds []string
+	seen := map[string]int{}
+	for i, item := range items {
+		v := &validator{}
+		switch item.op.Op {
+		case lib.BatchOpCreate:
+			if item.op.Pipeline == nil {
+				v.add("pipeline", MessageMustNotBeEmpty)
+				break
+			}
+			item.id = uuid.NewString()
+			validatePipeline(v, *item.op.Pipeline)
+		case lib.BatchOpUpdate:
+			if item.op.Pipeline == nil {
+				v.add("pipeline", MessageMustNotBeEmpty)
+				break
+			}
+			item.id = item.op.Pipeline.Id
+			v.required("pipeline.id", item.id)
+			validatePipeline(v, *item.op.Pipeline)
+			updateIds = append(updateIds, item.id)
+		case lib.BatchOpDelete:
+			item.id = item.op.Id
+			v.required("id", item.id)
+			deleteIds = append(deleteIds, item.id)
+		default:
+			v.add("op", fmt.Sprintf(MessageUnknownBatchOp, item.op.Op))
+		}
+		if j, ok := seen[item.id]; ok && item.id != "" {
+			v.add("id", fmt.Sprintf(MessageDuplicateBatchPipeline, j))
+		}
+		seen[item.id] = i
+		item.err = v.err()
+	}
+
+	writable, err := r.checkMultiplePermissions(auth, updateIds, permV2Client.Write)
+	if err != nil {
+		return
+	}
+	administrable, err := r.checkMultiplePermissions(auth, deleteIds, permV2Client.Administrate)
+	if err != nil {
+		return
+	}
+	stored, err := r.repository.FindPipelines(append(updateIds, deleteIds...))
+	if err != nil {
+		return
+	}
+	byId := map[string]lib.Pipeline{}
+	for _, pipeline := range stored {
+		byId[pipeline.Id] = pipeline
+	}
+	// pipelines of the batch may consume the output of each other
+	readable, err, _ := r.perm.ListAccessibleResourceIds(auth, PermV2InstanceTopic, permV2Client.ListOptions{}, permV2Client.Read)
+	if err != nil {
+		return
+	}
+	outputTopics, err := r.repository.OutputTopics("", readable)
+	if err != nil {
+		return
+	}
+	for _, item := range items {
+		if item.err == nil && item.op.Pipeline != nil {
+			for _, operator := range item.op.Pipeline.Operators {
+				outputTopics = append(outputTopics, operator.OutputTopic)
+			}
+		}
+	}
+	external := externalTopicsOf(outputTopics)
+
+	for _, item := range items {
+		if item.err != nil {
+			continue
+		}
+		switch item.op.Op {
+		case lib.BatchOpCreate:
+			item.err = validateGraphWith(*item.op.Pipeline, external)
+			pipeline := *item.op.Pipeline
+			pipeline.Template = nil
+			item.pipeline = newPipeline(pipeline, item.id, userId, lib.PipelineStatusDraft)
+		case lib.BatchOpUpdate:
+			old, ok := byId[item.id]
+			switch {
+			case !writable[item.id]:
+				item.err = lib.NewForbiddenError(errors.New(MessageMissingRights))
+			case !ok:
+				item.err = mongo.ErrNoDocuments
+			case item.op.Pipeline.Version != 0 && item.op.Pipeline.Version != old.Version:
+				item.err = lib.NewConflictError(errors.New(db.MessageVersionConflict))
+			default:
+				item.err = validateGraphWith(*item.op.Pipeline, external)
+				item.old = old
+				item.pipeline = updatedPipeline(*item.op.Pipeline, old)
+			}
+		case lib.BatchOpDelete:
+			old, ok := byId[item.id]
+			switch {
+			case !administrable[item.id]:
+				item.err = lib.NewForbiddenError(errors.New(MessageMissingRights))
+			case !ok:
+				item.err = mongo.ErrNoDocuments
+			default:
+				item.old = old
+			}
+		}
+	}
+	return
+}
+
+func (r *Registry) checkMultiplePermissions(auth string, ids []string, permission permV2Client.Permission) (access map[string]bool, err error) {
+	if len(ids) == 0 {
+		return map[string]bool{}, nil
+	}
+	access, err, _ = r.perm.CheckMultiplePermissions(auth, PermV2InstanceTopic, ids, permission)
+	return
+}
+
+// saveBatchRevisions stores the revisions of created and updated pipelines before they are written.
+// A failed revision fails its operation, a conflicting one means a concurrent update.
+func (r *Registry) saveBatchRevisions(items []*batchItem, userId string) {
+	for _, item := range items {
+		if item.err != nil || (item.op.Op != lib.BatchOpCreate && item.op.Op != lib.BatchOpUpdate) {
+			continue
+		}
+		item.err = r.saveRevision(item.pipeline, userId, 0)
+		item.revision = item.err == nil
+	}
+}
+
+// rollbackBatchItem undoes the write of an item. Failures are logged, since the batch already
+// failed. Created pipelines are purged through the journal, which retries on failure and also
+// deletes their revisions.
+func (r *Registry) rollbackBatchItem(item *batchItem, userId string) {
+	if !item.written {
+		if item.operation.Id != "" {
+			r.endOperations(item.operation)
+			item.operation = db.PipelineOperation{}
+		}
+		r.discardBatchRevision(item)
+		return
+	}
+	var err error
+	switch item.op.Op {
+	case lib.BatchOpCreate:
+		r.compensateOperation(item.operation)
+		item.revision = false
+	case lib.BatchOpUpdate:
+		err = r.repository.UpdatePipeline(item.old, userId, item.pipeline.Version)
+		r.discardBatchRevision(item)
+	case lib.BatchOpDelete:
+		err = r.repository.RestorePipeline(item.id)
+	}
+	var conflict *lib.ConflictError
+	if err != nil && !errors.Is(err, mongo.ErrNoDocuments) && !errors.As(err, &conflict) {
+		util.Logger.Error("could not roll back batch operation", "error", err, "op", item.op.Op, "id", item.id)
+	}
+	item.written = false
+}
+
+// discardBatchRevision removes the revision of an operation which was not applied or rolled back.
+// Revisions of updates are kept if the stored pipeline still has their version, see discardRevision.
+func (r *Registry) discardBatchRevision(item *batchItem) {
+	if !item.revision {
+		return
+	}
+	item.revision = false
+	if item.op.Op == lib.BatchOpUpdate {
+		r.discardRevision(item.pipeline)
+		return
+	}
+	err := r.repository.DeleteRevision(item.id, item.pipeline.Version)
+	if err != nil {
+		util.Logger.Error("could not delete revision of failed batch operation", "error", err, "id", item.id)
+	}
+}
+
+func batchFailed(items []*batchItem) bool {
+	for _, item := range items {
+		if item.err != nil {
+			return true
+		}
+	}
+	return false
+}
+
+func batchResponse(mode string, items []*batchItem) lib.PipelineBatchResponse {
+	failed := batchFailed(items)
+	response := lib.PipelineBatchResponse{Mode: mode, Results: make([]lib.PipelineBatchResult, len(items))}
+	for i, item := range items {
+		result := lib.PipelineBatchResult{Index: i, Op: item.op.Op, Id: item.id, Status: http.StatusOK}
+		switch {
+		case item.err != nil:
+			result.Status, result.Error, result.Violations = batchError(item.err)
+			if result.Status == http.StatusInternalServerError {
+				util.Logger.Error("could not apply batch operation", "error", item.err, "op", item.op.Op, "id", item.id)
+			}
+		case failed && mode == lib.BatchModeAtomic:
+			result.Status = http.StatusFailedDependency
+			result.Error = MessageBatchNotApplied
+		}
+		if item.op.Op == lib.BatchOpCreate && result.Status != http.StatusOK {
+			result.Id = ""
+		}
+		response.Results[i] = result
+	}
+	return response
+}
+
+// batchError maps the error of an operation like the API maps errors of single requests.
+func batchError(err error) (status int, message string, violations []lib.Violation) {
+	if errors.Is(err, mongo.ErrNoDocuments) {
+		return http.StatusNotFound, http.StatusText(http.StatusNotFound), nil
+	}
+	var validationErr *lib.ValidationError
+	if errors.As(err, &validationErr) {
+		violations = validationErr.Violations
+	}
+	status = util.GetStatusCode(err)
+	switch status {
+	case 0, http.StatusInternalServerError:
+		return http.StatusInternalServerError, http.StatusText(http.StatusInternalServerError), nil
+	case http.StatusForbidden:
+		return status, MessageMissingRights, nil
+	}
+	return status, err.Error(), violations
+}
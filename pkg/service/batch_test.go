@@ -0,0 +1,147 @@
+/*
+ * Copyright 2025 InfAI (CC SES)
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *      http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package service
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"slices"
+	"testing"
+
+	"github.com/SENERGY-Platform/analytics-pipeline/lib"
+	"github.com/SENERGY-Platform/analytics-pipeline/pkg/db"
+	"github.com/SENERGY-Platform/analytics-pipeline/pkg/util"
+	permV2Client "github.com/SENERGY-Platform/permissions-v2/pkg/client"
+	"go.mongodb.org/mongo-driver/mongo"
+)
+
+type batchRepo struct {
+	*db.MockRepo
+	insertErr error
+	writeErr  error
+	calls     []string
+}
+
+func (r *batchRepo) FindPipelines(ids []string) (pipelines []lib.Pipeline, err error) {
+	for _, id := range ids {
+		pipelines = append(pipelines, lib.Pipeline{Id: id, UserId: "1", Version: 3})
+	}
+	return
+}
+
+func (r *batchRepo) FindPipeline(id string, _ string) (pipeline lib.Pipeline, err error) {
+	return lib.Pipeline{Id: id, UserId: "1", Version: 3}, nil
+}
+
+func (r *batchRepo) InsertRevision(_ lib.PipelineRevision) (err error) {
+	r.calls = append(r.calls, "insert")
+	return r.insertErr
+}
+
+func (r *batchRepo) BulkWritePipelines(bulk db.PipelineBulk) (failed map[string]error, err error) {
+	r.calls = append(r.calls, "write")
+	failed = map[string]error{}
+	if r.writeErr != nil {
+		for _, update := range bulk.Update {
+			failed[update.Pipeline.Id] = r.writeErr
+		}
+	}
+	return
+}
+
+func (r *batchRepo) DeleteRevision(_ string, _ int) (err error) {
+	r.calls = append(r.calls, "delete")
+	return
+}
+
+func TestBatchResponse(t *testing.T) {
+	items := []*batchItem{
+		{op: lib.PipelineBatchOperation{Op: lib.BatchOpCreate}, id: "new"},
+		{op: lib.PipelineBatchOperation{Op: lib.BatchOpUpdate}, id: "p1", err: lib.NewValidationError([]lib.Violation{{Path: "name", Message: MessageMustNotBeEmpty}})},
+		{op: lib.PipelineBatchOperation{Op: lib.BatchOpDelete}, id: "p2", err: mongo.ErrNoDocuments},
+	}
+
+	response := batchResponse(lib.BatchModeAtomic, items)
+	expected := []int{http.StatusFailedDependency, http.StatusBadRequest, http.StatusNotFound}
+	for i, result := range response.Results {
+		if result.Index != i || result.Status != expected[i] {
+			t.Errorf("unexpected result %d: %+v", i, result)
+		}
+	}
+	if response.Results[0].Id != "" {
+		t.Errorf("id of a pipeline that was not created reported: %+v", response.Results[0])
+	}
+	if len(response.Results[1].Violations) != 1 || response.Results[1].Violations[0].Path != "name" {
+		t.Errorf("unexpected violations: %+v", response.Results[1])
+	}
+
+	response = batchResponse(lib.BatchModeBestEffort, items)
+	if response.Results[0].Status != http.StatusOK || response.Results[0].Id != "new" {
+		t.Errorf("unexpected result: %+v", response.Results[0])
+	}
+}
+
+func TestBatchError(t *testing.T) {
+	status, message, _ := batchError(errors.New("connection refused"))
+	if status != http.StatusInternalServerError || message != http.StatusText(http.StatusInternalServerError) {
+		t.Errorf("internal error leaked: %d %s", status, message)
+	}
+	status, _, _ = batchError(lib.NewForbiddenError(errors.New(MessageMissingRights)))
+	if status != http.StatusForbidden {
+		t.Errorf("unexpected status %d", status)
+	}
+}
+
+func TestRegistry_BatchPipelinesRevisions(t *testing.T) {
+	util.InitStructLogger("error")
+	perm, err := permV2Client.NewTestClient(context.Background())
+	if err != nil {
+		t.Skip(err)
+	}
+	repo := &batchRepo{MockRepo: db.NewMockRepo()}
+	registry := NewRegistry(repo, perm)
+	if registry == nil {
+		t.Skip("permissions-v2 topic could not be set")
+	}
+	pipeline := testPipeline()
+	pipeline.Id = "p1"
+	_, err, _ = perm.SetPermission(permV2Client.InternalAdminToken, PermV2InstanceTopic, pipeline.Id, ownerPermissions(lib.Pipeline{UserId: "1"}, nil))
+	if err != nil {
+		t.Fatal(err)
+	}
+	request := lib.PipelineBatchRequest{Operations: []lib.PipelineBatchOperation{{Op: lib.BatchOpUpdate, Pipeline: &pipeline}}}
+
+	repo.insertErr = lib.NewConflictError(errors.New(db.MessageVersionConflict))
+	response, err := registry.BatchPipelines(request, "1", permV2Client.InternalAdminToken)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if response.Results[0].Status != http.StatusConflict || !slices.Equal(repo.calls, []string{"insert"}) {
+		t.Errorf("pipeline written despite conflicting revision: %v %v", response.Results[0], repo.calls)
+	}
+
+	repo.calls = nil
+	repo.insertErr = nil
+	repo.writeErr = lib.NewConflictError(errors.New(db.MessageVersionConflict))
+	if _, err = registry.BatchPipelines(request, "1", permV2Client.InternalAdminToken); err != nil {
+		t.Fatal(err)
+	}
+	if !slices.Equal(repo.calls, []string{"insert", "write", "delete"}) {
+		t.Errorf("revision of failed update not deleted: %v", repo.calls)
+	}
+}
@@ -0,0 +1,80 @@
+/*
+ * Copyright 2025 InfAI (CC SES)
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *      http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package service
+
+import (
+	"errors"
+	"maps"
+
+	"github.com/SENERGY-Platform/analytics-pipeline/lib"
+	permV2Client "github.com/SENERGY-Platform/permissions-v2/pkg/client"
+	"github.com/google/uuid"
+	"go.mongodb.org/mongo-driver/mongo"
+)
+
+// ClonePipeline copies a readable pipeline under a new id owned by the user. Operators get new
+// ids, references to the operators or the pipeline in topic names and filter values are
+// remapped like on import.
+func (r *Registry) ClonePipeline(id string, options lib.PipelineCloneOptions, userId string, auth string) (cloneId string, err error) {
+	source, err := r.GetPipeline(id, userId, auth)
+	if err != nil {
+		return
+	}
+	var sharing *permV2Client.ResourcePermissions
+	if options.CopyPermissions {
+		err = r.checkPermission(id, auth, permV2Client.Administrate)
+		if err != nil {
+			return
+		}
+		resource, err, _ := r.perm.GetResource(permV2Client.InternalAdminToken, PermV2InstanceTopic, id)
+		if err != nil {
+			return "", err
+		}
+		sharing = &resource.ResourcePermissions
+	}
+	cloneId = uuid.NewString()
+	clone, _, err := lib.PipelineFromBundle(lib.NewPipelineBundle(source), cloneId, uuid.NewString)
+	if err != nil {
+		return "", err
+	}
+	clone.Name = options.Name
+	if clone.Name == "" {
+		clone.Name = source.Name + " (copy)"
+	}
+	// the template reference is only kept if the user owns the template, otherwise rendering the
+	// clone would read a template of another user
+	if source.Template != nil {
+		_, err = r.repository.FindTemplate(source.Template.TemplateId, userId)
+		switch {
+		case err == nil:
+			reference := *source.Template
+			reference.Values = maps.Clone(reference.Values)
+			clone.Template = &reference
+		case !errors.Is(err, mongo.ErrNoDocuments):
+			return "", err
+		}
+	}
+	status := lib.PipelineStatusDraft
+	if options.Draft != nil && !*options.Draft {
+		status = lib.PipelineStatusStopped
+	}
+	err = r.savePipeline(clone, cloneId, userId, auth, status, sharing)
+	if err != nil {
+		return "", err
+	}
+	return
+}
@@ -18,6 +18,43 @@ package service
 
 const PermV2InstanceTopic = "analytics-pipelines"
 
+// MaxBatchOperations limits the number of operations of a pipeline batch.
+const MaxBatchOperations = 100
+
 const (
-	MessageMissingRights = "missing access rights"
+	MessageMissingRights              = "missing access rights"
+	MessageMustNotBeEmpty             = "must not be empty"
+	MessageMustNotBeNegative          = "must not be negative"
+	MessageNoOperators                = "must contain at least one operator"
+	MessageDuplicateOperatorId        = "duplicate operator id, already used by operators[%d]"
+	MessageOperatorCycle              = "operators form a cycle: %s"
+	MessageDanglingInputTopic         = "topic is neither produced inside the pipeline nor a known external source"
+	MessageRevisionWithoutSnapshot    = "revision has no pipeline snapshot"
+	MessageVersionMismatch            = "pipeline version does not match If-Match"
+	MessageUnsupportedPatchType       = "unsupported patch content type %q"
+	MessageUnknownStatus              = "unknown status %q"
+	MessageStatusTransitionNotAllowed = "status transition from %q to %q is not allowed"
+	MessageInvalidWebhookUrl          = "must be an absolute http or https url"
+	MessageWebhookHostNotAllowed      = "host is not allowed"
+	MessageUnknownEventType           = "unknown event type %q"
+	MessageWebhooksDisabled           = "webhooks are disabled"
+	MessageWebhookQueueFull           = "webhook queue was full"
+	MessageUnknownEventId             = "events after the last event id are not available, reload and reconnect without it"
+	MessageUnknownDirection           = "unknown direction %q, expected up or down"
+	MessageDownstreamPipelines        = "pipeline is consumed by %d downstream pipelines"
+	MessageUnknownExportFormat        = "unknown format %q, expected one of %s"
+	MessageInvalidParamName           = "must start with a letter or underscore followed by letters, digits or underscores"
+	MessageDuplicateParamName         = "duplicate parameter name, already used by params[%d]"
+	MessageUnknownParamType           = "unknown parameter type %q"
+	MessageUndeclaredParam            = "placeholder ${%s} is not declared in params"
+	MessageNotFromTemplate            = "pipeline was not created from a template"
+	MessageUnknownBatchMode           = "unknown mode %q, expected atomic or bestEffort"
+	MessageUnknownBatchOp             = "unknown op %q, expected create, update or delete"
+	MessageTooManyOperations          = "must not contain more than %d operations"
+	MessageDuplicateBatchPipeline     = "pipeline is already changed by operations[%d]"
+	MessageBatchNotApplied            = "not applied, another operation of the atomic batch failed"
+	MessageEmptyPermissionId          = "ids must not be empty"
+	MessageLastAdministrator          = "at least one user has to keep the administrate permission"
+	MessageOwnerPermissions           = "the owner keeps all permissions, transfer the pipeline to change the owner"
+	MessageNotOwner                   = "only the owner or an admin can transfer a pipeline"
 )
@@ -0,0 +1,146 @@
+/*
+ * Copyright 2025 InfAI (CC SES)
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *      http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package service
+
+import (
+	"context"
+	"encoding/json"
+	"time"
+
+	"github.com/SENERGY-Platform/analytics-pipeline/lib"
+	"github.com/SENERGY-Platform/analytics-pipeline/pkg/util"
+	"github.com/google/uuid"
+	"github.com/segmentio/kafka-go"
+)
+
+// outboxBatchSize limits the number of outbox events read from the database at once.
+const outboxBatchSize = 100
+
+// EventPublisher delivers serialized pipeline events to a message broker.
+type EventPublisher interface {
+	Publish(ctx context.Context, key []byte, value []byte) error
+	Close() error
+}
+
+type KafkaPublisher struct {
+	writer *kafka.Writer
+}
+
+func NewKafkaPublisher(bootstrap string, topic string) *KafkaPublisher {
+	return &KafkaPublisher{writer: &kafka.Writer{
+		Addr:                   kafka.TCP(bootstrap),
+		Topic:                  topic,
+		Balancer:               &kafka.Hash{},
+		BatchSize:              1,
+		MaxAttempts:            3,
+		RequiredAcks:           kafka.RequireAll,
+		AllowAutoTopicCreation: true,
+	}}
+}
+
+func (p *KafkaPublisher) Publish(ctx context.Context, key []byte, value []byte) error {
+	return p.writer.WriteMessages(ctx, kafka.Message{Key: key, Value: value, Time: time.Now()})
+}
+
+func (p *KafkaPublisher) Close() error {
+	return p.writer.Close()
+}
+
+// emitEvent stores a change event in the outbox and notifies open event streams and webhooks.
+// Failures are only logged, the change itself has already been persisted at this point.
+func (r *Registry) emitEvent(eventType string, pipeline lib.Pipeline, userId string) {
+	r.publishEvent(newEvent(eventType, pipeline, userId))
+}
+
+func newEvent(eventType string, pipeline lib.Pipeline, userId string) lib.PipelineEvent {
+	return lib.PipelineEvent{
+		Id:         uuid.NewString(),
+		Type:       eventType,
+		PipelineId: pipeline.Id,
+		UserId:     userId,
+		Revision:   pipeline.Version,
+		Timestamp:  time.Now(),
+		Pipeline:   &pipeline,
+	}
+}
+
+// publishEvent stores event in the outbox, which is kept for db.OutboxRetention if no publisher is started.
+// Streams and webhooks are notified even if storing fails.
+func (r *Registry) publishEvent(event lib.PipelineEvent) {
+	if r.hub != nil {
+		r.hub.publish(event)
+	}
+	r.enqueueWebhooks(event)
+	err := r.repository.InsertOutboxEvent(event)
+	if err != nil {
+		util.Logger.Error("could not store pipeline event", "error", err, "id", event.PipelineId, "type", event.Type)
+	}
+}
+
+// PublishOutbox publishes all pending outbox events in the order they were stored. Events are
+// removed from the outbox once published. On the first failure publishing stops, so that the
+// remaining events are retried in order on the next run.
+func (r *Registry) PublishOutbox(ctx context.Context, publisher EventPublisher) (err error) {
+	for {
+		events, err := r.repository.PendingOutboxEvents(outboxBatchSize)
+		if err != nil {
+			return err
+		}
+		for _, event := range events {
+			value, err := json.Marshal(event)
+			if err != nil {
+				return err
+			}
+			err = publisher.Publish(ctx, []byte(event.PipelineId), value)
+			if err != nil {
+				return err
+			}
+			err = r.repository.DeleteOutboxEvent(event.Id)
+			if err != nil {
+				return err
+			}
+		}
+		if len(events) < outboxBatchSize {
+			return nil
+		}
+	}
+}
+
+// StartEventPublisher periodically publishes the events of the outbox until ctx is done.
+// A non-positive interval disables publishing.
+func (r *Registry) StartEventPublisher(ctx context.Context, publisher EventPublisher, interval time.Duration) {
+	if interval <= 0 {
+		util.Logger.Warn("event publisher disabled", "interval", interval)
+		_ = publisher.Close()
+		return
+	}
+	go func() {
+		ticker := time.NewTicker(interval)
+		defer ticker.Stop()
+		defer publisher.Close()
+		for {
+			if err := r.PublishOutbox(ctx, publisher); err != nil {
+				util.Logger.Error("could not publish pipeline events", "error", err)
+			}
+			select {
+			case <-ctx.Done():
+				return
+			case <-ticker.C:
+			}
+		}
+	}()
+}
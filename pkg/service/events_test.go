@@ -0,0 +1,133 @@
+/*
+ * Copyright 2025 InfAI (CC SES)
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *      http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package service
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"slices"
+	"testing"
+
+	"github.com/SENERGY-Platform/analytics-pipeline/lib"
+	"github.com/SENERGY-Platform/analytics-pipeline/pkg/db"
+	"github.com/SENERGY-Platform/analytics-pipeline/pkg/util"
+)
+
+type fakeBroker struct {
+	down     bool
+	messages []lib.PipelineEvent
+}
+
+func (b *fakeBroker) Publish(_ context.Context, key []byte, value []byte) error {
+	if b.down {
+		return errors.New("broker not available")
+	}
+	var event lib.PipelineEvent
+	if err := json.Unmarshal(value, &event); err != nil {
+		return err
+	}
+	if string(key) != event.PipelineId {
+		return errors.New("unexpected key " + string(key))
+	}
+	b.messages = append(b.messages, event)
+	return nil
+}
+
+func (b *fakeBroker) Close() error {
+	return nil
+}
+
+type outboxRepo struct {
+	*db.MockRepo
+	down   bool
+	outbox []lib.PipelineEvent
+}
+
+func (r *outboxRepo) InsertOutboxEvent(event lib.PipelineEvent) error {
+	if r.down {
+		return errors.New("database not available")
+	}
+	r.outbox = append(r.outbox, event)
+	return nil
+}
+
+func (r *outboxRepo) PendingOutboxEvents(limit int64) ([]lib.PipelineEvent, error) {
+	return slices.Clone(r.outbox[:min(int(limit), len(r.outbox))]), nil
+}
+
+func (r *outboxRepo) DeleteOutboxEvent(id string) error {
+	r.outbox = slices.DeleteFunc(r.outbox, func(event lib.PipelineEvent) bool { return event.Id == id })
+	return nil
+}
+
+func TestRegistry_PublishOutbox(t *testing.T) {
+	repo := &outboxRepo{MockRepo: db.NewMockRepo()}
+	registry := &Registry{repository: repo}
+	broker := &fakeBroker{down: true}
+
+	pipeline := testPipeline()
+	pipeline.Id = "p1"
+	pipeline.Version = 1
+	registry.emitEvent(lib.PipelineEventCreated, pipeline, "user")
+	pipeline.Version = 2
+	registry.emitEvent(lib.PipelineEventUpdated, pipeline, "user")
+	registry.emitEvent(lib.PipelineEventDeleted, pipeline, "user")
+
+	if err := registry.PublishOutbox(context.Background(), broker); err == nil {
+		t.Fatal("expected error while broker is down")
+	}
+	if len(repo.outbox) != 3 {
+		t.Fatalf("events must be kept while broker is down, got %d", len(repo.outbox))
+	}
+
+	broker.down = false
+	if err := registry.PublishOutbox(context.Background(), broker); err != nil {
+		t.Fatal(err)
+	}
+	if len(repo.outbox) != 0 {
+		t.Errorf("outbox not emptied, %d events left", len(repo.outbox))
+	}
+	types := []string{}
+	for _, event := range broker.messages {
+		types = append(types, event.Type)
+	}
+	expected := []string{lib.PipelineEventCreated, lib.PipelineEventUpdated, lib.PipelineEventDeleted}
+	if !slices.Equal(types, expected) {
+		t.Errorf("unexpected order of events: got %v want %v", types, expected)
+	}
+	last := broker.messages[2]
+	if last.UserId != "user" || last.Revision != 2 || last.Pipeline == nil || last.Pipeline.Name != pipeline.Name {
+		t.Errorf("unexpected event content: %+v", last)
+	}
+}
+
+func TestRegistry_EmitEventOutboxDown(t *testing.T) {
+	util.InitStructLogger("error")
+	repo := &outboxRepo{MockRepo: db.NewMockRepo(), down: true}
+	registry := &Registry{repository: repo, hub: newEventHub()}
+	_, ch, _ := registry.hub.subscribe("")
+	registry.emitEvent(lib.PipelineEventCreated, testPipeline(), "user")
+	select {
+	case event := <-ch:
+		if event.Event.Type != lib.PipelineEventCreated {
+			t.Errorf("unexpected event: %+v", event.Event)
+		}
+	default:
+		t.Error("streams not notified while the outbox is down")
+	}
+}
@@ -0,0 +1,101 @@
+/*
+ * Copyright 2025 InfAI (CC SES)
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *      http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package service
+
+import (
+	"fmt"
+	"slices"
+	"strings"
+
+	"github.com/SENERGY-Platform/analytics-pipeline/lib"
+	"github.com/google/uuid"
+)
+
+// exporters render a pipeline in an export format together with the content type of the result.
+var exporters = map[string]struct {
+	contentType string
+	render      func(pipeline lib.Pipeline) ([]byte, error)
+}{
+	lib.ExportFormatDOT: {"text/vnd.graphviz; charset=utf-8", func(pipeline lib.Pipeline) ([]byte, error) {
+		return []byte(lib.RenderDOT(pipeline)), nil
+	}},
+	lib.ExportFormatMermaid: {"text/plain; charset=utf-8", func(pipeline lib.Pipeline) ([]byte, error) {
+		return []byte(lib.RenderMermaid(pipeline)), nil
+	}},
+	lib.ExportFormatJSON: {"application/json; charset=utf-8", func(pipeline lib.Pipeline) ([]byte, error) {
+		return lib.MarshalBundle(lib.NewPipelineBundle(pipeline), lib.ExportFormatJSON)
+	}},
+	lib.ExportFormatYAML: {"application/yaml; charset=utf-8", func(pipeline lib.Pipeline) ([]byte, error) {
+		return lib.MarshalBundle(lib.NewPipelineBundle(pipeline), lib.ExportFormatYAML)
+	}},
+}
+
+// ExportPipeline renders a readable pipeline in format and returns the result with its content type.
+// Without format, the pipeline is exported as JSON bundle.
+func (r *Registry) ExportPipeline(id string, userId string, auth string, format string) (content []byte, contentType string, err error) {
+	if format == "" {
+		format = lib.ExportFormatJSON
+	}
+	exporter, ok := exporters[format]
+	if !ok {
+		formats := []string{}
+		for f := range exporters {
+			formats = append(formats, f)
+		}
+		slices.Sort(formats)
+		v := &validator{}
+		v.add("format", fmt.Sprintf(MessageUnknownExportFormat, format, strings.Join(formats, ", ")))
+		return nil, "", v.err()
+	}
+	pipeline, err := r.GetPipeline(id, userId, auth)
+	if err != nil {
+		return
+	}
+	content, err = exporter.render(pipeline)
+	return content, exporter.contentType, err
+}
+
+// ImportPipeline creates a pipeline from a bundle in lib.ExportFormatJSON or lib.ExportFormatYAML.
+// The pipeline and its operators get new ids. With dryRun, the pipeline is only validated and
+// returned as it would be created.
+func (r *Registry) ImportPipeline(data []byte, format string, userId string, auth string, dryRun bool) (result lib.PipelineImportResult, err error) {
+	bundle, err := lib.UnmarshalBundle(data, format)
+	if err != nil {
+		return
+	}
+	pipeline, operatorIds, err := lib.PipelineFromBundle(bundle, uuid.NewString(), uuid.NewString)
+	if err != nil {
+		return
+	}
+	result = lib.PipelineImportResult{DryRun: dryRun, OperatorIds: operatorIds}
+	if dryRun {
+		err = r.validateNewPipeline(pipeline, auth)
+		if err != nil {
+			return
+		}
+		pipeline.UserId = userId
+		pipeline.Status = lib.PipelineStatusDraft
+		result.Pipeline = pipeline
+		return
+	}
+	err = r.savePipeline(pipeline, pipeline.Id, userId, auth, lib.PipelineStatusDraft, nil)
+	if err != nil {
+		return
+	}
+	result.Pipeline, err = r.repository.FindPipeline(pipeline.Id, userId)
+	return
+}
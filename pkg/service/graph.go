@@ -0,0 +1,84 @@
+/*
+ * Copyright 2025 InfAI (CC SES)
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *      http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package service
+
+import (
+	"fmt"
+	"slices"
+	"strings"
+
+	"github.com/SENERGY-Platform/analytics-pipeline/lib"
+	permV2Client "github.com/SENERGY-Platform/permissions-v2/pkg/client"
+)
+
+func (r *Registry) GetPipelineGraph(id string, userId string, auth string) (graph lib.PipelineGraph, err error) {
+	pipeline, err := r.GetPipeline(id, userId, auth)
+	if err != nil {
+		return
+	}
+	external, err := r.externalTopics(pipeline.Id, auth)
+	if err != nil {
+		return
+	}
+	return lib.BuildGraph(pipeline, external), nil
+}
+
+// externalTopics returns a check for input topics which are fed from outside the pipeline,
+// either by devices and imports or by operators of other pipelines readable with auth.
+func (r *Registry) externalTopics(pipelineId string, auth string) (func(topic lib.InputTopic) bool, error) {
+	ids, err, _ := r.perm.ListAccessibleResourceIds(auth, PermV2InstanceTopic, permV2Client.ListOptions{}, permV2Client.Read)
+	if err != nil {
+		return nil, err
+	}
+	outputTopics, err := r.repository.OutputTopics(pipelineId, ids)
+	if err != nil {
+		return nil, err
+	}
+	return externalTopicsOf(outputTopics), nil
+}
+
+func externalTopicsOf(outputTopics []string) func(topic lib.InputTopic) bool {
+	return func(topic lib.InputTopic) bool {
+		switch topic.FilterType {
+		case lib.FilterTypeDeviceId, lib.FilterTypeImportId:
+			return true
+		}
+		return slices.Contains(outputTopics, topic.Name)
+	}
+}
+
+// validateGraph rejects pipelines whose operators form a cycle or consume topics that are not
+// produced by a pipeline readable with auth. Operators with unconsumed output are only reported by GetPipelineGraph.
+func (r *Registry) validateGraph(pipeline lib.Pipeline, auth string) error {
+	external, err := r.externalTopics(pipeline.Id, auth)
+	if err != nil {
+		return err
+	}
+	return validateGraphWith(pipeline, external)
+}
+
+func validateGraphWith(pipeline lib.Pipeline, external func(topic lib.InputTopic) bool) error {
+	graph := lib.BuildGraph(pipeline, external)
+	v := &validator{}
+	if len(graph.Cycle) > 0 {
+		v.add("operators", fmt.Sprintf(MessageOperatorCycle, strings.Join(graph.Cycle, " -> ")))
+	}
+	for _, input := range graph.DanglingInputs {
+		v.add(fmt.Sprintf("operators[%d].inputTopics[%d].name", input.OperatorIndex, input.InputIndex), MessageDanglingInputTopic)
+	}
+	return v.err()
+}
@@ -0,0 +1,251 @@
+/*
+ * Copyright 2025 InfAI (CC SES)
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *      http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package service
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"time"
+
+	"github.com/SENERGY-Platform/analytics-pipeline/pkg/db"
+	"github.com/SENERGY-Platform/analytics-pipeline/pkg/util"
+	permV2Client "github.com/SENERGY-Platform/permissions-v2/pkg/client"
+	"github.com/google/uuid"
+	"go.mongodb.org/mongo-driver/mongo"
+)
+
+// journalLease is the time an operation is left to the request that began it, or to the
+// instance that claimed it, before it is considered interrupted and applied again.
+const journalLease = time.Minute
+
+// maxJournalBackoff caps the exponential backoff between attempts to apply an operation.
+const maxJournalBackoff = time.Hour
+
+type JournalConfig struct {
+	RetryInterval  time.Duration
+	InitialBackoff time.Duration
+}
+
+// beginOperations journals an operation per pipeline before the pipelines are changed in the
+// database and permissions-v2. Create operations grant the owner permissions unless permissions is set.
+func (r *Registry) beginOperations(action string, pipelineIds []string, permissions *permV2Client.ResourcePermissions) (operations []db.PipelineOperation, err error) {
+	for _, id := range pipelineIds {
+		operation := newOperation(action, id)
+		operation.Permissions = permissions
+		operations = append(operations, operation)
+	}
+	err = r.repository.InsertOperations(operations)
+	return
+}
+
+func newOperation(action string, pipelineId string) db.PipelineOperation {
+	now := time.Now()
+	return db.PipelineOperation{
+		Id:          uuid.NewString(),
+		Action:      action,
+		PipelineId:  pipelineId,
+		CreatedAt:   now,
+		NextAttempt: now.Add(journalLease),
+	}
+}
+
+func (r *Registry) beginOperation(action string, pipelineId string, permissions *permV2Client.ResourcePermissions) (operation db.PipelineOperation, err error) {
+	operations, err := r.beginOperations(action, []string{pipelineId}, permissions)
+	if err != nil {
+		return
+	}
+	return operations[0], nil
+}
+
+// endOperations removes operations whose changes are complete. Failures are only logged, since
+// applying the operations again does not change anything.
+func (r *Registry) endOperations(operations ...db.PipelineOperation) {
+	ids := make([]string, len(operations))
+	for i, operation := range operations {
+		ids[i] = operation.Id
+	}
+	err := r.repository.DeleteOperations(ids)
+	if err != nil {
+		util.Logger.Error("could not remove journal operations", "error", err, "ids", ids)
+	}
+}
+
+// settleOperation applies an operation and ends it. If that fails, the operation is left for
+// the journal worker to retry.
+func (r *Registry) settleOperation(operation db.PipelineOperation) (err error) {
+	err = r.applyOperation(operation)
+	if err != nil {
+		r.retryOperation(operation, err)
+		return
+	}
+	r.endOperations(operation)
+	return
+}
+
+// compensateOperation undoes an operation that failed halfway. Created pipelines are purged,
+// transferred pipelines are handed back.
+func (r *Registry) compensateOperation(operation db.PipelineOperation) {
+	switch operation.Action {
+	case db.OperationCreate:
+		operation.Action = db.OperationPurge
+	case db.OperationTransfer:
+		operation.Action = db.OperationRestoreOwner
+	}
+	err := r.repository.UpdateOperation(operation)
+	if err != nil {
+		// the operation is rolled forward instead
+		util.Logger.Error("could not journal compensation", "error", err, "id", operation.PipelineId)
+		return
+	}
+	err = r.settleOperation(operation)
+	if err != nil {
+		util.Logger.Error("could not compensate operation, will retry", "error", err, "action", operation.Action, "id", operation.PipelineId)
+	}
+}
+
+func (r *Registry) retryOperation(operation db.PipelineOperation, cause error) {
+	operation.LastError = cause.Error()
+	operation.NextAttempt = time.Now().Add(journalBackoff(r.journalBackoff, operation.Attempts))
+	err := r.repository.UpdateOperation(operation)
+	if err != nil {
+		util.Logger.Error("could not schedule journal operation", "error", err, "id", operation.PipelineId)
+	}
+}
+
+// journalBackoff doubles the initial backoff with every failed attempt.
+func journalBackoff(initial time.Duration, attempts int) time.Duration {
+	backoff := initial
+	for i := 1; i < attempts && backoff < maxJournalBackoff; i++ {
+		backoff *= 2
+	}
+	return min(backoff, maxJournalBackoff)
+}
+
+// applyOperation brings the database and permissions-v2 in line with the operation.
+func (r *Registry) applyOperation(operation db.PipelineOperation) (err error) {
+	id := operation.PipelineId
+	switch operation.Action {
+	case db.OperationCreate:
+		exists, err := r.repository.PipelineExists(id)
+		if err != nil {
+			return err
+		}
+		if !exists {
+			return r.purgeOperation(id)
+		}
+		_, err, code := r.perm.GetResource(permV2Client.InternalAdminToken, PermV2InstanceTopic, id)
+		if code != http.StatusNotFound {
+			// permissions that have been set may already be changed by the owner
+			return err
+		}
+		permissions := operation.Permissions
+		if permissions == nil {
+			pipeline, err := r.repository.FindPipeline(id, "")
+			if err != nil && !errors.Is(err, mongo.ErrNoDocuments) {
+				return err
+			}
+			owner := ownerPermissions(pipeline, nil)
+			permissions = &owner
+		}
+		_, err, _ = r.perm.SetPermission(permV2Client.InternalAdminToken, PermV2InstanceTopic, id, *permissions)
+		return err
+	case db.OperationPurge:
+		return r.purgeOperation(id)
+	case db.OperationTransfer:
+		return r.ownerOperation(id, operation.FromUserId, operation.ToUserId, func(current permV2Client.ResourcePermissions) permV2Client.ResourcePermissions {
+			return transferredPermissions(current, operation.FromUserId, operation.ToUserId, operation.KeepPermissions)
+		})
+	case db.OperationRestoreOwner:
+		return r.ownerOperation(id, operation.ToUserId, operation.FromUserId, func(current permV2Client.ResourcePermissions) permV2Client.ResourcePermissions {
+			return restoredPermissions(current, operation)
+		})
+	}
+	return nil
+}
+
+// ownerOperation hands a pipeline over from one user to another and updates its permissions. If the
+// pipeline is owned by neither, it has been purged or transferred again and is left untouched.
+func (r *Registry) ownerOperation(id string, from string, to string, update func(current permV2Client.ResourcePermissions) permV2Client.ResourcePermissions) (err error) {
+	_, err = r.repository.ChangePipelineOwner(id, from, to)
+	if errors.Is(err, mongo.ErrNoDocuments) {
+		return nil
+	}
+	if err != nil {
+		return
+	}
+	return r.updatePermissions(id, update)
+}
+
+func (r *Registry) purgeOperation(id string) (err error) {
+	err = r.repository.DeletePipeline(id, "", true)
+	if err != nil && !errors.Is(err, mongo.ErrNoDocuments) {
+		return
+	}
+	err = r.repository.DeleteRevisions(id)
+	if err != nil {
+		return
+	}
+	err, code := r.perm.RemoveResource(permV2Client.InternalAdminToken, PermV2InstanceTopic, id)
+	if code == http.StatusNotFound {
+		return nil
+	}
+	return
+}
+
+// RetryOperations applies all due operations of the journal.
+func (r *Registry) RetryOperations() (err error) {
+	for {
+		operation, err := r.repository.ClaimOperation(time.Now(), journalLease)
+		if errors.Is(err, mongo.ErrNoDocuments) {
+			return nil
+		}
+		if err != nil {
+			return err
+		}
+		err = r.settleOperation(operation)
+		if err != nil {
+			util.Logger.Error("could not apply journal operation", "error", err, "action", operation.Action, "id", operation.PipelineId, "attempts", operation.Attempts)
+			continue
+		}
+		util.Logger.Info("applied interrupted journal operation", "action", operation.Action, "id", operation.PipelineId)
+	}
+}
+
+// StartJournal periodically retries interrupted and failed operations until ctx is done.
+// A non-positive RetryInterval disables retries, operations are still journaled.
+func (r *Registry) StartJournal(ctx context.Context, config JournalConfig) {
+	r.journalBackoff = config.InitialBackoff
+	if config.RetryInterval <= 0 {
+		util.Logger.Warn("journal retries disabled", "interval", config.RetryInterval)
+		return
+	}
+	go func() {
+		ticker := time.NewTicker(config.RetryInterval)
+		defer ticker.Stop()
+		for {
+			if err := r.RetryOperations(); err != nil {
+				util.Logger.Error("could not retry journal operations", "error", err)
+			}
+			select {
+			case <-ctx.Done():
+				return
+			case <-ticker.C:
+			}
+		}
+	}()
+}
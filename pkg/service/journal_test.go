@@ -0,0 +1,98 @@
+/*
+ * Copyright 2025 InfAI (CC SES)
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *      http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package service
+
+import (
+	"context"
+	"net/http"
+	"testing"
+	"time"
+
+	"github.com/SENERGY-Platform/analytics-pipeline/pkg/db"
+	permV2Client "github.com/SENERGY-Platform/permissions-v2/pkg/client"
+)
+
+type journalRepo struct {
+	*db.MockRepo
+	exists bool
+}
+
+func (r *journalRepo) PipelineExists(_ string) (bool, error) {
+	return r.exists, nil
+}
+
+func TestApplyOperation(t *testing.T) {
+	perm, err := permV2Client.NewTestClient(context.Background())
+	if err != nil {
+		t.Skip(err)
+	}
+	repo := &journalRepo{MockRepo: db.NewMockRepo(), exists: true}
+	registry := NewRegistry(repo, perm)
+	if registry == nil {
+		t.Skip("permissions-v2 topic could not be set")
+	}
+	permissions := ownerPermissions(testPipeline(), nil)
+	permissions.UserPermissions["owner"] = permV2Client.PermissionsMap{Read: true, Write: true, Execute: true, Administrate: true}
+	operation := db.PipelineOperation{Action: db.OperationCreate, PipelineId: "p1", Permissions: &permissions}
+
+	// a stored pipeline gets its permissions
+	if err = registry.applyOperation(operation); err != nil {
+		t.Fatal(err)
+	}
+	resource, err, _ := perm.GetResource(permV2Client.InternalAdminToken, PermV2InstanceTopic, "p1")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !resource.UserPermissions["owner"].Administrate {
+		t.Errorf("unexpected permissions: %+v", resource.ResourcePermissions)
+	}
+	// applying again keeps permissions changed in the meantime
+	shared := ownerPermissions(testPipeline(), nil)
+	shared.UserPermissions["other"] = permV2Client.PermissionsMap{Read: true}
+	if _, err, _ = perm.SetPermission(permV2Client.InternalAdminToken, PermV2InstanceTopic, "p1", shared); err != nil {
+		t.Fatal(err)
+	}
+	if err = registry.applyOperation(operation); err != nil {
+		t.Fatal(err)
+	}
+	resource, _, _ = perm.GetResource(permV2Client.InternalAdminToken, PermV2InstanceTopic, "p1")
+	if !resource.UserPermissions["other"].Read {
+		t.Errorf("permissions overwritten: %+v", resource.ResourcePermissions)
+	}
+
+	// permissions of a pipeline that was not stored are removed
+	repo.exists = false
+	if err = registry.applyOperation(operation); err != nil {
+		t.Fatal(err)
+	}
+	_, _, code := perm.GetResource(permV2Client.InternalAdminToken, PermV2InstanceTopic, "p1")
+	if code != http.StatusNotFound {
+		t.Errorf("expected removed resource, got status %d", code)
+	}
+}
+
+func TestJournalBackoff(t *testing.T) {
+	expected := []time.Duration{10 * time.Second, 10 * time.Second, 20 * time.Second, 40 * time.Second}
+	for attempts, backoff := range expected {
+		if actual := journalBackoff(10*time.Second, attempts); actual != backoff {
+			t.Errorf("attempt %d: expected %s, got %s", attempts, backoff, actual)
+		}
+	}
+	if actual := journalBackoff(10*time.Second, 100); actual != maxJournalBackoff {
+		t.Errorf("expected backoff capped at %s, got %s", maxJournalBackoff, actual)
+	}
+}
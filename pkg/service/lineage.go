@@ -0,0 +1,119 @@
+/*
+ * Copyright 2025 InfAI (CC SES)
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *      http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package service
+
+import (
+	"fmt"
+	"slices"
+
+	"github.com/SENERGY-Platform/analytics-pipeline/lib"
+	permV2Client "github.com/SENERGY-Platform/permissions-v2/pkg/client"
+)
+
+// GetPipelineLineage walks the topic links of a pipeline across all pipelines the user can read.
+// An empty direction walks downstream, a depth of 0 is unlimited.
+func (r *Registry) GetPipelineLineage(id string, userId string, auth string, direction string, depth int) (lineage lib.PipelineLineage, err error) {
+	if direction == "" {
+		direction = lib.LineageDownstream
+	}
+	v := &validator{}
+	if direction != lib.LineageDownstream && direction != lib.LineageUpstream {
+		v.add("direction", fmt.Sprintf(MessageUnknownDirection, direction))
+	}
+	if depth < 0 {
+		v.add("depth", MessageMustNotBeNegative)
+	}
+	err = v.err()
+	if err != nil {
+		return
+	}
+	err = r.checkPermission(id, auth, permV2Client.Read)
+	if err != nil {
+		return
+	}
+	pipeline, err := r.repository.FindPipeline(id, userId)
+	if err != nil {
+		return
+	}
+	ids, err, _ := r.perm.ListAccessibleResourceIds(auth, PermV2InstanceTopic, permV2Client.ListOptions{}, permV2Client.Read)
+	if err != nil {
+		return
+	}
+	pipelines, err := r.linkedPipelines(pipeline, direction, depth, userId, false, ids)
+	if err != nil {
+		return
+	}
+	return lib.BuildLineage(id, pipelines, direction, depth), nil
+}
+
+// linkedPipelines loads the pipelines linked to root breadth-first, level by level. The level after
+// depth is loaded as well, so that lib.BuildLineage can report truncation. A depth of 0 is unlimited.
+func (r *Registry) linkedPipelines(root lib.Pipeline, direction string, depth int, userId string, admin bool, ids []string) (pipelines []lib.Pipeline, err error) {
+	pipelines = []lib.Pipeline{root}
+	loaded := map[string]bool{root.Id: true}
+	frontier := []lib.Pipeline{root}
+	for level := 0; len(frontier) > 0 && (depth == 0 || level <= depth); level++ {
+		var linked []lib.Pipeline
+		linked, err = r.repository.LinkedPipelines(lineageTopics(frontier, direction), direction, userId, admin, ids)
+		if err != nil {
+			return
+		}
+		frontier = nil
+		for _, pipeline := range linked {
+			if !loaded[pipeline.Id] {
+				loaded[pipeline.Id] = true
+				pipelines = append(pipelines, pipeline)
+				frontier = append(frontier, pipeline)
+			}
+		}
+	}
+	return
+}
+
+// lineageTopics returns the topics linking pipelines to others in direction, the output topics
+// downstream and the input topics upstream.
+func lineageTopics(pipelines []lib.Pipeline, direction string) (topics []string) {
+	for _, pipeline := range pipelines {
+		for _, operator := range pipeline.Operators {
+			if direction == lib.LineageDownstream {
+				if operator.OutputTopic != "" && !slices.Contains(topics, operator.OutputTopic) {
+					topics = append(topics, operator.OutputTopic)
+				}
+				continue
+			}
+			for _, topic := range operator.InputTopics {
+				if topic.Name != "" && !slices.Contains(topics, topic.Name) {
+					topics = append(topics, topic.Name)
+				}
+			}
+		}
+	}
+	return
+}
+
+// checkNoDownstream returns a ConflictError if any pipeline consumes an output topic of pipeline.
+// Pipelines of all users are considered, since they break regardless of who can read them.
+func (r *Registry) checkNoDownstream(pipeline lib.Pipeline) (err error) {
+	linked, err := r.repository.LinkedPipelines(lineageTopics([]lib.Pipeline{pipeline}, lib.LineageDownstream), lib.LineageDownstream, "", true, nil)
+	if err != nil {
+		return
+	}
+	if downstream := lib.DownstreamPipelines(pipeline, linked); len(downstream) > 0 {
+		return lib.NewConflictError(fmt.Errorf(MessageDownstreamPipelines, len(downstream)))
+	}
+	return
+}
@@ -0,0 +1,104 @@
+/*
+ * Copyright 2025 InfAI (CC SES)
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *      http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package service
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+
+	"github.com/SENERGY-Platform/analytics-pipeline/lib"
+	permV2Client "github.com/SENERGY-Platform/permissions-v2/pkg/client"
+	jsonpatch "github.com/evanphx/json-patch/v5"
+)
+
+const (
+	ContentTypeMergePatch = "application/merge-patch+json"
+	ContentTypeJSONPatch  = "application/json-patch+json"
+	ContentTypeJSON       = "application/json"
+)
+
+// PatchPipeline applies a RFC 7386 merge patch or a RFC 6902 JSON patch to the stored pipeline and
+// updates it like UpdatePipeline. For plain application/json the patch type is derived from the body,
+// arrays are treated as JSON patch and objects as merge patch.
+func (r *Registry) PatchPipeline(id string, patch []byte, contentType string, userId string, auth string, ifMatch *int) (updated lib.Pipeline, err error) {
+	err = r.checkPermission(id, auth, permV2Client.Write)
+	if err != nil {
+		return
+	}
+	current, err := r.repository.FindPipeline(id, userId)
+	if err != nil {
+		return
+	}
+	doc, err := json.Marshal(current)
+	if err != nil {
+		return
+	}
+	if contentType == ContentTypeJSON {
+		contentType = ContentTypeMergePatch
+		if trimmed := bytes.TrimSpace(patch); len(trimmed) > 0 && trimmed[0] == '[' {
+			contentType = ContentTypeJSONPatch
+		}
+	}
+	switch contentType {
+	case ContentTypeMergePatch:
+		doc, err = applyMergePatch(doc, patch)
+	case ContentTypeJSONPatch:
+		doc, err = applyJSONPatch(doc, patch)
+	default:
+		err = lib.NewInputError(fmt.Errorf(MessageUnsupportedPatchType, contentType))
+	}
+	if err != nil {
+		return
+	}
+	var patched lib.Pipeline
+	err = json.Unmarshal(doc, &patched)
+	if err != nil {
+		return updated, lib.NewInputError(err)
+	}
+	// the patch is applied to the stored version, concurrent updates are detected by the conditional update
+	patched.Id = current.Id
+	patched.Version = current.Version
+	return r.UpdatePipeline(patched, userId, auth, ifMatch)
+}
+
+// applyMergePatch applies a RFC 7386 merge patch to a JSON document.
+func applyMergePatch(doc []byte, patch []byte) ([]byte, error) {
+	result, err := jsonpatch.MergePatch(doc, patch)
+	if err != nil {
+		return nil, lib.NewInputError(err)
+	}
+	return result, nil
+}
+
+// applyJSONPatch applies a RFC 6902 JSON patch to a JSON document. Failing test operations
+// result in a ConflictError, malformed patches in an InputError.
+func applyJSONPatch(doc []byte, patch []byte) ([]byte, error) {
+	decoded, err := jsonpatch.DecodePatch(patch)
+	if err != nil {
+		return nil, lib.NewInputError(err)
+	}
+	result, err := decoded.Apply(doc)
+	if errors.Is(err, jsonpatch.ErrTestFailed) {
+		return nil, lib.NewConflictError(err)
+	}
+	if err != nil {
+		return nil, lib.NewInputError(err)
+	}
+	return result, nil
+}
@@ -0,0 +1,81 @@
+/*
+ * Copyright 2025 InfAI (CC SES)
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *      http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package service
+
+import (
+	"encoding/json"
+	"errors"
+	"reflect"
+	"testing"
+
+	"github.com/SENERGY-Platform/analytics-pipeline/lib"
+)
+
+func equalJSON(t *testing.T, got []byte, want string) {
+	t.Helper()
+	var a, b any
+	if err := json.Unmarshal(got, &a); err != nil {
+		t.Fatal(err)
+	}
+	if err := json.Unmarshal([]byte(want), &b); err != nil {
+		t.Fatal(err)
+	}
+	if !reflect.DeepEqual(a, b) {
+		t.Errorf("unexpected document: got %s want %s", got, want)
+	}
+}
+
+func TestMergePatch(t *testing.T) {
+	doc := `{"name":"a","metrics":true,"operators":[{"id":"1","config":{"threshold":"5","unit":"C"}}]}`
+	patch := `{"name":"b","metrics":null,"description":"d"}`
+	result, err := applyMergePatch([]byte(doc), []byte(patch))
+	if err != nil {
+		t.Fatal(err)
+	}
+	equalJSON(t, result, `{"name":"b","description":"d","operators":[{"id":"1","config":{"threshold":"5","unit":"C"}}]}`)
+}
+
+func TestJSONPatch(t *testing.T) {
+	doc := `{"name":"a","operators":[{"id":"1","config":{"threshold":"5"}},{"id":"2"}]}`
+	patch := `[
+		{"op":"test","path":"/operators/0/id","value":"1"},
+		{"op":"replace","path":"/operators/0/config/threshold","value":"10"},
+		{"op":"add","path":"/operators/-","value":{"id":"3"}},
+		{"op":"remove","path":"/operators/1"},
+		{"op":"copy","from":"/name","path":"/description"},
+		{"op":"move","from":"/name","path":"/flowId"}
+	]`
+	result, err := applyJSONPatch([]byte(doc), []byte(patch))
+	if err != nil {
+		t.Fatal(err)
+	}
+	equalJSON(t, result, `{"description":"a","flowId":"a","operators":[{"id":"1","config":{"threshold":"10"}},{"id":"3"}]}`)
+}
+
+func TestJSONPatch_Errors(t *testing.T) {
+	doc := []byte(`{"name":"a","operators":[]}`)
+	_, err := applyJSONPatch(doc, []byte(`[{"op":"test","path":"/name","value":"b"}]`))
+	var ce *lib.ConflictError
+	if !errors.As(err, &ce) {
+		t.Errorf("expected conflict error, got %v", err)
+	}
+	_, err = applyJSONPatch(doc, []byte(`[{"op":"replace","path":"/operators/0","value":{}}]`))
+	var ie *lib.InputError
+	if !errors.As(err, &ie) {
+		t.Errorf("expected input error, got %v", err)
+	}
+}
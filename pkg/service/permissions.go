@@ -0,0 +1,123 @@
+/*
+ * Copyright 2025 InfAI (CC SES)
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *      http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package service
+
+import (
+	"github.com/SENERGY-Platform/analytics-pipeline/lib"
+	permV2Client "github.com/SENERGY-Platform/permissions-v2/pkg/client"
+	permV2Model "github.com/SENERGY-Platform/permissions-v2/pkg/model"
+)
+
+// GetPipelinePermissions returns the permissions granted on a pipeline the user can administrate.
+func (r *Registry) GetPipelinePermissions(id string, userId string, auth string) (permissions lib.PipelinePermissions, err error) {
+	err = r.checkPermission(id, auth, permV2Client.Administrate)
+	if err != nil {
+		return
+	}
+	_, err = r.repository.FindPipeline(id, userId)
+	if err != nil {
+		return
+	}
+	resource, err, _ := r.perm.GetResource(permV2Client.InternalAdminToken, PermV2InstanceTopic, id)
+	if err != nil {
+		return
+	}
+	return fromResourcePermissions(resource.ResourcePermissions), nil
+}
+
+// SetPipelinePermissions replaces the permissions granted on a pipeline the user can administrate.
+// The owner keeps all permissions, so that a pipeline always has an administrator.
+func (r *Registry) SetPipelinePermissions(id string, permissions lib.PipelinePermissions, userId string, auth string) (updated lib.PipelinePermissions, err error) {
+	err = r.checkPermission(id, auth, permV2Client.Administrate)
+	if err != nil {
+		return
+	}
+	pipeline, err := r.repository.FindPipeline(id, userId)
+	if err != nil {
+		return
+	}
+	err = validatePermissions(pipeline, permissions)
+	if err != nil {
+		return
+	}
+	result, err, _ := r.perm.SetPermission(permV2Client.InternalAdminToken, PermV2InstanceTopic, id, toResourcePermissions(permissions))
+	if err != nil {
+		return
+	}
+	return fromResourcePermissions(result), nil
+}
+
+func validatePermissions(pipeline lib.Pipeline, permissions lib.PipelinePermissions) error {
+	v := &validator{}
+	kinds := []struct {
+		path        string
+		permissions map[string]lib.PermissionsMap
+	}{
+		{"userPermissions", permissions.UserPermissions},
+		{"groupPermissions", permissions.GroupPermissions},
+		{"rolePermissions", permissions.RolePermissions},
+	}
+	for _, kind := range kinds {
+		if _, ok := kind.permissions[""]; ok {
+			v.add(kind.path, MessageEmptyPermissionId)
+		}
+	}
+	administrators := 0
+	for _, permission := range permissions.UserPermissions {
+		if permission.Administrate {
+			administrators++
+		}
+	}
+	if administrators == 0 {
+		v.add("userPermissions", MessageLastAdministrator)
+	}
+	owner := permV2Model.PermissionsMap(permissions.UserPermissions[pipeline.UserId])
+	if pipeline.UserId != "" && !fullPermissions(owner) {
+		v.add("userPermissions."+pipeline.UserId, MessageOwnerPermissions)
+	}
+	return v.err()
+}
+
+func fromResourcePermissions(resource permV2Client.ResourcePermissions) lib.PipelinePermissions {
+	convert := func(permissions map[string]permV2Model.PermissionsMap) map[string]lib.PermissionsMap {
+		result := map[string]lib.PermissionsMap{}
+		for id, permission := range permissions {
+			result[id] = lib.PermissionsMap(permission)
+		}
+		return result
+	}
+	return lib.PipelinePermissions{
+		UserPermissions:  convert(resource.UserPermissions),
+		GroupPermissions: convert(resource.GroupPermissions),
+		RolePermissions:  convert(resource.RolePermissions),
+	}
+}
+
+func toResourcePermissions(permissions lib.PipelinePermissions) permV2Client.ResourcePermissions {
+	convert := func(permissions map[string]lib.PermissionsMap) map[string]permV2Model.PermissionsMap {
+		result := map[string]permV2Model.PermissionsMap{}
+		for id, permission := range permissions {
+			result[id] = permV2Model.PermissionsMap(permission)
+		}
+		return result
+	}
+	return permV2Client.ResourcePermissions{
+		UserPermissions:  convert(permissions.UserPermissions),
+		GroupPermissions: convert(permissions.GroupPermissions),
+		RolePermissions:  convert(permissions.RolePermissions),
+	}
+}
@@ -0,0 +1,68 @@
+/*
+ * Copyright 2025 InfAI (CC SES)
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *      http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package service
+
+import (
+	"errors"
+	"slices"
+	"testing"
+
+	"github.com/SENERGY-Platform/analytics-pipeline/lib"
+)
+
+func TestValidatePermissions(t *testing.T) {
+	pipeline := lib.Pipeline{Id: "p1", UserId: "owner"}
+	all := lib.PermissionsMap{Read: true, Write: true, Execute: true, Administrate: true}
+	tests := []struct {
+		name        string
+		permissions lib.PipelinePermissions
+		paths       []string
+	}{
+		{"valid", lib.PipelinePermissions{
+			UserPermissions:  map[string]lib.PermissionsMap{"owner": all, "other": {Read: true}},
+			GroupPermissions: map[string]lib.PermissionsMap{"g1": {Read: true, Execute: true}},
+		}, nil},
+		{"last administrator", lib.PipelinePermissions{
+			UserPermissions: map[string]lib.PermissionsMap{"owner": {Read: true}},
+			RolePermissions: map[string]lib.PermissionsMap{"admin": all},
+		}, []string{"userPermissions", "userPermissions.owner"}},
+		{"owner removed", lib.PipelinePermissions{
+			UserPermissions: map[string]lib.PermissionsMap{"other": all},
+		}, []string{"userPermissions.owner"}},
+		{"empty id", lib.PipelinePermissions{
+			UserPermissions:  map[string]lib.PermissionsMap{"owner": all},
+			GroupPermissions: map[string]lib.PermissionsMap{"": {Read: true}},
+		}, []string{"groupPermissions"}},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			err := validatePermissions(pipeline, test.permissions)
+			var paths []string
+			var ve *lib.ValidationError
+			if errors.As(err, &ve) {
+				for _, violation := range ve.Violations {
+					paths = append(paths, violation.Path)
+				}
+			} else if err != nil {
+				t.Fatal(err)
+			}
+			if !slices.Equal(paths, test.paths) {
+				t.Errorf("expected violations at %v, got %v", test.paths, paths)
+			}
+		})
+	}
+}
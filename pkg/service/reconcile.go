@@ -0,0 +1,222 @@
+/*
+ * Copyright 2025 InfAI (CC SES)
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *      http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package service
+
+import (
+	"context"
+	"slices"
+	"sync"
+	"time"
+
+	"github.com/SENERGY-Platform/analytics-pipeline/lib"
+	"github.com/SENERGY-Platform/analytics-pipeline/pkg/util"
+	permV2Client "github.com/SENERGY-Platform/permissions-v2/pkg/client"
+)
+
+// reconcilePageSize is the number of pipelines and permissions-v2 resources compared at once.
+const reconcilePageSize = 100
+
+type reconciler struct {
+	// running serializes reconciliations, mu guards metrics
+	running sync.Mutex
+	mu      sync.Mutex
+	metrics lib.ReconcileMetrics
+}
+
+// Reconcile makes the permissions-v2 resources match the stored pipelines. Pipelines without a
+// resource get one granting the owner full permissions, resources lacking the permissions of the
+// owner are repaired while keeping other permissions, and resources without a pipeline are
+// deleted. With dryRun, the report lists the changes without applying them. Pipelines created
+// within the journal lease are skipped, their permissions are handled by the journal.
+func (r *Registry) Reconcile(dryRun bool) (report lib.ReconcileReport, err error) {
+	r.reconciler.running.Lock()
+	defer r.reconciler.running.Unlock()
+	report = lib.ReconcileReport{
+		DryRun:    dryRun,
+		StartedAt: time.Now(),
+		Created:   []string{},
+		Repaired:  []string{},
+		Deleted:   []string{},
+		Failed:    []string{},
+	}
+	err = r.reconcilePipelines(&report)
+	if err == nil {
+		err = r.reconcileResources(&report)
+	}
+	report.DurationMs = time.Since(report.StartedAt).Milliseconds()
+	r.reconciler.record(report, err)
+	if err != nil {
+		util.Logger.Error("could not reconcile pipeline permissions", "error", err, "dryRun", dryRun)
+		return
+	}
+	util.Logger.Info("reconciled pipeline permissions", "dryRun", dryRun, "pipelines", report.Pipelines, "resources", report.Resources,
+		"created", len(report.Created), "repaired", len(report.Repaired), "deleted", len(report.Deleted), "failed", len(report.Failed),
+		"durationMs", report.DurationMs)
+	return
+}
+
+func (r *Registry) reconcilePipelines(report *lib.ReconcileReport) (err error) {
+	afterId := ""
+	for {
+		pipelines, err := r.repository.PipelineOwners(afterId, reconcilePageSize)
+		if err != nil {
+			return err
+		}
+		if len(pipelines) == 0 {
+			return nil
+		}
+		afterId = pipelines[len(pipelines)-1].Id
+		report.Pipelines += len(pipelines)
+		ids := make([]string, len(pipelines))
+		for i, pipeline := range pipelines {
+			ids[i] = pipeline.Id
+		}
+		resources, err, _ := r.perm.ListResourcesWithAdminPermission(permV2Client.InternalAdminToken, PermV2InstanceTopic, permV2Client.ListOptions{Ids: ids})
+		if err != nil {
+			return err
+		}
+		byId := map[string]permV2Client.Resource{}
+		for _, resource := range resources {
+			byId[resource.Id] = resource
+		}
+		for _, pipeline := range pipelines {
+			if time.Since(pipeline.CreatedAt) < journalLease {
+				continue
+			}
+			resource, ok := byId[pipeline.Id]
+			changes := &report.Created
+			permissions := ownerPermissions(pipeline, nil)
+			if ok {
+				if hasOwnerPermissions(pipeline, resource) {
+					continue
+				}
+				changes = &report.Repaired
+				permissions = ownerPermissions(pipeline, &resource.ResourcePermissions)
+			}
+			if !report.DryRun {
+				_, err, _ = r.perm.SetPermission(permV2Client.InternalAdminToken, PermV2InstanceTopic, pipeline.Id, permissions)
+				if err != nil {
+					util.Logger.Error("could not reconcile pipeline permissions", "error", err, "id", pipeline.Id)
+					report.Failed = append(report.Failed, pipeline.Id)
+					continue
+				}
+			}
+			*changes = append(*changes, pipeline.Id)
+		}
+		if len(pipelines) < reconcilePageSize {
+			return nil
+		}
+	}
+}
+
+func hasOwnerPermissions(pipeline lib.Pipeline, resource permV2Client.Resource) bool {
+	return fullPermissions(resource.UserPermissions[pipeline.UserId])
+}
+
+func fullPermissions(permissions permV2Client.PermissionsMap) bool {
+	return permissions.Read && permissions.Write && permissions.Execute && permissions.Administrate
+}
+
+// reconcileResources deletes the resources without pipeline. They are collected first, so that
+// deleting them does not shift the pages of the listing.
+func (r *Registry) reconcileResources(report *lib.ReconcileReport) (err error) {
+	var orphans []string
+	for offset := int64(0); ; offset += reconcilePageSize {
+		ids, err, _ := r.perm.AdminListResourceIds(permV2Client.InternalAdminToken, PermV2InstanceTopic, permV2Client.ListOptions{Limit: reconcilePageSize, Offset: offset})
+		if err != nil {
+			return err
+		}
+		report.Resources += len(ids)
+		existing, err := r.repository.ExistingPipelineIds(ids)
+		if err != nil {
+			return err
+		}
+		for _, id := range ids {
+			if !slices.Contains(existing, id) {
+				orphans = append(orphans, id)
+			}
+		}
+		if len(ids) < reconcilePageSize {
+			break
+		}
+	}
+	for _, id := range orphans {
+		if !report.DryRun {
+			err, _ = r.perm.RemoveResource(permV2Client.InternalAdminToken, PermV2InstanceTopic, id)
+			if err != nil {
+				util.Logger.Error("could not delete permissions of missing pipeline", "error", err, "id", id)
+				report.Failed = append(report.Failed, id)
+				continue
+			}
+		}
+		report.Deleted = append(report.Deleted, id)
+	}
+	return nil
+}
+
+func (rc *reconciler) record(report lib.ReconcileReport, err error) {
+	rc.mu.Lock()
+	defer rc.mu.Unlock()
+	if report.DryRun {
+		rc.metrics.DryRuns++
+	} else {
+		rc.metrics.Runs++
+	}
+	if err != nil {
+		rc.metrics.FailedRuns++
+		rc.metrics.LastError = err.Error()
+		return
+	}
+	if report.DryRun {
+		return
+	}
+	rc.metrics.LastError = ""
+	rc.metrics.Created += len(report.Created)
+	rc.metrics.Repaired += len(report.Repaired)
+	rc.metrics.Deleted += len(report.Deleted)
+	rc.metrics.Failed += len(report.Failed)
+	rc.metrics.LastRun = &report
+}
+
+// GetReconcileMetrics returns the metrics of the reconciliations since the service started.
+func (r *Registry) GetReconcileMetrics() lib.ReconcileMetrics {
+	r.reconciler.mu.Lock()
+	defer r.reconciler.mu.Unlock()
+	return r.reconciler.metrics
+}
+
+// StartReconciler reconciles the permissions right away and then every interval until ctx is done.
+// A non-positive interval disables the reconciler.
+func (r *Registry) StartReconciler(ctx context.Context, interval time.Duration) {
+	if interval <= 0 {
+		util.Logger.Warn("reconciler disabled", "interval", interval)
+		return
+	}
+	go func() {
+		ticker := time.NewTicker(interval)
+		defer ticker.Stop()
+		for {
+			// failures are logged and recorded by Reconcile
+			_, _ = r.Reconcile(false)
+			select {
+			case <-ctx.Done():
+				return
+			case <-ticker.C:
+			}
+		}
+	}()
+}
@@ -0,0 +1,118 @@
+/*
+ * Copyright 2025 InfAI (CC SES)
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *      http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package service
+
+import (
+	"context"
+	"net/http"
+	"slices"
+	"testing"
+	"time"
+
+	"github.com/SENERGY-Platform/analytics-pipeline/lib"
+	"github.com/SENERGY-Platform/analytics-pipeline/pkg/db"
+	"github.com/SENERGY-Platform/analytics-pipeline/pkg/util"
+	permV2Client "github.com/SENERGY-Platform/permissions-v2/pkg/client"
+)
+
+type reconcileRepo struct {
+	*db.MockRepo
+	pipelines []lib.Pipeline
+}
+
+func (r *reconcileRepo) PipelineOwners(afterId string, _ int64) (pipelines []lib.Pipeline, err error) {
+	if afterId != "" {
+		return nil, nil
+	}
+	return r.pipelines, nil
+}
+
+func (r *reconcileRepo) ExistingPipelineIds(ids []string) (existing []string, err error) {
+	for _, pipeline := range r.pipelines {
+		if slices.Contains(ids, pipeline.Id) {
+			existing = append(existing, pipeline.Id)
+		}
+	}
+	return
+}
+
+func TestReconcile(t *testing.T) {
+	util.InitStructLogger("error")
+	perm, err := permV2Client.NewTestClient(context.Background())
+	if err != nil {
+		t.Skip(err)
+	}
+	repo := &reconcileRepo{MockRepo: db.NewMockRepo(), pipelines: []lib.Pipeline{
+		{Id: "missing", UserId: "u1"},
+		{Id: "broken", UserId: "u2"},
+		{Id: "new", UserId: "u3", CreatedAt: time.Now()},
+	}}
+	registry := NewRegistry(repo, perm)
+	if registry == nil {
+		t.Skip("permissions-v2 topic could not be set")
+	}
+	shared := ownerPermissions(lib.Pipeline{UserId: "other"}, nil)
+	for _, id := range []string{"broken", "orphan"} {
+		if _, err, _ = perm.SetPermission(permV2Client.InternalAdminToken, PermV2InstanceTopic, id, shared); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	check := func(report lib.ReconcileReport) {
+		t.Helper()
+		if !slices.Equal(report.Created, []string{"missing"}) || !slices.Equal(report.Repaired, []string{"broken"}) ||
+			!slices.Equal(report.Deleted, []string{"orphan"}) || len(report.Failed) > 0 {
+			t.Errorf("unexpected report: %+v", report)
+		}
+	}
+	report, err := registry.Reconcile(true)
+	if err != nil {
+		t.Fatal(err)
+	}
+	check(report)
+	if _, _, code := perm.GetResource(permV2Client.InternalAdminToken, PermV2InstanceTopic, "missing"); code != http.StatusNotFound {
+		t.Errorf("dry run created resource, status %d", code)
+	}
+
+	report, err = registry.Reconcile(false)
+	if err != nil {
+		t.Fatal(err)
+	}
+	check(report)
+	resource, err, _ := perm.GetResource(permV2Client.InternalAdminToken, PermV2InstanceTopic, "broken")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !hasOwnerPermissions(repo.pipelines[1], resource) || !resource.UserPermissions["other"].Administrate {
+		t.Errorf("unexpected repaired permissions: %+v", resource.ResourcePermissions)
+	}
+	if _, _, code := perm.GetResource(permV2Client.InternalAdminToken, PermV2InstanceTopic, "orphan"); code != http.StatusNotFound {
+		t.Errorf("orphaned resource not deleted, status %d", code)
+	}
+
+	report, err = registry.Reconcile(false)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(report.Created)+len(report.Repaired)+len(report.Deleted) > 0 {
+		t.Errorf("second run changed resources: %+v", report)
+	}
+	metrics := registry.GetReconcileMetrics()
+	if metrics.Runs != 2 || metrics.DryRuns != 1 || metrics.Created != 1 || metrics.Repaired != 1 || metrics.Deleted != 1 {
+		t.Errorf("unexpected metrics: %+v", metrics)
+	}
+}
@@ -18,14 +18,11 @@ package service
 
 import (
 	"errors"
-	"fmt"
 	"maps"
-	"slices"
 	"time"
 
 	"github.com/SENERGY-Platform/analytics-pipeline/lib"
 	"github.com/SENERGY-Platform/analytics-pipeline/pkg/db"
-	"github.com/SENERGY-Platform/analytics-pipeline/pkg/util"
 	permV2Client "github.com/SENERGY-Platform/permissions-v2/pkg/client"
 	permV2Model "github.com/SENERGY-Platform/permissions-v2/pkg/model"
 	"github.com/google/uuid"
@@ -34,6 +31,11 @@ import (
 type Registry struct {
 	repository db.PipelineRepository
 	perm       permV2Client.Client
+	hub        *eventHub
+	webhooks   *webhookDispatcher
+	reconciler *reconciler
+	// journalBackoff is the delay before a failed journal operation is retried the first time
+	journalBackoff time.Duration
 }
 
 func NewRegistry(repository db.PipelineRepository, perm permV2Client.Client) *Registry {
@@ -53,57 +55,7 @@ func NewRegistry(repository db.PipelineRepository, perm permV2Client.Client) *Re
 	if err != nil {
 		return nil
 	}
-	return &Registry{repository, perm}
-}
-
-func (r *Registry) ValidateOperatorPermissions() (err error) {
-	util.Logger.Debug("validate pipeline permissions")
-	resp, err := r.GetPipelinesAdmin("", nil)
-	if err != nil {
-		return
-	}
-	permResources, err, _ := r.perm.ListResourcesWithAdminPermission(permV2Client.InternalAdminToken, PermV2InstanceTopic, permV2Client.ListOptions{})
-	if err != nil {
-		return
-	}
-	permResourceMap := map[string]permV2Client.Resource{}
-	for _, permResource := range permResources {
-		permResourceMap[permResource.Id] = permResource
-	}
-
-	dbIds := []string{}
-	for _, pipeline := range resp.Data {
-		permissions := permV2Client.ResourcePermissions{
-			UserPermissions:  map[string]permV2Client.PermissionsMap{},
-			GroupPermissions: map[string]permV2Client.PermissionsMap{},
-			RolePermissions:  map[string]permV2Model.PermissionsMap{},
-		}
-		dbIds = append(dbIds, pipeline.Id)
-		resource, ok := permResourceMap[pipeline.Id]
-		if ok {
-			permissions.UserPermissions = resource.ResourcePermissions.UserPermissions
-			permissions.GroupPermissions = resource.GroupPermissions
-			permissions.RolePermissions = resource.ResourcePermissions.RolePermissions
-		}
-		SetDefaultPermissions(pipeline, permissions)
-
-		_, err, _ = r.perm.SetPermission(permV2Client.InternalAdminToken, PermV2InstanceTopic, pipeline.Id, permissions)
-		if err != nil {
-			return
-		}
-	}
-	permResourceIds := maps.Keys(permResourceMap)
-
-	for permResouceId := range permResourceIds {
-		if !slices.Contains(dbIds, permResouceId) {
-			err, _ = r.perm.RemoveResource(permV2Client.InternalAdminToken, PermV2InstanceTopic, permResouceId)
-			if err != nil {
-				return
-			}
-			util.Logger.Debug(fmt.Sprintf("%s exists only in permissions-v2, now deleted", permResouceId))
-		}
-	}
-	return
+	return &Registry{repository: repository, perm: perm, hub: newEventHub(), reconciler: &reconciler{}}
 }
 
 func SetDefaultPermissions(instance lib.Pipeline, permissions permV2Client.ResourcePermissions) {
@@ -115,49 +67,158 @@ func SetDefaultPermissions(instance lib.Pipeline, permissions permV2Client.Resou
 	}
 }
 
-func (r *Registry) SavePipeline(pipeline lib.Pipeline, userId string) (id string, err error) {
+func (r *Registry) SavePipeline(pipeline lib.Pipeline, userId string, auth string) (id string, err error) {
 	// Create new uuid to use as pipeline id
 	uid := uuid.New()
 	id = uid.String()
-	pipeline.Id = id
-	pipeline.UserId = userId
-	pipeline.CreatedAt = time.Now()
-	pipeline.UpdatedAt = time.Now()
+	pipeline.Template = nil
+	return id, r.savePipeline(pipeline, id, userId, auth, lib.PipelineStatusDraft, nil)
+}
+
+// savePipeline stores a new pipeline under id, which allows ids to be referenced by the pipeline itself.
+// The pipeline starts in status. The owner gets full permissions, in addition to sharing if set.
+// Input topics may be produced by pipelines readable with auth.
+func (r *Registry) savePipeline(pipeline lib.Pipeline, id string, userId string, auth string, status string, sharing *permV2Client.ResourcePermissions) (err error) {
+	err = r.validateNewPipeline(pipeline, auth)
+	if err != nil {
+		return
+	}
+	pipeline = newPipeline(pipeline, id, userId, status)
+	permissions := ownerPermissions(pipeline, sharing)
+	operation, err := r.beginOperation(db.OperationCreate, pipeline.Id, &permissions)
+	if err != nil {
+		return
+	}
 	err = r.repository.InsertPipeline(pipeline)
 	if err != nil {
+		// the pipeline might be stored nevertheless, the journal makes both stores agree
+		_ = r.settleOperation(operation)
+		return
+	}
+	err = r.saveRevision(pipeline, userId, 0)
+	if err == nil {
+		_, err, _ = r.perm.SetPermission(permV2Client.InternalAdminToken, PermV2InstanceTopic, pipeline.Id, permissions)
+	}
+	if err != nil {
+		r.compensateOperation(operation)
 		return
 	}
+	r.endOperations(operation)
+	r.emitEvent(lib.PipelineEventCreated, pipeline, userId)
+	return
+}
+
+// newPipeline sets the fields managed by the service for a pipeline created by userId.
+func newPipeline(pipeline lib.Pipeline, id string, userId string, status string) lib.Pipeline {
+	pipeline.Id = id
+	pipeline.UserId = userId
+	pipeline.CreatedAt = time.Now()
+	pipeline.UpdatedAt = pipeline.CreatedAt
+	pipeline.Version = 1
+	pipeline.DeletedAt = nil
+	pipeline.Status = status
+	pipeline.StatusTransitions = []lib.StatusTransition{{
+		To:        status,
+		UserId:    userId,
+		Timestamp: pipeline.CreatedAt,
+	}}
+	return pipeline
+}
+
+// ownerPermissions grants the owner of pipeline full permissions, in addition to sharing if set.
+func ownerPermissions(pipeline lib.Pipeline, sharing *permV2Client.ResourcePermissions) permV2Client.ResourcePermissions {
 	permissions := permV2Client.ResourcePermissions{
 		GroupPermissions: map[string]permV2Client.PermissionsMap{},
 		UserPermissions:  map[string]permV2Client.PermissionsMap{},
 		RolePermissions:  map[string]permV2Model.PermissionsMap{},
 	}
+	if sharing != nil {
+		maps.Copy(permissions.UserPermissions, sharing.UserPermissions)
+		maps.Copy(permissions.GroupPermissions, sharing.GroupPermissions)
+		maps.Copy(permissions.RolePermissions, sharing.RolePermissions)
+	}
 	SetDefaultPermissions(pipeline, permissions)
-	_, err, _ = r.perm.SetPermission(permV2Client.InternalAdminToken, PermV2InstanceTopic, pipeline.Id, permissions)
-	return
+	return permissions
+}
+
+func (r *Registry) validateNewPipeline(pipeline lib.Pipeline, auth string) error {
+	err := ValidatePipeline(pipeline)
+	if err != nil {
+		return err
+	}
+	return r.validateGraph(pipeline, auth)
+}
+
+// UpdatePipeline replaces a pipeline and increments its version. If ifMatch is set, it has to equal the
+// stored version. Otherwise a version sent with the pipeline itself has to match the stored one.
+func (r *Registry) UpdatePipeline(pipeline lib.Pipeline, userId string, auth string, ifMatch *int) (updated lib.Pipeline, err error) {
+	return r.updatePipeline(pipeline, userId, auth, ifMatch, 0, nil)
 }
 
-func (r *Registry) UpdatePipeline(pipeline lib.Pipeline, userId string, auth string) (id string, err error) {
+// updatePipeline replaces a pipeline. The template reference of the stored pipeline is kept unless
+// template is set, which only happens when the pipeline is rendered from its template again.
+func (r *Registry) updatePipeline(pipeline lib.Pipeline, userId string, auth string, ifMatch *int, rollbackOf int, template *lib.TemplateReference) (updated lib.Pipeline, err error) {
+	v := &validator{}
+	v.required("id", pipeline.Id)
+	err = v.err()
+	if err != nil {
+		return
+	}
 	ok, err, _ := r.perm.CheckPermission(auth, PermV2InstanceTopic, pipeline.Id, permV2Client.Write)
 	if err != nil {
 		return
 	}
 	if !ok {
-		return id, lib.NewForbiddenError(errors.New(MessageMissingRights))
+		return updated, lib.NewForbiddenError(errors.New(MessageMissingRights))
+	}
+	err = ValidatePipeline(pipeline)
+	if err != nil {
+		return
+	}
+	err = r.validateGraph(pipeline, auth)
+	if err != nil {
+		return
 	}
 
 	oldPipeline, err := r.repository.FindPipeline(pipeline.Id, userId)
 	if err != nil {
-		return id, err
+		return updated, err
+	}
+	if ifMatch != nil && *ifMatch != oldPipeline.Version {
+		return updated, lib.NewPreconditionFailedError(errors.New(MessageVersionMismatch))
+	}
+	if ifMatch == nil && pipeline.Version != 0 && pipeline.Version != oldPipeline.Version {
+		return updated, lib.NewConflictError(errors.New(db.MessageVersionConflict))
+	}
+	pipeline = updatedPipeline(pipeline, oldPipeline)
+	if template != nil {
+		pipeline.Template = template
+	}
+	// the revision reserves the new version, concurrent updates of the same version conflict
+	err = r.saveRevision(pipeline, userId, rollbackOf)
+	if err != nil {
+		return updated, err
 	}
+	err = r.repository.UpdatePipeline(pipeline, userId, oldPipeline.Version)
+	if err != nil {
+		r.discardRevision(pipeline)
+		return updated, err
+	}
+	r.emitEvent(lib.PipelineEventUpdated, pipeline, userId)
+	return pipeline, nil
+}
+
+// updatedPipeline keeps the fields managed by the service from oldPipeline and increments the version.
+func updatedPipeline(pipeline lib.Pipeline, oldPipeline lib.Pipeline) lib.Pipeline {
 	pipeline.CreatedAt = oldPipeline.CreatedAt
 	pipeline.UpdatedAt = time.Now()
 	pipeline.UserId = oldPipeline.UserId
-	err = r.repository.UpdatePipeline(pipeline, userId)
-	if err != nil {
-		return id, err
-	}
-	return
+	pipeline.DeletedAt = oldPipeline.DeletedAt
+	pipeline.Status = oldPipeline.Status
+	pipeline.StatusTransitions = oldPipeline.StatusTransitions
+	pipeline.Template = oldPipeline.Template
+	pipeline.Version = oldPipeline.Version + 1
+	return pipeline
 }
 
 func (r *Registry) GetPipelines(userId string, args map[string][]string, auth string) (pipelines lib.PipelinesResponse, err error) {
@@ -192,13 +253,35 @@ func (r *Registry) GetFlowUsageById(id string) (statistics *lib.FlowUsage, err e
 	return &resp[0], nil
 }
 
+// GetTopicUsage returns the operators of readable pipelines consuming topic, or nil if there are none.
+func (r *Registry) GetTopicUsage(topic string, userId string, auth string) (usage *lib.TopicUsage, err error) {
+	ids, err, _ := r.perm.ListAccessibleResourceIds(auth, PermV2InstanceTopic, permV2Client.ListOptions{}, permV2Client.Read)
+	if err != nil {
+		return
+	}
+	return r.repository.TopicUsage(topic, userId, ids)
+}
+
+// GetSelectableUsage returns the operators of readable pipelines referencing a device or device group, or nil if there are none.
+func (r *Registry) GetSelectableUsage(id string, userId string, auth string) (usage *lib.SelectableUsage, err error) {
+	ids, err, _ := r.perm.ListAccessibleResourceIds(auth, PermV2InstanceTopic, permV2Client.ListOptions{}, permV2Client.Read)
+	if err != nil {
+		return
+	}
+	return r.repository.SelectableUsage(id, userId, ids)
+}
+
 func (r *Registry) DeletePipelineAdmin(id string, userId string) (err error) {
-	err = r.repository.DeletePipeline(id, userId, true)
+	// trashed pipelines are not found, their deletion has already been announced
+	pipeline, findErr := r.repository.FindPipeline(id, userId)
+	err = r.purgePipeline(id, userId)
 	if err != nil {
 		return
 	}
-	err, _ = r.perm.RemoveResource(permV2Client.InternalAdminToken, PermV2InstanceTopic, id)
-	return err
+	if findErr == nil {
+		r.emitEvent(lib.PipelineEventDeleted, pipeline, userId)
+	}
+	return
 }
 
 func (r *Registry) GetPipeline(id string, userId string, auth string) (pipeline lib.Pipeline, err error) {
@@ -212,7 +295,9 @@ func (r *Registry) GetPipeline(id string, userId string, auth string) (pipeline
 	return r.repository.FindPipeline(id, userId)
 }
 
-func (r *Registry) DeletePipeline(id string, userId string, auth string) (err error) {
+// DeletePipeline moves a pipeline to the trash. With checkDownstream, pipelines consumed by
+// other pipelines are not deleted.
+func (r *Registry) DeletePipeline(id string, userId string, auth string, checkDownstream bool) (err error) {
 	ok, err, _ := r.perm.CheckPermission(auth, PermV2InstanceTopic, id, permV2Client.Administrate)
 	if err != nil {
 		return
@@ -220,10 +305,31 @@ func (r *Registry) DeletePipeline(id string, userId string, auth string) (err er
 	if !ok {
 		return lib.NewForbiddenError(errors.New(MessageMissingRights))
 	}
-	err = r.repository.DeletePipeline(id, userId, false)
+	pipeline, err := r.repository.FindPipeline(id, userId)
+	if err != nil {
+		return
+	}
+	if checkDownstream {
+		err = r.checkNoDownstream(pipeline)
+		if err != nil {
+			return
+		}
+	}
+	err = r.repository.TrashPipeline(id)
 	if err != nil {
 		return
 	}
-	err, _ = r.perm.RemoveResource(permV2Client.InternalAdminToken, PermV2InstanceTopic, id)
+	r.emitEvent(lib.PipelineEventDeleted, pipeline, userId)
+	return
+}
+
+func (r *Registry) checkPermission(id string, auth string, permission permV2Client.Permission) (err error) {
+	ok, err, _ := r.perm.CheckPermission(auth, PermV2InstanceTopic, id, permission)
+	if err != nil {
+		return
+	}
+	if !ok {
+		return lib.NewForbiddenError(errors.New(MessageMissingRights))
+	}
 	return
 }
@@ -18,18 +18,22 @@ package service
 
 import (
 	"context"
+	"errors"
 	"reflect"
+	"slices"
 	"testing"
+	"time"
 
 	"github.com/SENERGY-Platform/analytics-pipeline/lib"
 	"github.com/SENERGY-Platform/analytics-pipeline/pkg/db"
+	"github.com/SENERGY-Platform/analytics-pipeline/pkg/util"
 	permV2Client "github.com/SENERGY-Platform/permissions-v2/pkg/client"
 )
 
 func TestRegistry_SavePipeline(t *testing.T) {
 	perm, err := permV2Client.NewTestClient(context.Background())
 	registry := NewRegistry(db.NewMockRepo(), perm)
-	id, err := registry.SavePipeline(lib.Pipeline{}, "1")
+	id, err := registry.SavePipeline(testPipeline(), "1", permV2Client.InternalAdminToken)
 	if err != nil {
 		t.Skip(err)
 	}
@@ -38,3 +42,125 @@ func TestRegistry_SavePipeline(t *testing.T) {
 			reflect.TypeOf(id), reflect.TypeOf(""))
 	}
 }
+
+type revisionRepo struct {
+	*db.MockRepo
+	insertErr error
+	updateErr error
+	calls     []string
+}
+
+func (r *revisionRepo) FindPipeline(id string, _ string) (pipeline lib.Pipeline, err error) {
+	return lib.Pipeline{Id: id, UserId: "1", Version: 3}, nil
+}
+
+func (r *revisionRepo) InsertRevision(revision lib.PipelineRevision) (err error) {
+	r.calls = append(r.calls, "insert")
+	return r.insertErr
+}
+
+func (r *revisionRepo) UpdatePipeline(_ lib.Pipeline, _ string, _ int) (err error) {
+	r.calls = append(r.calls, "update")
+	return r.updateErr
+}
+
+func (r *revisionRepo) DeleteRevision(_ string, revision int) (err error) {
+	r.calls = append(r.calls, "delete")
+	return
+}
+
+func TestRegistry_UpdatePipelineRevision(t *testing.T) {
+	util.InitStructLogger("error")
+	perm, err := permV2Client.NewTestClient(context.Background())
+	if err != nil {
+		t.Skip(err)
+	}
+	repo := &revisionRepo{MockRepo: db.NewMockRepo()}
+	registry := NewRegistry(repo, perm)
+	if registry == nil {
+		t.Skip("permissions-v2 topic could not be set")
+	}
+	pipeline := testPipeline()
+	pipeline.Id = "p1"
+
+	if _, err = registry.UpdatePipeline(pipeline, "1", permV2Client.InternalAdminToken, nil); err != nil {
+		t.Fatal(err)
+	}
+	if !slices.Equal(repo.calls, []string{"insert", "update"}) {
+		t.Errorf("revision not stored before update: %v", repo.calls)
+	}
+
+	repo.calls = nil
+	repo.insertErr = lib.NewConflictError(errors.New(db.MessageVersionConflict))
+	if _, err = registry.UpdatePipeline(pipeline, "1", permV2Client.InternalAdminToken, nil); err == nil {
+		t.Error("expected conflict")
+	}
+	if !slices.Equal(repo.calls, []string{"insert"}) {
+		t.Errorf("pipeline updated despite conflicting revision: %v", repo.calls)
+	}
+
+	repo.calls = nil
+	repo.insertErr = nil
+	repo.updateErr = lib.NewConflictError(errors.New(db.MessageVersionConflict))
+	if _, err = registry.UpdatePipeline(pipeline, "1", permV2Client.InternalAdminToken, nil); err == nil {
+		t.Error("expected conflict")
+	}
+	if !slices.Equal(repo.calls, []string{"insert", "update", "delete"}) {
+		t.Errorf("revision of failed update not deleted: %v", repo.calls)
+	}
+}
+
+func TestUpdatedPipeline_KeepsTemplate(t *testing.T) {
+	old := lib.Pipeline{Id: "p1", Version: 2, Template: &lib.TemplateReference{TemplateId: "t1", Version: 1}}
+	pipeline := testPipeline()
+	pipeline.Template = &lib.TemplateReference{TemplateId: "foreign", Version: 7}
+	updated := updatedPipeline(pipeline, old)
+	if updated.Template == nil || updated.Template.TemplateId != "t1" {
+		t.Errorf("client template not ignored: %v", updated.Template)
+	}
+	pipeline.Template = nil
+	old.Template = nil
+	if updated = updatedPipeline(pipeline, old); updated.Template != nil {
+		t.Errorf("template set on update: %v", updated.Template)
+	}
+}
+
+func testPipeline() lib.Pipeline {
+	return lib.Pipeline{
+		Name: "test",
+		Operators: []lib.Operator{
+			{
+				Id:          "op1",
+				OperatorId:  "adder",
+				OutputTopic: "analytics-adder",
+				InputTopics: []lib.InputTopic{
+					{
+						Name:        "device-topic",
+						FilterType:  "DeviceId",
+						FilterValue: "device1",
+						Mappings:    []lib.Mapping{{Dest: "value", Source: "value.root.value"}},
+					},
+				},
+			},
+		},
+	}
+}
+
+func TestRegistry_StartDisabled(t *testing.T) {
+	util.InitStructLogger("error")
+	perm, err := permV2Client.NewTestClient(context.Background())
+	if err != nil {
+		t.Skip(err)
+	}
+	registry := NewRegistry(db.NewMockRepo(), perm)
+	if registry == nil {
+		t.Skip("permissions-v2 topic could not be set")
+	}
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	// non-positive intervals must not panic in time.NewTicker
+	registry.StartTrashPurger(ctx, time.Hour, 0)
+	registry.StartJournal(ctx, JournalConfig{RetryInterval: -time.Second})
+	registry.StartReconciler(ctx, 0)
+	registry.StartEventPublisher(ctx, &fakeBroker{}, 0)
+}
@@ -0,0 +1,129 @@
+/*
+ * Copyright 2025 InfAI (CC SES)
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *      http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package service
+
+import (
+	"errors"
+	"strconv"
+	"time"
+
+	"github.com/SENERGY-Platform/analytics-pipeline/lib"
+	"github.com/SENERGY-Platform/analytics-pipeline/pkg/util"
+	permV2Client "github.com/SENERGY-Platform/permissions-v2/pkg/client"
+	"go.mongodb.org/mongo-driver/mongo"
+)
+
+func (r *Registry) saveRevision(pipeline lib.Pipeline, userId string, rollbackOf int) (err error) {
+	return r.repository.InsertRevision(newRevision(pipeline, userId, rollbackOf))
+}
+
+// discardRevision removes the revision of a failed update. The revision is kept if the update was
+// written nevertheless or its outcome is unknown, orphaned revisions are replaced after db.RevisionLease.
+func (r *Registry) discardRevision(pipeline lib.Pipeline) {
+	stored, err := r.repository.FindPipeline(pipeline.Id, pipeline.UserId)
+	if err != nil && !errors.Is(err, mongo.ErrNoDocuments) {
+		util.Logger.Error("could not check version of failed update", "error", err, "pipelineId", pipeline.Id)
+		return
+	}
+	if err == nil && stored.Version == pipeline.Version {
+		return
+	}
+	err = r.repository.DeleteRevision(pipeline.Id, pipeline.Version)
+	if err != nil {
+		util.Logger.Error("could not delete revision of failed update", "error", err, "pipelineId", pipeline.Id)
+	}
+}
+
+func newRevision(pipeline lib.Pipeline, userId string, rollbackOf int) lib.PipelineRevision {
+	return lib.PipelineRevision{
+		PipelineId: pipeline.Id,
+		Revision:   pipeline.Version,
+		UserId:     userId,
+		CreatedAt:  time.Now(),
+		RollbackOf: rollbackOf,
+		Pipeline:   &pipeline,
+	}
+}
+
+func (r *Registry) GetPipelineRevisions(id string, auth string) (revisions []lib.PipelineRevision, err error) {
+	err = r.checkPermission(id, auth, permV2Client.Read)
+	if err != nil {
+		return
+	}
+	return r.repository.Revisions(id)
+}
+
+func (r *Registry) GetPipelineRevision(id string, revision int, auth string) (result lib.PipelineRevision, err error) {
+	err = r.checkPermission(id, auth, permV2Client.Read)
+	if err != nil {
+		return
+	}
+	return r.repository.FindRevision(id, revision)
+}
+
+// RollbackPipeline restores the pipeline snapshot of the given revision. The rollback is
+// validated like any other update and stored as a new revision.
+func (r *Registry) RollbackPipeline(id string, revision int, userId string, auth string) (err error) {
+	err = r.checkPermission(id, auth, permV2Client.Write)
+	if err != nil {
+		return
+	}
+	pipeline, err := r.revisionSnapshot(id, revision)
+	if err != nil {
+		return
+	}
+	// the snapshot carries its own version, the rollback applies to whatever version is stored now
+	pipeline.Version = 0
+	_, err = r.updatePipeline(pipeline, userId, auth, nil, revision, nil)
+	return
+}
+
+// DiffPipeline compares the snapshots of two revisions. If to is 0, the revision is compared with
+// the current state of the pipeline.
+func (r *Registry) DiffPipeline(id string, from int, to int, userId string, auth string) (diff lib.PipelineDiff, err error) {
+	err = r.checkPermission(id, auth, permV2Client.Read)
+	if err != nil {
+		return
+	}
+	fromPipeline, err := r.revisionSnapshot(id, from)
+	if err != nil {
+		return
+	}
+	toLabel := "current"
+	var toPipeline lib.Pipeline
+	if to == 0 {
+		toPipeline, err = r.repository.FindPipeline(id, userId)
+	} else {
+		toLabel = "revision " + strconv.Itoa(to)
+		toPipeline, err = r.revisionSnapshot(id, to)
+	}
+	if err != nil {
+		return
+	}
+	return lib.DiffPipelines(fromPipeline, toPipeline, "revision "+strconv.Itoa(from), toLabel), nil
+}
+
+func (r *Registry) revisionSnapshot(id string, revision int) (pipeline lib.Pipeline, err error) {
+	rev, err := r.repository.FindRevision(id, revision)
+	if err != nil {
+		return
+	}
+	if rev.Pipeline == nil {
+		return pipeline, lib.NewInternalError(errors.New(MessageRevisionWithoutSnapshot))
+	}
+	return *rev.Pipeline, nil
+}
@@ -0,0 +1,81 @@
+/*
+ * Copyright 2025 InfAI (CC SES)
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *      http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package service
+
+import (
+	"fmt"
+	"slices"
+	"time"
+
+	"github.com/SENERGY-Platform/analytics-pipeline/lib"
+	permV2Client "github.com/SENERGY-Platform/permissions-v2/pkg/client"
+)
+
+// statusTransitions lists the allowed target states per state. Pipelines stored before the
+// introduction of states are migrated to draft, an empty status allows no transition.
+var statusTransitions = map[string][]string{
+	lib.PipelineStatusDraft:     {lib.PipelineStatusDeploying, lib.PipelineStatusDeleting},
+	lib.PipelineStatusDeploying: {lib.PipelineStatusRunning, lib.PipelineStatusFailed, lib.PipelineStatusStopped},
+	lib.PipelineStatusRunning:   {lib.PipelineStatusStopped, lib.PipelineStatusFailed, lib.PipelineStatusDeploying},
+	lib.PipelineStatusStopped:   {lib.PipelineStatusDeploying, lib.PipelineStatusDeleting},
+	lib.PipelineStatusFailed:    {lib.PipelineStatusDeploying, lib.PipelineStatusStopped, lib.PipelineStatusDeleting},
+	lib.PipelineStatusDeleting:  {lib.PipelineStatusFailed},
+}
+
+func validStatus(status string) bool {
+	_, ok := statusTransitions[status]
+	return ok
+}
+
+func statusTransitionAllowed(from string, to string) bool {
+	return slices.Contains(statusTransitions[from], to)
+}
+
+// UpdatePipelineStatus records a status transition reported by the deployer.
+func (r *Registry) UpdatePipelineStatus(id string, update lib.StatusUpdate, userId string, auth string) (pipeline lib.Pipeline, err error) {
+	if !validStatus(update.Status) {
+		v := &validator{}
+		v.add("status", fmt.Sprintf(MessageUnknownStatus, update.Status))
+		return pipeline, v.err()
+	}
+	err = r.checkPermission(id, auth, permV2Client.Execute)
+	if err != nil {
+		return
+	}
+	pipeline, err = r.repository.FindPipeline(id, userId)
+	if err != nil {
+		return
+	}
+	if !statusTransitionAllowed(pipeline.Status, update.Status) {
+		return pipeline, lib.NewConflictError(fmt.Errorf(MessageStatusTransitionNotAllowed, pipeline.Status, update.Status))
+	}
+	transition := lib.StatusTransition{
+		From:      pipeline.Status,
+		To:        update.Status,
+		Reason:    update.Reason,
+		UserId:    userId,
+		Timestamp: time.Now(),
+	}
+	err = r.repository.UpdatePipelineStatus(id, pipeline.Status, transition)
+	if err != nil {
+		return
+	}
+	pipeline.Status = update.Status
+	pipeline.StatusTransitions = append(pipeline.StatusTransitions, transition)
+	r.emitEvent(lib.PipelineEventUpdated, pipeline, userId)
+	return pipeline, nil
+}
@@ -0,0 +1,75 @@
+/*
+ * Copyright 2025 InfAI (CC SES)
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *      http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package service
+
+import (
+	"testing"
+
+	"github.com/SENERGY-Platform/analytics-pipeline/lib"
+)
+
+func TestStatusTransitionAllowed(t *testing.T) {
+	for _, test := range []struct {
+		from    string
+		to      string
+		allowed bool
+	}{
+		{lib.PipelineStatusDraft, lib.PipelineStatusDeploying, true},
+		{lib.PipelineStatusDraft, lib.PipelineStatusDeleting, true},
+		{lib.PipelineStatusDraft, lib.PipelineStatusRunning, false},
+		{lib.PipelineStatusDeploying, lib.PipelineStatusRunning, true},
+		{lib.PipelineStatusDeploying, lib.PipelineStatusFailed, true},
+		{lib.PipelineStatusDeploying, lib.PipelineStatusStopped, true},
+		{lib.PipelineStatusDeploying, lib.PipelineStatusDraft, false},
+		{lib.PipelineStatusRunning, lib.PipelineStatusStopped, true},
+		{lib.PipelineStatusRunning, lib.PipelineStatusDeploying, true},
+		{lib.PipelineStatusRunning, lib.PipelineStatusDeleting, false},
+		{lib.PipelineStatusStopped, lib.PipelineStatusDeploying, true},
+		{lib.PipelineStatusStopped, lib.PipelineStatusRunning, false},
+		{lib.PipelineStatusFailed, lib.PipelineStatusDeploying, true},
+		{lib.PipelineStatusFailed, lib.PipelineStatusDeleting, true},
+		{lib.PipelineStatusDeleting, lib.PipelineStatusFailed, true},
+		{lib.PipelineStatusDeleting, lib.PipelineStatusDraft, false},
+		{lib.PipelineStatusRunning, lib.PipelineStatusRunning, false},
+		{"", lib.PipelineStatusDraft, false},
+		{"", lib.PipelineStatusRunning, false},
+		{lib.PipelineStatusDraft, "unknown", false},
+	} {
+		if allowed := statusTransitionAllowed(test.from, test.to); allowed != test.allowed {
+			t.Errorf("%q -> %q: expected allowed=%v", test.from, test.to, test.allowed)
+		}
+	}
+}
+
+func TestStatusTransitions(t *testing.T) {
+	for from, targets := range statusTransitions {
+		if len(targets) == 0 {
+			t.Errorf("%s is a dead end", from)
+		}
+		for _, to := range targets {
+			if !validStatus(to) {
+				t.Errorf("%s leads to unknown status %s", from, to)
+			}
+			if to == from {
+				t.Errorf("%s leads to itself", from)
+			}
+		}
+	}
+	if validStatus("") {
+		t.Error("empty status must not be valid")
+	}
+}
@@ -0,0 +1,221 @@
+/*
+ * Copyright 2025 InfAI (CC SES)
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *      http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package service
+
+import (
+	"context"
+	"errors"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/SENERGY-Platform/analytics-pipeline/lib"
+	"github.com/SENERGY-Platform/analytics-pipeline/pkg/util"
+	permV2Client "github.com/SENERGY-Platform/permissions-v2/pkg/client"
+	"github.com/google/uuid"
+)
+
+const (
+	// eventHistorySize is the number of events kept for resuming streams via Last-Event-ID.
+	eventHistorySize = 1000
+	// subscriberBufferSize is the number of events buffered per stream. Streams that fall
+	// further behind are closed and have to resume.
+	subscriberBufferSize = 64
+	// readableMaxAge is the time after which a stream lists the readable pipelines again,
+	// so that revoked permissions take effect.
+	readableMaxAge = time.Minute
+	// readableMinInterval limits how often a stream lists the readable pipelines because of
+	// events of unknown pipelines.
+	readableMinInterval = time.Second
+)
+
+// StreamEvent is a pipeline event numbered in the order it was emitted by this instance.
+// Epoch identifies the instance and its start, sequence numbers are only comparable within it.
+type StreamEvent struct {
+	Epoch    string
+	Sequence uint64
+	Event    lib.PipelineEvent
+}
+
+// Id returns the SSE event id, which is sent back as Last-Event-ID to resume a stream.
+func (e StreamEvent) Id() string {
+	return e.Epoch + "-" + strconv.FormatUint(e.Sequence, 10)
+}
+
+// eventHub fans out events to all open streams and keeps a history for resuming.
+type eventHub struct {
+	mu          sync.Mutex
+	epoch       string
+	sequence    uint64
+	history     []StreamEvent
+	subscribers map[chan StreamEvent]struct{}
+}
+
+func newEventHub() *eventHub {
+	return &eventHub{epoch: strings.ReplaceAll(uuid.NewString(), "-", "")[:12], subscribers: map[chan StreamEvent]struct{}{}}
+}
+
+func (h *eventHub) publish(event lib.PipelineEvent) {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	h.sequence++
+	streamEvent := StreamEvent{Epoch: h.epoch, Sequence: h.sequence, Event: event}
+	h.history = append(h.history, streamEvent)
+	if len(h.history) > eventHistorySize {
+		h.history = h.history[len(h.history)-eventHistorySize:]
+	}
+	for ch := range h.subscribers {
+		select {
+		case ch <- streamEvent:
+		default:
+			delete(h.subscribers, ch)
+			close(ch)
+		}
+	}
+}
+
+// subscribe returns the events emitted after the event with lastEventId and a channel receiving
+// all following events. An empty lastEventId starts with the following events. Ids of other
+// instances, of before a restart or of events no longer in the history are rejected with a
+// ConflictError, since events in between would be missed.
+func (h *eventHub) subscribe(lastEventId string) (backlog []StreamEvent, ch chan StreamEvent, err error) {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	if lastEventId != "" {
+		epoch, sequence, _ := strings.Cut(lastEventId, "-")
+		last, parseErr := strconv.ParseUint(sequence, 10, 64)
+		oldest := h.sequence + 1
+		if len(h.history) > 0 {
+			oldest = h.history[0].Sequence
+		}
+		if epoch != h.epoch || parseErr != nil || last > h.sequence || last+1 < oldest {
+			return nil, nil, lib.NewConflictError(errors.New(MessageUnknownEventId))
+		}
+		for _, event := range h.history {
+			if event.Sequence > last {
+				backlog = append(backlog, event)
+			}
+		}
+	}
+	ch = make(chan StreamEvent, subscriberBufferSize)
+	h.subscribers[ch] = struct{}{}
+	return
+}
+
+func (h *eventHub) unsubscribe(ch chan StreamEvent) {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	if _, ok := h.subscribers[ch]; ok {
+		delete(h.subscribers, ch)
+		close(ch)
+	}
+}
+
+// readableSet caches the pipelines readable by a stream. Unknown pipelines are cached as not
+// readable until the next refresh.
+type readableSet struct {
+	list      func() ([]string, error)
+	readable  map[string]bool
+	refreshed time.Time
+	attempted time.Time
+}
+
+func (s *readableSet) refresh() error {
+	s.attempted = time.Now()
+	ids, err := s.list()
+	if err != nil {
+		return err
+	}
+	s.readable = make(map[string]bool, len(ids))
+	for _, id := range ids {
+		s.readable[id] = true
+	}
+	s.refreshed = s.attempted
+	return nil
+}
+
+// allowed reports whether the pipeline with id is readable. Unknown pipelines cause a refresh,
+// except for deletions. Without a refresh within readableMaxAge, nothing is readable.
+func (s *readableSet) allowed(id string, deleted bool) bool {
+	stale := time.Since(s.refreshed) > readableMaxAge
+	readable, known := s.readable[id]
+	if (stale || (!known && !deleted)) && time.Since(s.attempted) > readableMinInterval {
+		if err := s.refresh(); err != nil {
+			util.Logger.Error("could not list readable pipelines", "error", err)
+		}
+		readable, known = s.readable[id]
+	}
+	if time.Since(s.refreshed) > readableMaxAge {
+		return false
+	}
+	if !known {
+		s.readable[id] = false
+	}
+	return readable
+}
+
+// SubscribePipelineEvents streams the events of all pipelines the caller may read, starting after
+// lastEventId. The returned channel is closed when ctx is done or the stream fell behind.
+// Event payloads do not contain the pipeline document.
+func (r *Registry) SubscribePipelineEvents(ctx context.Context, auth string, lastEventId string) (events <-chan StreamEvent, err error) {
+	readable := &readableSet{list: func() ([]string, error) {
+		ids, err, _ := r.perm.ListAccessibleResourceIds(auth, PermV2InstanceTopic, permV2Client.ListOptions{}, permV2Client.Read)
+		return ids, err
+	}}
+	err = readable.refresh()
+	if err != nil {
+		return
+	}
+	backlog, ch, err := r.hub.subscribe(lastEventId)
+	if err != nil {
+		return
+	}
+	out := make(chan StreamEvent)
+	go func() {
+		defer close(out)
+		defer r.hub.unsubscribe(ch)
+		send := func(event StreamEvent) bool {
+			if !readable.allowed(event.Event.PipelineId, event.Event.Type == lib.PipelineEventDeleted) {
+				return true
+			}
+			event.Event.Pipeline = nil
+			select {
+			case out <- event:
+				return true
+			case <-ctx.Done():
+				return false
+			}
+		}
+		for _, event := range backlog {
+			if !send(event) {
+				return
+			}
+		}
+		for {
+			select {
+			case <-ctx.Done():
+				return
+			case event, ok := <-ch:
+				if !ok || !send(event) {
+					return
+				}
+			}
+		}
+	}()
+	return out, nil
+}
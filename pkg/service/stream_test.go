@@ -0,0 +1,107 @@
+/*
+ * Copyright 2025 InfAI (CC SES)
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *      http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package service
+
+import (
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/SENERGY-Platform/analytics-pipeline/lib"
+)
+
+func TestEventHub_Resume(t *testing.T) {
+	hub := newEventHub()
+	for _, id := range []string{"p1", "p2", "p3"} {
+		hub.publish(lib.PipelineEvent{PipelineId: id, Type: lib.PipelineEventUpdated})
+	}
+	backlog, ch, err := hub.subscribe(hub.epoch + "-1")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer hub.unsubscribe(ch)
+	if len(backlog) != 2 || backlog[0].Sequence != 2 || backlog[1].Event.PipelineId != "p3" {
+		t.Fatalf("unexpected backlog: %+v", backlog)
+	}
+	hub.publish(lib.PipelineEvent{PipelineId: "p4"})
+	if event := <-ch; event.Sequence != 4 || event.Event.PipelineId != "p4" {
+		t.Errorf("unexpected event: %+v", event)
+	}
+
+	// ids of other instances, of before a restart or of events no longer in the history are rejected
+	var conflict *lib.ConflictError
+	for _, id := range []string{"other-1", hub.epoch + "-100", "1", hub.epoch + "-x"} {
+		if _, _, err = hub.subscribe(id); !errors.As(err, &conflict) {
+			t.Errorf("%s: expected conflict, got %v", id, err)
+		}
+	}
+	for i := 0; i < eventHistorySize; i++ {
+		hub.publish(lib.PipelineEvent{PipelineId: "p5"})
+	}
+	if _, _, err = hub.subscribe(hub.epoch + "-1"); !errors.As(err, &conflict) {
+		t.Errorf("expected conflict for an event no longer in the history, got %v", err)
+	}
+	if newEventHub().epoch == hub.epoch {
+		t.Error("epoch reused")
+	}
+}
+
+func TestEventHub_SlowSubscriberIsClosed(t *testing.T) {
+	hub := newEventHub()
+	_, ch, _ := hub.subscribe("")
+	for i := 0; i <= subscriberBufferSize; i++ {
+		hub.publish(lib.PipelineEvent{PipelineId: "p1"})
+	}
+	count := 0
+	for range ch {
+		count++
+	}
+	if count != subscriberBufferSize {
+		t.Errorf("expected %d buffered events before close, got %d", subscriberBufferSize, count)
+	}
+	hub.unsubscribe(ch)
+}
+
+func TestReadableSet(t *testing.T) {
+	ids := []string{"p1"}
+	calls := 0
+	set := &readableSet{list: func() ([]string, error) {
+		calls++
+		return ids, nil
+	}}
+	if err := set.refresh(); err != nil {
+		t.Fatal(err)
+	}
+	if !set.allowed("p1", false) || calls != 1 {
+		t.Errorf("readable pipeline not allowed without listing: %d calls", calls)
+	}
+	set.attempted = time.Now().Add(-2 * readableMinInterval)
+	if set.allowed("p2", false) || set.allowed("p2", false) || calls != 2 {
+		t.Errorf("unreadable pipeline not cached: %d calls", calls)
+	}
+	if set.allowed("p3", false) || calls != 2 {
+		t.Errorf("listing not limited: %d calls", calls)
+	}
+
+	// revoked permissions take effect after readableMaxAge
+	ids = []string{"p2"}
+	set.refreshed = time.Now().Add(-2 * readableMaxAge)
+	set.attempted = set.refreshed
+	if set.allowed("p1", false) || !set.allowed("p2", false) || calls != 3 {
+		t.Errorf("stale set not rebuilt: %d calls", calls)
+	}
+}
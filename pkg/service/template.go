@@ -0,0 +1,177 @@
+/*
+ * Copyright 2025 InfAI (CC SES)
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *      http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package service
+
+import (
+	"errors"
+	"fmt"
+	"regexp"
+	"slices"
+	"time"
+
+	"github.com/SENERGY-Platform/analytics-pipeline/lib"
+	"github.com/SENERGY-Platform/analytics-pipeline/pkg/db"
+	"github.com/google/uuid"
+)
+
+var templateParamName = regexp.MustCompile(`^[A-Za-z_][A-Za-z0-9_]*$`)
+
+func validateTemplate(template lib.PipelineTemplate) error {
+	v := &validator{}
+	v.required("name", template.Name)
+	declared := map[string]int{}
+	for i, param := range template.Params {
+		path := fmt.Sprintf("params[%d]", i)
+		if !templateParamName.MatchString(param.Name) {
+			v.add(path+".name", MessageInvalidParamName)
+		} else if j, ok := declared[param.Name]; ok {
+			v.add(path+".name", fmt.Sprintf(MessageDuplicateParamName, j))
+		} else {
+			declared[param.Name] = i
+		}
+		if !slices.Contains(lib.TemplateParamTypes, param.Type) {
+			v.add(path+".type", fmt.Sprintf(MessageUnknownParamType, param.Type))
+			continue
+		}
+		if param.Default != nil {
+			if _, err := lib.TemplateValue(param.Type, *param.Default); err != nil {
+				v.add(path+".default", err.Error())
+			}
+		}
+	}
+	if len(template.Pipeline.Operators) == 0 {
+		v.add("pipeline.operators", MessageNoOperators)
+	}
+	for _, name := range lib.TemplatePlaceholders(template.Pipeline) {
+		if _, ok := declared[name]; !ok {
+			v.add("pipeline.operators", fmt.Sprintf(MessageUndeclaredParam, name))
+		}
+	}
+	return v.err()
+}
+
+func (r *Registry) CreateTemplate(template lib.PipelineTemplate, userId string) (created lib.PipelineTemplate, err error) {
+	err = validateTemplate(template)
+	if err != nil {
+		return
+	}
+	template.Id = uuid.NewString()
+	template.UserId = userId
+	template.Version = 1
+	template.CreatedAt = time.Now()
+	template.UpdatedAt = template.CreatedAt
+	err = r.repository.InsertTemplate(template)
+	if err != nil {
+		return
+	}
+	return template, nil
+}
+
+func (r *Registry) GetTemplates(userId string) (templates []lib.PipelineTemplate, err error) {
+	return r.repository.Templates(userId)
+}
+
+func (r *Registry) GetTemplate(id string, userId string) (template lib.PipelineTemplate, err error) {
+	return r.repository.FindTemplate(id, userId)
+}
+
+// UpdateTemplate replaces a template and increments its version. A version sent with the
+// template has to match the stored one. Pipelines created from the template are not changed
+// until they are rendered again.
+func (r *Registry) UpdateTemplate(template lib.PipelineTemplate, userId string) (updated lib.PipelineTemplate, err error) {
+	err = validateTemplate(template)
+	if err != nil {
+		return
+	}
+	old, err := r.repository.FindTemplate(template.Id, userId)
+	if err != nil {
+		return
+	}
+	if template.Version != 0 && template.Version != old.Version {
+		return updated, lib.NewConflictError(errors.New(db.MessageTemplateConflict))
+	}
+	template.UserId = old.UserId
+	template.CreatedAt = old.CreatedAt
+	template.UpdatedAt = time.Now()
+	template.Version = old.Version + 1
+	err = r.repository.UpdateTemplate(template, old.Version)
+	if err != nil {
+		return
+	}
+	return template, nil
+}
+
+func (r *Registry) DeleteTemplate(id string, userId string) (err error) {
+	return r.repository.DeleteTemplate(id, userId)
+}
+
+// InstantiateTemplate creates a pipeline from a template of the user. The pipeline references
+// the template version and the rendered values.
+func (r *Registry) InstantiateTemplate(id string, request lib.TemplateInstantiation, userId string, auth string) (pipelineId string, err error) {
+	template, err := r.repository.FindTemplate(id, userId)
+	if err != nil {
+		return
+	}
+	pipelineId = uuid.NewString()
+	pipeline, values, err := lib.RenderTemplate(template, pipelineId, request.Values)
+	if err != nil {
+		return "", err
+	}
+	if request.Name != "" {
+		pipeline.Name = request.Name
+	}
+	pipeline.Template = &lib.TemplateReference{TemplateId: template.Id, Version: template.Version, Values: values}
+	err = r.savePipeline(pipeline, pipelineId, userId, auth, lib.PipelineStatusDraft, nil)
+	if err != nil {
+		return "", err
+	}
+	return
+}
+
+// RenderPipeline renders a pipeline again from the current version of its template. The values
+// stored with the pipeline are used unless overridden by values, values of parameters that were
+// removed from the template are dropped. The name of the pipeline is kept.
+func (r *Registry) RenderPipeline(id string, values map[string]any, userId string, auth string) (updated lib.Pipeline, err error) {
+	pipeline, err := r.GetPipeline(id, userId, auth)
+	if err != nil {
+		return
+	}
+	if pipeline.Template == nil {
+		return updated, lib.NewInputError(errors.New(MessageNotFromTemplate))
+	}
+	// templates are private, only the owner of the pipeline may have instantiated it
+	template, err := r.repository.FindTemplate(pipeline.Template.TemplateId, pipeline.UserId)
+	if err != nil {
+		return
+	}
+	merged := map[string]any{}
+	for _, param := range template.Params {
+		if value, ok := pipeline.Template.Values[param.Name]; ok {
+			merged[param.Name] = value
+		}
+	}
+	for name, value := range values {
+		merged[name] = value
+	}
+	rendered, renderedValues, err := lib.RenderTemplate(template, pipeline.Id, merged)
+	if err != nil {
+		return
+	}
+	rendered.Name = pipeline.Name
+	reference := &lib.TemplateReference{TemplateId: template.Id, Version: template.Version, Values: renderedValues}
+	return r.updatePipeline(rendered, userId, auth, nil, 0, reference)
+}
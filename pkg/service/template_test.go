@@ -0,0 +1,59 @@
+/*
+ * Copyright 2025 InfAI (CC SES)
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *      http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package service
+
+import (
+	"errors"
+	"slices"
+	"testing"
+
+	"github.com/SENERGY-Platform/analytics-pipeline/lib"
+)
+
+func TestValidateTemplate(t *testing.T) {
+	pipeline := testPipeline()
+	pipeline.Operators[0].Config = map[string]string{"limit": "${limit}", "unit": "${unit}"}
+	invalid := "many"
+	template := lib.PipelineTemplate{
+		Name: "template",
+		Params: []lib.TemplateParam{
+			{Name: "limit", Type: lib.TemplateParamInteger, Default: &invalid},
+			{Name: "limit", Type: lib.TemplateParamString},
+			{Name: "1x", Type: "date"},
+		},
+		Pipeline: pipeline,
+	}
+
+	err := validateTemplate(template)
+	var ve *lib.ValidationError
+	if !errors.As(err, &ve) {
+		t.Fatalf("expected validation error, got %v", err)
+	}
+	var paths []string
+	for _, v := range ve.Violations {
+		paths = append(paths, v.Path)
+	}
+	expected := []string{"params[0].default", "params[1].name", "params[2].name", "params[2].type", "pipeline.operators"}
+	if !slices.Equal(paths, expected) {
+		t.Errorf("unexpected violations: %v", ve.Violations)
+	}
+
+	template.Params = []lib.TemplateParam{{Name: "limit", Type: lib.TemplateParamInteger}, {Name: "unit", Type: lib.TemplateParamString}}
+	if err = validateTemplate(template); err != nil {
+		t.Errorf("unexpected error: %v", err)
+	}
+}
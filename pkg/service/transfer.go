@@ -0,0 +1,178 @@
+/*
+ * Copyright 2025 InfAI (CC SES)
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *      http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package service
+
+import (
+	"errors"
+	"maps"
+	"net/http"
+
+	"github.com/SENERGY-Platform/analytics-pipeline/lib"
+	"github.com/SENERGY-Platform/analytics-pipeline/pkg/db"
+	"github.com/SENERGY-Platform/analytics-pipeline/pkg/util"
+	permV2Client "github.com/SENERGY-Platform/permissions-v2/pkg/client"
+	"go.mongodb.org/mongo-driver/mongo"
+)
+
+// TransferPipeline hands a pipeline over to another user. Only the owner or an admin may transfer it.
+// Like transfers of all pipelines of a user, trashed pipelines are included.
+func (r *Registry) TransferPipeline(id string, transfer lib.PipelineTransfer, userId string, admin bool) (err error) {
+	v := &validator{}
+	v.required("userId", transfer.UserId)
+	err = v.err()
+	if err != nil {
+		return
+	}
+	owner, err := r.repository.PipelineOwner(id)
+	if err != nil {
+		return
+	}
+	if !admin && owner != userId {
+		return lib.NewForbiddenError(errors.New(MessageNotOwner))
+	}
+	return r.transferPipeline(id, owner, transfer.UserId, transfer.KeepPermissions, userId)
+}
+
+// TransferPipelinesAdmin hands all pipelines of a user over to another user. Every pipeline is
+// transferred on its own, failures are reported per pipeline.
+func (r *Registry) TransferPipelinesAdmin(transfer lib.PipelinesTransfer, userId string) (result lib.PipelinesTransferResult, err error) {
+	v := &validator{}
+	v.required("fromUserId", transfer.FromUserId)
+	v.required("toUserId", transfer.ToUserId)
+	err = v.err()
+	if err != nil {
+		return
+	}
+	ids, err := r.repository.OwnedPipelineIds(transfer.FromUserId)
+	if err != nil {
+		return
+	}
+	result.Transferred = []string{}
+	for _, id := range ids {
+		err = r.transferPipeline(id, transfer.FromUserId, transfer.ToUserId, transfer.KeepPermissions, userId)
+		if err != nil {
+			util.Logger.Error("could not transfer pipeline", "error", err, "id", id)
+			if result.Failed == nil {
+				result.Failed = map[string]string{}
+			}
+			result.Failed[id] = err.Error()
+			continue
+		}
+		result.Transferred = append(result.Transferred, id)
+	}
+	return result, nil
+}
+
+// transferPipeline changes the owner in the database and the permissions in permissions-v2 as a
+// journaled operation. If either fails, the previous owner and the previous grants of both users
+// are restored. Permissions of other users, groups and roles are left as they are.
+func (r *Registry) transferPipeline(id string, from string, to string, keepPermissions bool, userId string) (err error) {
+	if from == to {
+		return nil
+	}
+	previous, err := r.currentPermissions(id)
+	if err != nil {
+		return
+	}
+	operation := newOperation(db.OperationTransfer, id)
+	operation.FromUserId = from
+	operation.ToUserId = to
+	operation.KeepPermissions = keepPermissions
+	operation.FromPermissions = userGrant(previous, from)
+	operation.ToPermissions = userGrant(previous, to)
+	err = r.repository.InsertOperations([]db.PipelineOperation{operation})
+	if err != nil {
+		return
+	}
+	pipeline, err := r.repository.ChangePipelineOwner(id, from, to)
+	if errors.Is(err, mongo.ErrNoDocuments) {
+		// transferred or purged concurrently
+		r.endOperations(operation)
+		return
+	}
+	if err == nil {
+		err = r.updatePermissions(id, func(current permV2Client.ResourcePermissions) permV2Client.ResourcePermissions {
+			return transferredPermissions(current, from, to, keepPermissions)
+		})
+	}
+	if err != nil {
+		r.compensateOperation(operation)
+		return
+	}
+	r.endOperations(operation)
+	if pipeline.DeletedAt == nil {
+		r.emitEvent(lib.PipelineEventUpdated, pipeline, userId)
+	}
+	return
+}
+
+// transferredPermissions grants the new owner all permissions and removes those of the previous
+// owner unless keepPermissions is set. Group and role permissions are kept.
+func transferredPermissions(current permV2Client.ResourcePermissions, from string, to string, keepPermissions bool) permV2Client.ResourcePermissions {
+	sharing := current
+	sharing.UserPermissions = maps.Clone(current.UserPermissions)
+	if !keepPermissions {
+		delete(sharing.UserPermissions, from)
+	}
+	return ownerPermissions(lib.Pipeline{UserId: to}, &sharing)
+}
+
+// restoredPermissions undoes a transfer by resetting the grants of both users of the operation to
+// the ones they had before. The previous owner gets all permissions if they had no grant.
+func restoredPermissions(current permV2Client.ResourcePermissions, operation db.PipelineOperation) permV2Client.ResourcePermissions {
+	permissions := ownerPermissions(lib.Pipeline{UserId: operation.FromUserId}, &current)
+	if operation.FromPermissions != nil {
+		permissions.UserPermissions[operation.FromUserId] = *operation.FromPermissions
+	}
+	delete(permissions.UserPermissions, operation.ToUserId)
+	if operation.ToPermissions != nil {
+		permissions.UserPermissions[operation.ToUserId] = *operation.ToPermissions
+	}
+	return permissions
+}
+
+func userGrant(permissions permV2Client.ResourcePermissions, userId string) *permV2Client.PermissionsMap {
+	grant, ok := permissions.UserPermissions[userId]
+	if !ok {
+		return nil
+	}
+	return &grant
+}
+
+// currentPermissions returns the permissions of a pipeline, empty ones if it has no permissions-v2 resource.
+func (r *Registry) currentPermissions(id string) (permissions permV2Client.ResourcePermissions, err error) {
+	resource, err, code := r.perm.GetResource(permV2Client.InternalAdminToken, PermV2InstanceTopic, id)
+	if code == http.StatusNotFound {
+		return permV2Client.ResourcePermissions{
+			UserPermissions:  map[string]permV2Client.PermissionsMap{},
+			GroupPermissions: map[string]permV2Client.PermissionsMap{},
+			RolePermissions:  map[string]permV2Client.PermissionsMap{},
+		}, nil
+	}
+	return resource.ResourcePermissions, err
+}
+
+// updatePermissions reads the permissions of a pipeline right before setting the ones computed by
+// update, so that concurrent changes of other grants are kept.
+func (r *Registry) updatePermissions(id string, update func(current permV2Client.ResourcePermissions) permV2Client.ResourcePermissions) (err error) {
+	current, err := r.currentPermissions(id)
+	if err != nil {
+		return
+	}
+	_, err, _ = r.perm.SetPermission(permV2Client.InternalAdminToken, PermV2InstanceTopic, id, update(current))
+	return
+}
@@ -0,0 +1,132 @@
+/*
+ * Copyright 2025 InfAI (CC SES)
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *      http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package service
+
+import (
+	"context"
+	"errors"
+	"slices"
+	"testing"
+
+	"github.com/SENERGY-Platform/analytics-pipeline/lib"
+	"github.com/SENERGY-Platform/analytics-pipeline/pkg/db"
+	"github.com/SENERGY-Platform/analytics-pipeline/pkg/util"
+	permV2Client "github.com/SENERGY-Platform/permissions-v2/pkg/client"
+)
+
+type transferRepo struct {
+	*db.MockRepo
+	owner   string
+	fail    bool
+	changes []string
+}
+
+func (r *transferRepo) PipelineOwner(_ string) (userId string, err error) {
+	return r.owner, nil
+}
+
+func (r *transferRepo) ChangePipelineOwner(id string, from string, to string) (pipeline lib.Pipeline, err error) {
+	r.changes = append(r.changes, from+">"+to)
+	if r.fail {
+		r.fail = false
+		return pipeline, errors.New("connection lost")
+	}
+	r.owner = to
+	return lib.Pipeline{Id: id, UserId: to}, nil
+}
+
+func TestTransferredPermissions(t *testing.T) {
+	all := permV2Client.PermissionsMap{Read: true, Write: true, Execute: true, Administrate: true}
+	previous := permV2Client.ResourcePermissions{
+		UserPermissions:  map[string]permV2Client.PermissionsMap{"a": all, "other": {Read: true}},
+		GroupPermissions: map[string]permV2Client.PermissionsMap{"g": {Read: true}},
+	}
+	permissions := transferredPermissions(previous, "a", "b", false)
+	if _, ok := permissions.UserPermissions["a"]; ok || !fullPermissions(permissions.UserPermissions["b"]) ||
+		!permissions.UserPermissions["other"].Read || !permissions.GroupPermissions["g"].Read {
+		t.Errorf("unexpected permissions: %+v", permissions)
+	}
+	if _, ok := previous.UserPermissions["a"]; !ok {
+		t.Error("previous permissions modified")
+	}
+	permissions = transferredPermissions(previous, "a", "b", true)
+	if !fullPermissions(permissions.UserPermissions["a"]) {
+		t.Errorf("permissions of previous owner not kept: %+v", permissions)
+	}
+}
+
+func TestRestoredPermissions(t *testing.T) {
+	all := permV2Client.PermissionsMap{Read: true, Write: true, Execute: true, Administrate: true}
+	read := permV2Client.PermissionsMap{Read: true}
+	operation := db.PipelineOperation{FromUserId: "a", ToUserId: "b", ToPermissions: &read}
+	// shared with other after the transfer
+	current := permV2Client.ResourcePermissions{
+		UserPermissions: map[string]permV2Client.PermissionsMap{"b": all, "other": read},
+	}
+	permissions := restoredPermissions(current, operation)
+	if !fullPermissions(permissions.UserPermissions["a"]) || permissions.UserPermissions["b"] != read ||
+		permissions.UserPermissions["other"] != read {
+		t.Errorf("unexpected permissions: %+v", permissions)
+	}
+	operation.ToPermissions = nil
+	if _, ok := restoredPermissions(current, operation).UserPermissions["b"]; ok {
+		t.Error("grant of new owner not removed")
+	}
+}
+
+func TestTransferPipeline(t *testing.T) {
+	util.InitStructLogger("error")
+	perm, err := permV2Client.NewTestClient(context.Background())
+	if err != nil {
+		t.Skip(err)
+	}
+	repo := &transferRepo{MockRepo: db.NewMockRepo(), owner: "a"}
+	registry := NewRegistry(repo, perm)
+	if registry == nil {
+		t.Skip("permissions-v2 topic could not be set")
+	}
+	if _, err, _ = perm.SetPermission(permV2Client.InternalAdminToken, PermV2InstanceTopic, "p1", ownerPermissions(lib.Pipeline{UserId: "a"}, nil)); err != nil {
+		t.Fatal(err)
+	}
+
+	err = registry.TransferPipeline("p1", lib.PipelineTransfer{UserId: "b"}, "c", false)
+	var forbidden *lib.ForbiddenError
+	if !errors.As(err, &forbidden) {
+		t.Fatalf("expected forbidden error, got %v", err)
+	}
+
+	// a failed transfer hands the pipeline back
+	repo.fail = true
+	if err = registry.TransferPipeline("p1", lib.PipelineTransfer{UserId: "b"}, "a", false); err == nil {
+		t.Fatal("expected error")
+	}
+	if !slices.Equal(repo.changes, []string{"a>b", "b>a"}) {
+		t.Errorf("unexpected owner changes: %v", repo.changes)
+	}
+	resource, _, _ := perm.GetResource(permV2Client.InternalAdminToken, PermV2InstanceTopic, "p1")
+	if !fullPermissions(resource.UserPermissions["a"]) || resource.UserPermissions["b"].Read {
+		t.Errorf("permissions not restored: %+v", resource.ResourcePermissions)
+	}
+
+	if err = registry.TransferPipeline("p1", lib.PipelineTransfer{UserId: "b"}, "admin", true); err != nil {
+		t.Fatal(err)
+	}
+	resource, _, _ = perm.GetResource(permV2Client.InternalAdminToken, PermV2InstanceTopic, "p1")
+	if repo.owner != "b" || !fullPermissions(resource.UserPermissions["b"]) || resource.UserPermissions["a"].Read {
+		t.Errorf("unexpected transfer result, owner %s: %+v", repo.owner, resource.ResourcePermissions)
+	}
+}
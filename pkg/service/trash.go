@@ -0,0 +1,115 @@
+/*
+ * Copyright 2025 InfAI (CC SES)
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *      http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package service
+
+import (
+	"context"
+	"errors"
+	"time"
+
+	"github.com/SENERGY-Platform/analytics-pipeline/lib"
+	"github.com/SENERGY-Platform/analytics-pipeline/pkg/db"
+	"github.com/SENERGY-Platform/analytics-pipeline/pkg/util"
+	permV2Client "github.com/SENERGY-Platform/permissions-v2/pkg/client"
+	"go.mongodb.org/mongo-driver/mongo"
+)
+
+func (r *Registry) GetTrashedPipelines(userId string, args map[string][]string, auth string) (pipelines lib.PipelinesResponse, err error) {
+	ids, err, _ := r.perm.ListAccessibleResourceIds(auth, PermV2InstanceTopic, permV2Client.ListOptions{}, permV2Client.Administrate)
+	if err != nil {
+		return
+	}
+	return r.repository.AllTrashed(userId, false, args, ids)
+}
+
+func (r *Registry) RestorePipeline(id string, userId string, auth string) (err error) {
+	err = r.checkPermission(id, auth, permV2Client.Administrate)
+	if err != nil {
+		return
+	}
+	err = r.repository.RestorePipeline(id)
+	if err != nil {
+		return
+	}
+	pipeline, err := r.repository.FindPipeline(id, userId)
+	if err != nil {
+		return
+	}
+	r.emitEvent(lib.PipelineEventCreated, pipeline, userId)
+	return
+}
+
+// purgePipeline removes a pipeline, its revisions and its permissions-v2 resource for good. Steps
+// that fail are retried by the journal.
+func (r *Registry) purgePipeline(id string, userId string) (err error) {
+	operation, err := r.beginOperation(db.OperationPurge, id, nil)
+	if err != nil {
+		return
+	}
+	err = r.repository.DeletePipeline(id, userId, true)
+	if err != nil && !errors.Is(err, mongo.ErrNoDocuments) {
+		// the pipeline might be deleted nevertheless, the journal completes the purge
+		r.retryOperation(operation, err)
+		return
+	}
+	missing := err
+	err = r.settleOperation(operation)
+	if err != nil {
+		return
+	}
+	// a permissions-v2 resource left behind has been removed anyway
+	return missing
+}
+
+// PurgeTrash removes all pipelines that have been in the trash for longer than retention.
+func (r *Registry) PurgeTrash(retention time.Duration) (err error) {
+	ids, err := r.repository.TrashedBefore(time.Now().Add(-retention))
+	if err != nil {
+		return
+	}
+	for _, id := range ids {
+		err = r.purgePipeline(id, "")
+		if err != nil {
+			return
+		}
+		util.Logger.Debug("purged trashed pipeline", "id", id)
+	}
+	return
+}
+
+// StartTrashPurger purges the trash right away and then every interval until ctx is done.
+// A non-positive interval disables purging.
+func (r *Registry) StartTrashPurger(ctx context.Context, retention time.Duration, interval time.Duration) {
+	if interval <= 0 {
+		util.Logger.Warn("trash purger disabled", "interval", interval)
+		return
+	}
+	go func() {
+		ticker := time.NewTicker(interval)
+		defer ticker.Stop()
+		for {
+			if err := r.PurgeTrash(retention); err != nil {
+				util.Logger.Error("could not purge trash", "error", err)
+			}
+			select {
+			case <-ctx.Done():
+				return
+			case <-ticker.C:
+			}
+		}
+	}()
+}
@@ -0,0 +1,94 @@
+/*
+ * Copyright 2025 InfAI (CC SES)
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *      http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package service
+
+import (
+	"fmt"
+
+	"github.com/SENERGY-Platform/analytics-pipeline/lib"
+)
+
+type validator struct {
+	violations []lib.Violation
+}
+
+func (v *validator) add(path string, message string) {
+	v.violations = append(v.violations, lib.Violation{Path: path, Message: message})
+}
+
+func (v *validator) required(path string, value string) {
+	if value == "" {
+		v.add(path, MessageMustNotBeEmpty)
+	}
+}
+
+func (v *validator) err() error {
+	if len(v.violations) == 0 {
+		return nil
+	}
+	return lib.NewValidationError(v.violations)
+}
+
+// ValidatePipeline checks the structure of a pipeline and returns an InputError
+// listing every violation with its JSON path, or nil if the pipeline is valid.
+func ValidatePipeline(pipeline lib.Pipeline) error {
+	v := &validator{}
+	validatePipeline(v, pipeline)
+	return v.err()
+}
+
+func validatePipeline(v *validator, pipeline lib.Pipeline) {
+	v.required("name", pipeline.Name)
+	if pipeline.WindowTime < 0 {
+		v.add("windowTime", MessageMustNotBeNegative)
+	}
+	if len(pipeline.Operators) == 0 {
+		v.add("operators", MessageNoOperators)
+	}
+	operatorIds := map[string]int{}
+	for i, operator := range pipeline.Operators {
+		path := fmt.Sprintf("operators[%d]", i)
+		v.required(path+".id", operator.Id)
+		if operator.Id != "" {
+			if j, ok := operatorIds[operator.Id]; ok {
+				v.add(path+".id", fmt.Sprintf(MessageDuplicateOperatorId, j))
+			} else {
+				operatorIds[operator.Id] = i
+			}
+		}
+		v.required(path+".operatorId", operator.OperatorId)
+		validateInputTopics(v, path, operator.InputTopics)
+		for j, selection := range operator.InputSelections {
+			v.required(fmt.Sprintf("%s.inputSelections[%d].inputName", path, j), selection.InputName)
+		}
+	}
+}
+
+func validateInputTopics(v *validator, operatorPath string, topics []lib.InputTopic) {
+	for i, topic := range topics {
+		path := fmt.Sprintf("%s.inputTopics[%d]", operatorPath, i)
+		v.required(path+".name", topic.Name)
+		if len(topic.Mappings) == 0 {
+			v.add(path+".mappings", MessageMustNotBeEmpty)
+		}
+		for j, mapping := range topic.Mappings {
+			mappingPath := fmt.Sprintf("%s.mappings[%d]", path, j)
+			v.required(mappingPath+".dest", mapping.Dest)
+			v.required(mappingPath+".source", mapping.Source)
+		}
+	}
+}
@@ -0,0 +1,68 @@
+/*
+ * Copyright 2025 InfAI (CC SES)
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *      http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package service
+
+import (
+	"errors"
+	"slices"
+	"testing"
+
+	"github.com/SENERGY-Platform/analytics-pipeline/lib"
+)
+
+func TestValidatePipeline_Valid(t *testing.T) {
+	if err := ValidatePipeline(testPipeline()); err != nil {
+		t.Errorf("unexpected error: %v", err)
+	}
+}
+
+func TestValidatePipeline_Violations(t *testing.T) {
+	pipeline := testPipeline()
+	pipeline.Name = ""
+	pipeline.Operators = append(pipeline.Operators, lib.Operator{
+		Id: "op1",
+		InputTopics: []lib.InputTopic{
+			{Name: "topic"},
+			{Mappings: []lib.Mapping{{Dest: "value"}}},
+		},
+	})
+
+	err := ValidatePipeline(pipeline)
+	var ie *lib.InputError
+	if !errors.As(err, &ie) {
+		t.Fatalf("expected input error, got %v", err)
+	}
+	var ve *lib.ValidationError
+	if !errors.As(err, &ve) {
+		t.Fatalf("expected validation error, got %v", err)
+	}
+	var paths []string
+	for _, v := range ve.Violations {
+		paths = append(paths, v.Path)
+	}
+	expected := []string{
+		"name",
+		"operators[1].id",
+		"operators[1].operatorId",
+		"operators[1].inputTopics[0].mappings",
+		"operators[1].inputTopics[1].name",
+		"operators[1].inputTopics[1].mappings[0].source",
+	}
+	if !slices.Equal(paths, expected) {
+		t.Errorf("unexpected violations: got %v want %v", paths, expected)
+	}
+}
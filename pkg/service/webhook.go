@@ -0,0 +1,436 @@
+/*
+ * Copyright 2025 InfAI (CC SES)
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *      http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package service
+
+import (
+	"bytes"
+	"context"
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net"
+	"net/http"
+	"net/netip"
+	"net/url"
+	"slices"
+	"strings"
+	"syscall"
+	"time"
+
+	"github.com/SENERGY-Platform/analytics-pipeline/lib"
+	"github.com/SENERGY-Platform/analytics-pipeline/pkg/util"
+	permV2Client "github.com/SENERGY-Platform/permissions-v2/pkg/client"
+	"github.com/SENERGY-Platform/service-commons/pkg/jwt"
+	"github.com/google/uuid"
+)
+
+const (
+	HeaderWebhookSignature = "X-Pipeline-Signature"
+	HeaderWebhookEvent     = "X-Pipeline-Event"
+	HeaderWebhookDelivery  = "X-Pipeline-Delivery"
+)
+
+// maxWebhookBackoff caps the exponential backoff between delivery attempts.
+const maxWebhookBackoff = 10 * time.Minute
+
+var pipelineEventTypes = []string{lib.PipelineEventCreated, lib.PipelineEventUpdated, lib.PipelineEventDeleted}
+
+// internalHosts are names resolved within the host or the cluster. Like names without a dot,
+// they are rejected unless private networks are allowed.
+var internalHosts = []string{"localhost", "local", "internal", "svc", "cluster.local"}
+
+// sharedAddressSpace (RFC 6598) is used for internal networks by some providers.
+var sharedAddressSpace = netip.MustParsePrefix("100.64.0.0/10")
+
+type WebhookConfig struct {
+	MaxAttempts    int
+	InitialBackoff time.Duration
+	Timeout        time.Duration
+	// AllowedHosts restricts webhook urls to these hosts and their subdomains, if not empty.
+	AllowedHosts []string
+	// DeniedHosts rejects these hosts and their subdomains.
+	DeniedHosts []string
+	// AllowPrivateNetworks permits internal names as well as loopback, private and link-local addresses.
+	AllowPrivateNetworks bool
+}
+
+// webhookDispatcher delivers events to matching subscriptions. Pending retries are kept in memory
+// and are lost on shutdown.
+type webhookDispatcher struct {
+	ctx    context.Context
+	client *http.Client
+	config WebhookConfig
+	queue  chan lib.PipelineEvent
+}
+
+// SignWebhookPayload returns the signature sent in the X-Pipeline-Signature header,
+// the hex encoded HMAC-SHA256 of the body prefixed with "sha256=".
+func SignWebhookPayload(secret string, body []byte) string {
+	mac := hmac.New(sha256.New, []byte(secret))
+	mac.Write(body)
+	return "sha256=" + hex.EncodeToString(mac.Sum(nil))
+}
+
+// CreateWebhook subscribes to events of pipelines readable by the user. Group and role permissions
+// are checked with the groups and roles in auth, if it contains a token.
+func (r *Registry) CreateWebhook(subscription lib.WebhookSubscription, userId string, auth string) (created lib.WebhookSubscription, err error) {
+	v := &validator{}
+	if u, err := url.Parse(subscription.Url); err != nil || (u.Scheme != "http" && u.Scheme != "https") || u.Host == "" {
+		v.add("url", MessageInvalidWebhookUrl)
+	} else if checkWebhookHost(u.Hostname(), r.webhookConfig()) != nil {
+		v.add("url", MessageWebhookHostNotAllowed)
+	}
+	v.required("secret", subscription.Secret)
+	for i, eventType := range subscription.EventTypes {
+		if !slices.Contains(pipelineEventTypes, eventType) {
+			v.add(fmt.Sprintf("eventTypes[%d]", i), fmt.Sprintf(MessageUnknownEventType, eventType))
+		}
+	}
+	err = v.err()
+	if err != nil {
+		return
+	}
+	subscription.Id = uuid.NewString()
+	subscription.UserId = userId
+	subscription.CreatedAt = time.Now()
+	subscription.Roles, subscription.Groups = nil, nil
+	if token, err := jwt.Parse(auth); err == nil {
+		subscription.Roles = token.GetRoles()
+		subscription.Groups = token.GetGroups()
+	}
+	err = r.repository.InsertWebhook(subscription)
+	if err != nil {
+		return
+	}
+	subscription.Secret = ""
+	return subscription, nil
+}
+
+func (r *Registry) GetWebhooks(userId string) (subscriptions []lib.WebhookSubscription, err error) {
+	subscriptions, err = r.repository.Webhooks(userId)
+	for i := range subscriptions {
+		subscriptions[i].Secret = ""
+	}
+	return
+}
+
+func (r *Registry) GetWebhook(id string, userId string) (subscription lib.WebhookSubscription, err error) {
+	subscription, err = r.repository.FindWebhook(id, userId)
+	subscription.Secret = ""
+	return
+}
+
+func (r *Registry) DeleteWebhook(id string, userId string) (err error) {
+	return r.repository.DeleteWebhook(id, userId)
+}
+
+func (r *Registry) GetWebhookDeliveries(id string, userId string) (deliveries []lib.WebhookDelivery, err error) {
+	_, err = r.repository.FindWebhook(id, userId)
+	if err != nil {
+		return
+	}
+	return r.repository.WebhookDeliveries(id)
+}
+
+func (r *Registry) GetWebhookDeadLetters(id string, userId string) (letters []lib.WebhookDeadLetter, err error) {
+	_, err = r.repository.FindWebhook(id, userId)
+	if err != nil {
+		return
+	}
+	return r.repository.WebhookDeadLetters(id)
+}
+
+// RedeliverWebhookDeadLetter schedules the event of a dead letter for delivery again. The letter is
+// removed once the event is delivered and kept with the new attempts otherwise.
+func (r *Registry) RedeliverWebhookDeadLetter(id string, letterId string, userId string) (err error) {
+	if r.webhooks == nil {
+		return lib.NewInputError(errors.New(MessageWebhooksDisabled))
+	}
+	subscription, err := r.repository.FindWebhook(id, userId)
+	if err != nil {
+		return
+	}
+	letter, err := r.repository.FindWebhookDeadLetter(letterId, id)
+	if err != nil {
+		return
+	}
+	go r.deliverWebhook(subscription, letter.Event, &letter)
+	return
+}
+
+// StartWebhookDispatcher enables webhook deliveries until ctx is done.
+// It has to be called before the registry is handed to the routes.
+func (r *Registry) StartWebhookDispatcher(ctx context.Context, config WebhookConfig) {
+	r.webhooks = &webhookDispatcher{
+		ctx:    ctx,
+		client: newWebhookClient(config),
+		config: config,
+		queue:  make(chan lib.PipelineEvent, subscriberBufferSize),
+	}
+	go func() {
+		for {
+			select {
+			case <-ctx.Done():
+				return
+			case event := <-r.webhooks.queue:
+				r.dispatchWebhooks(event)
+			}
+		}
+	}()
+}
+
+func (r *Registry) enqueueWebhooks(event lib.PipelineEvent) {
+	if r.webhooks == nil {
+		return
+	}
+	select {
+	case r.webhooks.queue <- event:
+	default:
+		// the event is not delivered, but can be redelivered from the dead letters
+		util.Logger.Error("webhook queue full, storing event as dead letters", "id", event.PipelineId, "type", event.Type)
+		go func() {
+			for _, subscription := range r.webhookSubscribers(event) {
+				r.saveDeadLetter(subscription, event, nil, 0, MessageWebhookQueueFull)
+			}
+		}()
+	}
+}
+
+func (r *Registry) dispatchWebhooks(event lib.PipelineEvent) {
+	for _, subscription := range r.webhookSubscribers(event) {
+		go r.deliverWebhook(subscription, event, nil)
+	}
+}
+
+// webhookSubscribers returns the subscriptions matching event whose users may read the pipeline.
+func (r *Registry) webhookSubscribers(event lib.PipelineEvent) (subscribers []lib.WebhookSubscription) {
+	subscriptions, err := r.repository.MatchingWebhooks(event.Type, event.PipelineId)
+	if err != nil {
+		util.Logger.Error("could not get webhook subscriptions", "error", err, "id", event.PipelineId)
+		return
+	}
+	var permissions *permV2Client.ResourcePermissions
+	for _, subscription := range subscriptions {
+		readable := event.Pipeline != nil && event.Pipeline.UserId == subscription.UserId
+		if !readable {
+			if permissions == nil {
+				resource, err, _ := r.perm.GetResource(permV2Client.InternalAdminToken, PermV2InstanceTopic, event.PipelineId)
+				if err != nil {
+					// the lookup is repeated for the next subscription
+					util.Logger.Error("could not get pipeline permissions", "error", err, "id", event.PipelineId, "subscription", subscription.Id)
+					continue
+				}
+				permissions = &resource.ResourcePermissions
+			}
+			readable = subscriberMayRead(subscription, *permissions)
+		}
+		if readable {
+			subscribers = append(subscribers, subscription)
+		}
+	}
+	return
+}
+
+// subscriberMayRead reports whether the subscriber may read a pipeline with permissions, granted
+// to the user or one of the groups or roles of the subscription.
+func subscriberMayRead(subscription lib.WebhookSubscription, permissions permV2Client.ResourcePermissions) bool {
+	if permissions.UserPermissions[subscription.UserId].Read {
+		return true
+	}
+	for _, group := range subscription.Groups {
+		if permissions.GroupPermissions[group].Read {
+			return true
+		}
+	}
+	for _, role := range subscription.Roles {
+		if permissions.RolePermissions[role].Read {
+			return true
+		}
+	}
+	return false
+}
+
+// deliverWebhook posts the event to the subscription, retrying with exponential backoff. Every
+// attempt is logged, events that could not be delivered after all attempts become dead letters.
+// A redelivered letter is removed on success and updated otherwise.
+func (r *Registry) deliverWebhook(subscription lib.WebhookSubscription, event lib.PipelineEvent, letter *lib.WebhookDeadLetter) {
+	body, err := json.Marshal(event)
+	if err != nil {
+		util.Logger.Error("could not marshal webhook payload", "error", err)
+		return
+	}
+	backoff := r.webhooks.config.InitialBackoff
+	var lastError string
+	for attempt := 1; attempt <= r.webhooks.config.MaxAttempts; attempt++ {
+		delivery := lib.WebhookDelivery{
+			Id:             uuid.NewString(),
+			SubscriptionId: subscription.Id,
+			EventId:        event.Id,
+			EventType:      event.Type,
+			PipelineId:     event.PipelineId,
+			Attempt:        attempt,
+		}
+		delivery.StatusCode, err = r.postWebhook(subscription, delivery.Id, event.Type, body)
+		delivery.Timestamp = time.Now()
+		delivery.Success = err == nil
+		if err != nil {
+			delivery.Error = err.Error()
+			lastError = delivery.Error
+		}
+		if logErr := r.repository.InsertWebhookDelivery(delivery); logErr != nil {
+			util.Logger.Error("could not log webhook delivery", "error", logErr, "subscription", subscription.Id)
+		}
+		if delivery.Success {
+			if letter != nil {
+				if err = r.repository.DeleteWebhookDeadLetter(letter.Id); err != nil {
+					util.Logger.Error("could not delete redelivered webhook dead letter", "error", err, "subscription", subscription.Id)
+				}
+			}
+			return
+		}
+		if attempt == r.webhooks.config.MaxAttempts {
+			break
+		}
+		select {
+		case <-r.webhooks.ctx.Done():
+			return
+		case <-time.After(backoff):
+		}
+		backoff = min(2*backoff, maxWebhookBackoff)
+	}
+	r.saveDeadLetter(subscription, event, letter, r.webhooks.config.MaxAttempts, lastError)
+}
+
+// saveDeadLetter stores an event that could not be delivered, a redelivered letter is updated.
+func (r *Registry) saveDeadLetter(subscription lib.WebhookSubscription, event lib.PipelineEvent, letter *lib.WebhookDeadLetter, attempts int, lastError string) {
+	if letter == nil {
+		letter = &lib.WebhookDeadLetter{
+			Id:             uuid.NewString(),
+			SubscriptionId: subscription.Id,
+			Event:          event,
+			CreatedAt:      time.Now(),
+		}
+	}
+	letter.Attempts += attempts
+	letter.LastError = lastError
+	err := r.repository.SaveWebhookDeadLetter(*letter)
+	if err != nil {
+		util.Logger.Error("could not store webhook dead letter", "error", err, "subscription", subscription.Id)
+	}
+}
+
+func (r *Registry) postWebhook(subscription lib.WebhookSubscription, deliveryId string, eventType string, body []byte) (statusCode int, err error) {
+	req, err := http.NewRequestWithContext(r.webhooks.ctx, http.MethodPost, subscription.Url, bytes.NewReader(body))
+	if err != nil {
+		return
+	}
+	req.Header.Set("Content-Type", "application/json")
+	req.Header.Set(HeaderWebhookSignature, SignWebhookPayload(subscription.Secret, body))
+	req.Header.Set(HeaderWebhookEvent, eventType)
+	req.Header.Set(HeaderWebhookDelivery, deliveryId)
+	resp, err := r.webhooks.client.Do(req)
+	if err != nil {
+		return
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode < 200 || resp.StatusCode > 299 {
+		return resp.StatusCode, fmt.Errorf("unexpected status code %d", resp.StatusCode)
+	}
+	return resp.StatusCode, nil
+}
+
+func (r *Registry) webhookConfig() WebhookConfig {
+	if r.webhooks == nil {
+		return WebhookConfig{}
+	}
+	return r.webhooks.config
+}
+
+// newWebhookClient returns a client which only connects to addresses permitted by config. Addresses are
+// checked after name resolution, so that names resolving to internal addresses are rejected as well.
+// Proxies are not used, they would hide the address of the receiver.
+func newWebhookClient(config WebhookConfig) *http.Client {
+	dialer := &net.Dialer{
+		Timeout: config.Timeout,
+		Control: func(_ string, address string, _ syscall.RawConn) error {
+			addrPort, err := netip.ParseAddrPort(address)
+			if err != nil {
+				return err
+			}
+			return checkWebhookAddr(addrPort.Addr(), config)
+		},
+	}
+	return &http.Client{
+		Timeout: config.Timeout,
+		Transport: &http.Transport{
+			DialContext:         dialer.DialContext,
+			ForceAttemptHTTP2:   true,
+			TLSHandshakeTimeout: config.Timeout,
+		},
+		CheckRedirect: func(req *http.Request, via []*http.Request) error {
+			if len(via) >= 10 {
+				return errors.New("stopped after 10 redirects")
+			}
+			return checkWebhookHost(req.URL.Hostname(), config)
+		},
+	}
+}
+
+// checkWebhookHost applies the host policy of config to the host of a webhook url.
+func checkWebhookHost(host string, config WebhookConfig) error {
+	host = strings.ToLower(strings.TrimSuffix(host, "."))
+	if len(config.AllowedHosts) > 0 && !matchesHost(host, config.AllowedHosts) {
+		return fmt.Errorf("host %s is not allowed", host)
+	}
+	if matchesHost(host, config.DeniedHosts) {
+		return fmt.Errorf("host %s is denied", host)
+	}
+	if addr, err := netip.ParseAddr(host); err == nil {
+		return checkWebhookAddr(addr, config)
+	}
+	if !config.AllowPrivateNetworks && (!strings.Contains(host, ".") || matchesHost(host, internalHosts)) {
+		return fmt.Errorf("host %s is internal", host)
+	}
+	return nil
+}
+
+// checkWebhookAddr rejects loopback, private, link-local and other non-public addresses unless
+// private networks are allowed.
+func checkWebhookAddr(addr netip.Addr, config WebhookConfig) error {
+	if config.AllowPrivateNetworks {
+		return nil
+	}
+	addr = addr.Unmap()
+	if !addr.IsGlobalUnicast() || addr.IsPrivate() || sharedAddressSpace.Contains(addr) {
+		return fmt.Errorf("address %s is not public", addr)
+	}
+	return nil
+}
+
+// matchesHost reports whether host equals one of patterns or is a subdomain of it.
+func matchesHost(host string, patterns []string) bool {
+	for _, pattern := range patterns {
+		pattern = strings.ToLower(strings.Trim(pattern, "."))
+		if pattern != "" && (host == pattern || strings.HasSuffix(host, "."+pattern)) {
+			return true
+		}
+	}
+	return false
+}
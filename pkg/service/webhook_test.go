@@ -0,0 +1,286 @@
+/*
+ * Copyright 2025 InfAI (CC SES)
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *      http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package service
+
+import (
+	"context"
+	"encoding/json"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"slices"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/SENERGY-Platform/analytics-pipeline/lib"
+	"github.com/SENERGY-Platform/analytics-pipeline/pkg/db"
+	"github.com/SENERGY-Platform/analytics-pipeline/pkg/util"
+	permV2Client "github.com/SENERGY-Platform/permissions-v2/pkg/client"
+	"go.mongodb.org/mongo-driver/mongo"
+)
+
+type webhookRepo struct {
+	*db.MockRepo
+	mu            sync.Mutex
+	subscriptions []lib.WebhookSubscription
+	deliveries    []lib.WebhookDelivery
+	deadLetters   []lib.WebhookDeadLetter
+}
+
+func (r *webhookRepo) MatchingWebhooks(_ string, _ string) ([]lib.WebhookSubscription, error) {
+	return r.subscriptions, nil
+}
+
+func (r *webhookRepo) InsertWebhookDelivery(delivery lib.WebhookDelivery) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.deliveries = append(r.deliveries, delivery)
+	return nil
+}
+
+func (r *webhookRepo) SaveWebhookDeadLetter(letter lib.WebhookDeadLetter) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.deadLetters = slices.DeleteFunc(r.deadLetters, func(l lib.WebhookDeadLetter) bool { return l.Id == letter.Id })
+	r.deadLetters = append(r.deadLetters, letter)
+	return nil
+}
+
+func (r *webhookRepo) FindWebhook(_ string, _ string) (lib.WebhookSubscription, error) {
+	return r.subscriptions[0], nil
+}
+
+func (r *webhookRepo) FindWebhookDeadLetter(id string, _ string) (lib.WebhookDeadLetter, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	for _, letter := range r.deadLetters {
+		if letter.Id == id {
+			return letter, nil
+		}
+	}
+	return lib.WebhookDeadLetter{}, mongo.ErrNoDocuments
+}
+
+func (r *webhookRepo) DeleteWebhookDeadLetter(id string) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.deadLetters = slices.DeleteFunc(r.deadLetters, func(l lib.WebhookDeadLetter) bool { return l.Id == id })
+	return nil
+}
+
+func (r *webhookRepo) state() (deliveries []lib.WebhookDelivery, deadLetters []lib.WebhookDeadLetter) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	return append(deliveries, r.deliveries...), append(deadLetters, r.deadLetters...)
+}
+
+func startWebhookTest(t *testing.T, handler http.HandlerFunc, maxAttempts int) (*Registry, *webhookRepo) {
+	server := httptest.NewServer(handler)
+	t.Cleanup(server.Close)
+	ctx, cancel := context.WithCancel(context.Background())
+	t.Cleanup(cancel)
+	repo := &webhookRepo{MockRepo: db.NewMockRepo(), subscriptions: []lib.WebhookSubscription{{
+		Id:     "hook",
+		UserId: "owner",
+		Url:    server.URL,
+		Secret: "secret",
+	}}}
+	registry := &Registry{repository: repo}
+	registry.StartWebhookDispatcher(ctx, WebhookConfig{MaxAttempts: maxAttempts, InitialBackoff: time.Millisecond, Timeout: time.Second, AllowPrivateNetworks: true})
+	pipeline := testPipeline()
+	pipeline.Id = "p1"
+	pipeline.UserId = "owner"
+	registry.emitEvent(lib.PipelineEventUpdated, pipeline, "owner")
+	return registry, repo
+}
+
+func waitFor(t *testing.T, condition func() bool) {
+	deadline := time.Now().Add(5 * time.Second)
+	for !condition() {
+		if time.Now().After(deadline) {
+			t.Fatal("timeout")
+		}
+		time.Sleep(5 * time.Millisecond)
+	}
+}
+
+func TestRegistry_WebhookSignedDeliveryWithRetry(t *testing.T) {
+	var calls atomic.Int32
+	received := make(chan lib.PipelineEvent, 1)
+	_, repo := startWebhookTest(t, func(w http.ResponseWriter, r *http.Request) {
+		body, _ := io.ReadAll(r.Body)
+		if r.Header.Get(HeaderWebhookSignature) != SignWebhookPayload("secret", body) {
+			t.Error("invalid signature")
+		}
+		if calls.Add(1) == 1 {
+			w.WriteHeader(http.StatusServiceUnavailable)
+			return
+		}
+		var event lib.PipelineEvent
+		if err := json.Unmarshal(body, &event); err != nil {
+			t.Error(err)
+		}
+		received <- event
+	}, 3)
+
+	select {
+	case event := <-received:
+		if event.Type != lib.PipelineEventUpdated || event.PipelineId != "p1" || event.Pipeline == nil {
+			t.Errorf("unexpected event: %+v", event)
+		}
+	case <-time.After(5 * time.Second):
+		t.Fatal("webhook not delivered")
+	}
+	waitFor(t, func() bool {
+		deliveries, _ := repo.state()
+		return len(deliveries) == 2
+	})
+	deliveries, deadLetters := repo.state()
+	if deliveries[0].Success || deliveries[0].StatusCode != http.StatusServiceUnavailable || !deliveries[1].Success || deliveries[1].Attempt != 2 {
+		t.Errorf("unexpected delivery log: %+v", deliveries)
+	}
+	if len(deadLetters) != 0 {
+		t.Errorf("unexpected dead letters: %+v", deadLetters)
+	}
+}
+
+func TestRegistry_WebhookDeadLetter(t *testing.T) {
+	var healthy atomic.Bool
+	registry, repo := startWebhookTest(t, func(w http.ResponseWriter, r *http.Request) {
+		if !healthy.Load() {
+			w.WriteHeader(http.StatusInternalServerError)
+		}
+	}, 2)
+
+	waitFor(t, func() bool {
+		_, deadLetters := repo.state()
+		return len(deadLetters) == 1
+	})
+	deliveries, deadLetters := repo.state()
+	if len(deliveries) != 2 {
+		t.Errorf("expected 2 attempts, got %d", len(deliveries))
+	}
+	if deadLetters[0].SubscriptionId != "hook" || deadLetters[0].Event.PipelineId != "p1" || deadLetters[0].Attempts != 2 {
+		t.Errorf("unexpected dead letter: %+v", deadLetters[0])
+	}
+
+	// a failed redelivery keeps the letter
+	letterId := deadLetters[0].Id
+	if err := registry.RedeliverWebhookDeadLetter("hook", letterId, "owner"); err != nil {
+		t.Fatal(err)
+	}
+	waitFor(t, func() bool {
+		_, deadLetters := repo.state()
+		return len(deadLetters) == 1 && deadLetters[0].Attempts == 4
+	})
+
+	healthy.Store(true)
+	if err := registry.RedeliverWebhookDeadLetter("hook", letterId, "owner"); err != nil {
+		t.Fatal(err)
+	}
+	waitFor(t, func() bool {
+		_, deadLetters := repo.state()
+		return len(deadLetters) == 0
+	})
+}
+
+func TestRegistry_WebhookQueueFull(t *testing.T) {
+	util.InitStructLogger("error")
+	repo := &webhookRepo{MockRepo: db.NewMockRepo(), subscriptions: []lib.WebhookSubscription{{Id: "hook", UserId: "owner"}}}
+	// nothing reads from the unbuffered queue
+	registry := &Registry{repository: repo, webhooks: &webhookDispatcher{queue: make(chan lib.PipelineEvent)}}
+	pipeline := testPipeline()
+	pipeline.Id = "p1"
+	pipeline.UserId = "owner"
+	registry.emitEvent(lib.PipelineEventUpdated, pipeline, "owner")
+	waitFor(t, func() bool {
+		_, deadLetters := repo.state()
+		return len(deadLetters) == 1
+	})
+	_, deadLetters := repo.state()
+	if deadLetters[0].SubscriptionId != "hook" || deadLetters[0].LastError != MessageWebhookQueueFull || deadLetters[0].Attempts != 0 {
+		t.Errorf("unexpected dead letter: %+v", deadLetters[0])
+	}
+}
+
+func TestCheckWebhookHost(t *testing.T) {
+	config := WebhookConfig{DeniedHosts: []string{"blocked.example.com"}}
+	for host, allowed := range map[string]bool{
+		"hooks.example.com":      true,
+		"93.184.216.34":          true,
+		"localhost":              false,
+		"127.0.0.1":              false,
+		"10.1.2.3":               false,
+		"169.254.169.254":        false,
+		"::1":                    false,
+		"fd00::1":                false,
+		"::ffff:10.0.0.1":        false,
+		"kafka":                  false,
+		"permv2.permissions.svc": false,
+		"api.svc.cluster.local":  false,
+		"a.blocked.example.com":  false,
+	} {
+		if err := checkWebhookHost(host, config); (err == nil) != allowed {
+			t.Errorf("%s: expected allowed=%v, got %v", host, allowed, err)
+		}
+	}
+	config = WebhookConfig{AllowedHosts: []string{"example.com"}, AllowPrivateNetworks: true}
+	if checkWebhookHost("hooks.example.com", config) != nil || checkWebhookHost("example.org", config) == nil {
+		t.Error("allowed hosts not applied")
+	}
+	if checkWebhookHost("10.1.2.3", WebhookConfig{AllowPrivateNetworks: true}) != nil {
+		t.Error("private address rejected although allowed")
+	}
+}
+
+func TestNewWebhookClient_RejectsPrivateAddresses(t *testing.T) {
+	var calls atomic.Int32
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		calls.Add(1)
+	}))
+	defer server.Close()
+	client := newWebhookClient(WebhookConfig{Timeout: time.Second})
+	resp, err := client.Get(server.URL)
+	if err == nil {
+		resp.Body.Close()
+		t.Fatal("expected error for loopback address")
+	}
+	if calls.Load() != 0 {
+		t.Error("request reached loopback server")
+	}
+}
+
+func TestSubscriberMayRead(t *testing.T) {
+	permissions := permV2Client.ResourcePermissions{
+		UserPermissions:  map[string]permV2Client.PermissionsMap{"owner": {Read: true}},
+		GroupPermissions: map[string]permV2Client.PermissionsMap{"team": {Read: true}},
+		RolePermissions:  map[string]permV2Client.PermissionsMap{"operator": {Read: true}, "guest": {}},
+	}
+	for subscription, readable := range map[*lib.WebhookSubscription]bool{
+		{UserId: "owner"}: true,
+		{UserId: "member", Groups: []string{"team"}}:     true,
+		{UserId: "staff", Roles: []string{"operator"}}:   true,
+		{UserId: "visitor", Roles: []string{"guest"}}:    false,
+		{UserId: "other", Groups: []string{"elsewhere"}}: false,
+	} {
+		if subscriberMayRead(*subscription, permissions) != readable {
+			t.Errorf("%+v: expected readable=%v", *subscription, readable)
+		}
+	}
+}
@@ -40,5 +40,13 @@ func GetStatusCode(err error) int {
 	if errors.As(err, &fe) {
 		return http.StatusForbidden
 	}
+	var ce *lib.ConflictError
+	if errors.As(err, &ce) {
+		return http.StatusConflict
+	}
+	var pfe *lib.PreconditionFailedError
+	if errors.As(err, &pfe) {
+		return http.StatusPreconditionFailed
+	}
 	return 0
 }